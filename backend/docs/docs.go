@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/characters": {
+        "/alliances/{id}": {
             "get": {
-                "description": "Fetch all characters from the database",
+                "description": "Fetch an alliance (from ESI if it is not stored yet) together with its kills, losses and most killed victim corporations",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,17 +25,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "characters"
+                    "alliances"
+                ],
+                "summary": "Get an alliance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Alliance ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISK values from zkill (default) or own",
+                        "name": "valueSource",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all characters",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Character"
-                            }
+                            "$ref": "#/definitions/models.AllianceDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -45,47 +69,106 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Add a new character ID to the database and fetch all kills\nAdd a new character ID to the database and fetch all kills",
-                "consumes": [
-                    "application/json",
-                    "application/json"
-                ],
+            }
+        },
+        "/attackers/ships": {
+            "get": {
+                "description": "Count how often each ship type was flown by the attackers of the matching kills, with distinct pilots, final blows and damage",
                 "produces": [
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "characters",
-                    "characters"
+                    "kills"
                 ],
-                "summary": "Add a new character ID",
+                "summary": "Get attacker ship usage",
                 "parameters": [
                     {
-                        "description": "Character ID",
-                        "name": "character",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Character"
-                        }
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Region IDs",
+                        "name": "regionID",
+                        "in": "query"
                     },
                     {
-                        "description": "Character ID",
-                        "name": "character",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Character"
-                        }
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship group IDs or names",
+                        "name": "shipGroup",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship category IDs or names",
+                        "name": "shipCategory",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills within jumps of this system",
+                        "name": "nearSystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jump radius around nearSystem (default 0)",
+                        "name": "jumps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in space held by this alliance",
+                        "name": "sovAlliance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in faction warfare space occupied by this faction",
+                        "name": "fwOccupier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of ship types (default 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Character"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ShipUsage"
+                            }
                         }
                     },
                     "400": {
@@ -103,19 +186,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/characters/stats": {
+        "/attackers/stats": {
             "get": {
-                "description": "Fetch stats for all characters from the database with optional filters",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Rank characters on the matching kills by final blows, kills on which they dealt the most damage, total damage or kills",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "characters"
+                    "kills"
                 ],
-                "summary": "Get all character stats",
+                "summary": "Get attacker stats",
                 "parameters": [
                     {
                         "type": "array",
@@ -138,6 +218,68 @@ const docTemplate = `{
                         "description": "End date (YYYY-MM-DD)",
                         "name": "endDate",
                         "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship group IDs or names",
+                        "name": "shipGroup",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship category IDs or names",
+                        "name": "shipCategory",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills within jumps of this system",
+                        "name": "nearSystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jump radius around nearSystem (default 0)",
+                        "name": "jumps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in space held by this alliance",
+                        "name": "sovAlliance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in faction warfare space occupied by this faction",
+                        "name": "fwOccupier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "finalBlows (default), topDamage, damage or kills",
+                        "name": "orderBy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of characters (default 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -146,7 +288,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/db.CharacterStats"
+                                "$ref": "#/definitions/models.AttackerStats"
                             }
                         }
                     },
@@ -165,36 +307,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/characters/{id}": {
-            "delete": {
-                "description": "Remove a character from the database",
-                "consumes": [
-                    "application/json"
-                ],
+        "/categories": {
+            "get": {
+                "description": "Fetch all type categories from the database",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "characters"
-                ],
-                "summary": "Remove a character",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Character ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "taxonomy"
                 ],
+                "summary": "Get all categories",
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ItemCategory"
+                            }
                         }
                     },
                     "500": {
@@ -206,48 +336,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/characters/{id}/kills": {
-            "get": {
-                "description": "Fetch and store kills for a character from zKillboard",
-                "consumes": [
-                    "application/json"
-                ],
+        "/categories/fetch": {
+            "post": {
+                "description": "Fetch type categories from ESI and store the ones that changed",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "characters"
-                ],
-                "summary": "Get character kills",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Character ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    }
+                    "taxonomy"
                 ],
+                "summary": "Fetch and store categories",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Kill"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "500": {
@@ -259,45 +363,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/characters/{id}/kills/db": {
+        "/categories/{id}": {
             "get": {
-                "description": "Fetch kills for a character from the database",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Fetch a type category and its groups from the database",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "characters"
+                    "taxonomy"
                 ],
-                "summary": "Get character kills from database",
+                "summary": "Get category by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Character ID",
+                        "description": "Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page size",
-                        "name": "pageSize",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PaginatedResponse"
+                            "$ref": "#/definitions/models.CategoryDetails"
                         }
                     },
                     "400": {
@@ -306,6 +395,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -315,9 +410,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/kills": {
+        "/characters": {
             "get": {
-                "description": "Fetch all kills from the database",
+                "description": "Fetch all characters from the database",
                 "consumes": [
                     "application/json"
                 ],
@@ -325,16 +420,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "kills"
+                    "characters"
                 ],
-                "summary": "Get all kills",
+                "summary": "Get all characters",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Kill"
+                                "$ref": "#/definitions/models.Character"
                             }
                         }
                     },
@@ -345,11 +440,55 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "post": {
+                "description": "Add a new character ID to the database and fetch all kills",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "characters"
+                ],
+                "summary": "Add a new character ID",
+                "parameters": [
+                    {
+                        "description": "Character ID",
+                        "name": "character",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Character"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Character"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
             }
         },
-        "/kills/region/{regionID}": {
+        "/characters/stats": {
             "get": {
-                "description": "Fetch kills for a region from the database",
+                "description": "Fetch kill and loss stats with ISK efficiency for all characters from the database with optional filters",
                 "consumes": [
                     "application/json"
                 ],
@@ -357,39 +496,98 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "kills"
+                    "characters"
                 ],
-                "summary": "Get kills by region",
+                "summary": "Get all character stats",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Region ID",
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Region IDs",
                         "name": "regionID",
-                        "in": "path",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
+                        "description": "Only count kills and losses while in this corporation",
+                        "name": "corporationID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship group IDs or names",
+                        "name": "shipGroup",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship category IDs or names",
+                        "name": "shipCategory",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Page size",
-                        "name": "pageSize",
+                        "description": "Only kills within jumps of this system",
+                        "name": "nearSystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jump radius around nearSystem (default 0)",
+                        "name": "jumps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in space held by this alliance",
+                        "name": "sovAlliance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in faction warfare space occupied by this faction",
+                        "name": "fwOccupier",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "startDate",
+                        "description": "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)",
+                        "name": "labels",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "endDate",
+                        "description": "ISK values from zkill (default) or own",
+                        "name": "valueSource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credit shared kills to every participant (default), the finalBlow only, or by damageShare of the ISK",
+                        "name": "countBy",
                         "in": "query"
                     }
                 ],
@@ -397,7 +595,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PaginatedResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CharacterStats"
+                            }
                         }
                     },
                     "400": {
@@ -415,9 +616,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/regions": {
+        "/characters/{id}": {
+            "delete": {
+                "description": "Remove a character from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "characters"
+                ],
+                "summary": "Remove a character",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Character ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/characters/{id}/affiliations": {
             "get": {
-                "description": "Fetch all regions from the database",
+                "description": "Fetch the corporations and alliances a character belonged to, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -425,19 +667,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "regions"
+                    "characters"
+                ],
+                "summary": "Get character affiliation history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Character ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all regions",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Region"
+                                "$ref": "#/definitions/models.CharacterAffiliation"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -446,240 +703,2832 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "db.CharacterStats": {
+        },
+        "/characters/{id}/killmails": {
+            "get": {
+                "description": "Fetch the kills or losses of a character from the database with optional filters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "characters"
+                ],
+                "summary": "Get character killmails",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Character ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Kills as attacker (default) or losses as victim",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC 3339)",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC 3339)",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Solar system ID",
+                        "name": "system_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Region ID",
+                        "name": "region_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Embed resolved names",
+                        "name": "resolveNames",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Kill"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/characters/{id}/kills/db": {
+            "get": {
+                "description": "Fetch kills for a character from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "characters"
+                ],
+                "summary": "Get character kills from database",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Character ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Embed resolved names",
+                        "name": "resolveNames",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PaginatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/characters/{id}/losses/db": {
+            "get": {
+                "description": "Fetch losses of a character from the database, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "characters"
+                ],
+                "summary": "Get character losses from database",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Character ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Embed resolved names",
+                        "name": "resolveNames",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PaginatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/corporations/{id}": {
+            "get": {
+                "description": "Fetch a corporation (from ESI if it is not stored yet) together with its kills, losses and most killed victim corporations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "corporations"
+                ],
+                "summary": "Get a corporation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Corporation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISK values from zkill (default) or own",
+                        "name": "valueSource",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CorporationDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/enrichment/jobs": {
+            "get": {
+                "description": "Fetch the jobs fetching full killmails from ESI, most recently updated first, optionally only those in one state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Get enrichment jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, in_flight, done, failed or permanent",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PaginatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/enrichment/requeue": {
+            "post": {
+                "description": "Reset failed or permanent enrichment jobs to pending with no attempts. Without killmail IDs every job in the given state, or every failed and permanent job, is requeued.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Requeue failed enrichment jobs",
+                "parameters": [
+                    {
+                        "description": "Jobs to requeue",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/routes.RequeueRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/enrichment/summary": {
+            "get": {
+                "description": "Count the enrichment jobs in every state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Get enrichment queue summary",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EnrichmentStateCount"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "get": {
+                "description": "Fetch type groups from the database, optionally of a single category",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxonomy"
+                ],
+                "summary": "Get groups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Category ID",
+                        "name": "categoryID",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ItemGroup"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/fetch": {
+            "post": {
+                "description": "Fetch type groups from ESI and store the ones that changed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxonomy"
+                ],
+                "summary": "Fetch and store groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}": {
+            "get": {
+                "description": "Fetch a type group and its types from the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxonomy"
+                ],
+                "summary": "Get group by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/items/{typeID}/history": {
+            "get": {
+                "description": "Fetch the stored daily market history of a type in The Forge",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "items"
+                ],
+                "summary": "Get item price history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Type ID",
+                        "name": "typeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PriceHistory"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/kills": {
+            "get": {
+                "description": "Fetch all kills made by tracked characters from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kills"
+                ],
+                "summary": "Get all kills",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Region IDs",
+                        "name": "regionID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills made while in this corporation",
+                        "name": "corporationID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship group IDs or names",
+                        "name": "shipGroup",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship category IDs or names",
+                        "name": "shipCategory",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills within jumps of this system",
+                        "name": "nearSystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jump radius around nearSystem (default 0)",
+                        "name": "jumps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in space held by this alliance",
+                        "name": "sovAlliance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in faction warfare space occupied by this faction",
+                        "name": "fwOccupier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Embed resolved names",
+                        "name": "resolveNames",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Kill"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/kills/region/{regionID}": {
+            "get": {
+                "description": "Fetch all kills for a region from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kills"
+                ],
+                "summary": "Get kills by region",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Region ID",
+                        "name": "regionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship group IDs or names",
+                        "name": "shipGroup",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship category IDs or names",
+                        "name": "shipCategory",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills within jumps of this system",
+                        "name": "nearSystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jump radius around nearSystem (default 0)",
+                        "name": "jumps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in space held by this alliance",
+                        "name": "sovAlliance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in faction warfare space occupied by this faction",
+                        "name": "fwOccupier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Embed resolved names",
+                        "name": "resolveNames",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Kill"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/kills/space": {
+            "get": {
+                "description": "Aggregate kills by the sovereignty alliance and faction warfare occupier of their system at the time of the kill",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kills"
+                ],
+                "summary": "Get kills by space holder",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Region IDs",
+                        "name": "regionID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills made while in this corporation",
+                        "name": "corporationID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in space held by this alliance",
+                        "name": "sovAlliance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only kills in faction warfare space occupied by this faction",
+                        "name": "fwOccupier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISK values from zkill (default) or own",
+                        "name": "valueSource",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SpaceBreakdown"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/kills/{killmailID}": {
+            "get": {
+                "description": "Fetch a stored kill with its zKillboard data and the full victim item tree, items inside containers nested under their container",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kills"
+                ],
+                "summary": "Get a killmail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Killmail ID",
+                        "name": "killmailID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Embed resolved names",
+                        "name": "resolveNames",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Kill"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/kills/{killmailID}/attackers": {
+            "get": {
+                "description": "Fetch the attackers of a stored kill in killmail order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kills"
+                ],
+                "summary": "Get the attackers of a killmail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Killmail ID",
+                        "name": "killmailID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.KillAttacker"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/kills/{killmailID}/items": {
+            "get": {
+                "description": "Fetch the victim's items of a stored kill as flat rows in walk order, with the index of the containing item, flag and the quantities dropped and destroyed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kills"
+                ],
+                "summary": "Get the items of a killmail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Killmail ID",
+                        "name": "killmailID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.KillItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/kills/{killmailID}/participants": {
+            "get": {
+                "description": "Fetch the tracked characters on a stored kill, as victim or attackers, with their final blow and damage",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kills"
+                ],
+                "summary": "Get the participants of a killmail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Killmail ID",
+                        "name": "killmailID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.KillParticipant"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/losses": {
+            "get": {
+                "description": "Fetch all losses of tracked characters from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kills"
+                ],
+                "summary": "Get all losses",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Region IDs",
+                        "name": "regionID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "startDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "endDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only losses suffered while in this corporation",
+                        "name": "corporationID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship group IDs or names",
+                        "name": "shipGroup",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Victim ship category IDs or names",
+                        "name": "shipCategory",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only losses within jumps of this system",
+                        "name": "nearSystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jump radius around nearSystem (default 0)",
+                        "name": "jumps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only losses in space held by this alliance",
+                        "name": "sovAlliance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only losses in faction warfare space occupied by this faction",
+                        "name": "fwOccupier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only losses with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Embed resolved names",
+                        "name": "resolveNames",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Kill"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/market-groups": {
+            "get": {
+                "description": "Fetch all market groups from the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxonomy"
+                ],
+                "summary": "Get all market groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MarketGroup"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/market-groups/fetch": {
+            "post": {
+                "description": "Fetch market groups from ESI and store the ones that changed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxonomy"
+                ],
+                "summary": "Fetch and store market groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/names": {
+            "post": {
+                "description": "Resolve character, corporation, alliance, type and location IDs to names, using the names cache and ESI for unknown IDs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "names"
+                ],
+                "summary": "Resolve IDs to names",
+                "parameters": [
+                    {
+                        "description": "IDs to resolve",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/routes.NamesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Name"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/regions": {
+            "get": {
+                "description": "Fetch all regions from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "Get all regions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Region"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/route/{from}/{to}": {
+            "get": {
+                "description": "Find the shortest stargate route between two systems, optionally avoiding systems",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "universe"
+                ],
+                "summary": "Get route between systems",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Origin system ID",
+                        "name": "from",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Destination system ID",
+                        "name": "to",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Security bands to avoid (high, low, null)",
+                        "name": "avoidSecurity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "System IDs to avoid",
+                        "name": "avoidSystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Avoid systems with at least this many recent kills",
+                        "name": "avoidKills",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How far back avoidKills looks, e.g. 1h (default 24h)",
+                        "name": "killWindow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Route"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stargates/fetch": {
+            "post": {
+                "description": "Fetch the stargates of all stored systems from ESI and store the ones that changed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "universe"
+                ],
+                "summary": "Fetch and store stargates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/systems/{id}/control": {
+            "get": {
+                "description": "Fetch the recorded sovereignty and faction warfare holders of a system, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "systems"
+                ],
+                "summary": "Get system control history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "System ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SystemControl"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/systems/{id}/within/{jumps}": {
+            "get": {
+                "description": "List the systems reachable from a system within a number of stargate jumps, nearest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "universe"
+                ],
+                "summary": "Get systems within jumps",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "System ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of jumps",
+                        "name": "jumps",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Security bands to avoid (high, low, null)",
+                        "name": "avoidSecurity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "System IDs to avoid",
+                        "name": "avoidSystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Avoid systems with at least this many recent kills",
+                        "name": "avoidKills",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How far back avoidKills looks, e.g. 1h (default 24h)",
+                        "name": "killWindow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RouteSystem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tracked-entities": {
+            "get": {
+                "description": "Fetch the corporations and alliances whose kills and losses are ingested",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracked entities"
+                ],
+                "summary": "Get tracked entities",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TrackedEntity"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a corporation or alliance and fetch all its kills and losses from zKillboard. Every member appearing on them is added to the tracked characters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracked entities"
+                ],
+                "summary": "Track a corporation or alliance",
+                "parameters": [
+                    {
+                        "description": "Entity type (corporation or alliance) and ID",
+                        "name": "entity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TrackedEntity"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrackedEntity"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrackedEntity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tracked-entities/{type}/{id}": {
+            "delete": {
+                "description": "Remove a tracked corporation or alliance. Characters added through it stay tracked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracked entities"
+                ],
+                "summary": "Stop tracking a corporation or alliance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity type (corporation or alliance)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tracked-regions": {
+            "get": {
+                "description": "Fetch the regions in which every kill is ingested",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracked regions"
+                ],
+                "summary": "Get tracked regions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TrackedRegion"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a region and fetch every kill of the last week in it from zKillboard. New kills in it are ingested from then on, whether or not they involve tracked characters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracked regions"
+                ],
+                "summary": "Track a region",
+                "parameters": [
+                    {
+                        "description": "Region ID",
+                        "name": "region",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TrackedRegion"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrackedRegion"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrackedRegion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tracked-regions/{id}": {
+            "delete": {
+                "description": "Remove a tracked region. Kills already stored stay.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tracked regions"
+                ],
+                "summary": "Stop tracking a region",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Region ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AllianceDetails": {
+            "type": "object",
+            "properties": {
+                "corporation_count": {
+                    "type": "integer"
+                },
+                "executor_corporation_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "member_count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "stats": {
+                    "$ref": "#/definitions/models.EntityKillStats"
+                },
+                "ticker": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AttackerStats": {
+            "type": "object",
+            "properties": {
+                "character_id": {
+                    "type": "integer"
+                },
+                "damage_done": {
+                    "type": "integer"
+                },
+                "final_blows": {
+                    "type": "integer"
+                },
+                "kill_count": {
+                    "description": "KillCount is the number of kills the character was on",
+                    "type": "integer"
+                },
+                "top_damage": {
+                    "description": "TopDamage is the number of kills the character dealt the most damage on",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CategoryDetails": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "integer"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItemGroup"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "published": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Character": {
+            "type": "object",
+            "properties": {
+                "alliance_id": {
+                    "type": "integer"
+                },
+                "corporation_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "race_id": {
+                    "type": "integer"
+                },
+                "security_status": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CharacterAffiliation": {
+            "type": "object",
+            "properties": {
+                "alliance_id": {
+                    "type": "integer"
+                },
+                "character_id": {
+                    "type": "integer"
+                },
+                "corporation_id": {
+                    "type": "integer"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CharacterStats": {
+            "type": "object",
+            "properties": {
+                "character_id": {
+                    "type": "integer"
+                },
+                "isk_efficiency": {
+                    "description": "ISKEfficiency is the share of ISK destroyed in ISK destroyed and lost, in percent",
+                    "type": "number"
+                },
+                "kill_count": {
+                    "type": "integer"
+                },
+                "loss_count": {
+                    "type": "integer"
+                },
+                "loss_isk": {
+                    "type": "number"
+                },
+                "total_isk": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CorporationDetails": {
+            "type": "object",
+            "properties": {
+                "alliance_id": {
+                    "type": "integer"
+                },
+                "ceo_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "member_count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "stats": {
+                    "$ref": "#/definitions/models.EntityKillStats"
+                },
+                "ticker": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ESIItem": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "market_group_id": {
+                    "type": "integer"
+                },
+                "mass": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "packaged_volume": {
+                    "type": "number"
+                },
+                "portion_size": {
+                    "type": "integer"
+                },
+                "published": {
+                    "type": "boolean"
+                },
+                "radius": {
+                    "type": "number"
+                },
+                "type_id": {
+                    "type": "integer"
+                },
+                "volume": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.EnrichmentStateCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EntityKillStats": {
+            "type": "object",
+            "properties": {
+                "kill_count": {
+                    "type": "integer"
+                },
+                "kill_isk": {
+                    "type": "number"
+                },
+                "loss_count": {
+                    "type": "integer"
+                },
+                "loss_isk": {
+                    "type": "number"
+                },
+                "top_victims": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.VictimCount"
+                    }
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GroupDetails": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "published": {
+                    "type": "boolean"
+                },
+                "types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ESIItem"
+                    }
+                }
+            }
+        },
+        "models.ItemCategory": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "published": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ItemGroup": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "published": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Kill": {
+            "type": "object",
+            "properties": {
+                "attackers": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "characterID": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "killmailID": {
+                    "type": "integer"
+                },
+                "killmailTime": {
+                    "type": "string"
+                },
+                "names": {
+                    "description": "Names holds resolved names of the IDs in the kill when requested",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "description": "Role is whether CharacterID was an attacker or the victim. Kills in\ntracked regions without a tracked character have neither.",
+                    "type": "string"
+                },
+                "solarSystemID": {
+                    "type": "integer"
+                },
+                "space": {
+                    "description": "Space holds who held the solar system when the kill happened",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.KillSpace"
+                        }
+                    ]
+                },
+                "value": {
+                    "description": "Value is our own valuation from market prices, next to ZkillData's",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.KillValue"
+                        }
+                    ]
+                },
+                "victim": {
+                    "$ref": "#/definitions/models.Victim"
+                },
+                "zkillData": {
+                    "$ref": "#/definitions/models.Zkill"
+                }
+            }
+        },
+        "models.KillAttacker": {
+            "type": "object",
+            "properties": {
+                "alliance_id": {
+                    "type": "integer"
+                },
+                "character_id": {
+                    "type": "integer"
+                },
+                "corporation_id": {
+                    "type": "integer"
+                },
+                "damage_done": {
+                    "type": "integer"
+                },
+                "final_blow": {
+                    "type": "boolean"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "killmail_id": {
+                    "type": "integer"
+                },
+                "security_status": {
+                    "type": "number"
+                },
+                "ship_type_id": {
+                    "type": "integer"
+                },
+                "weapon_type_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.KillItem": {
+            "type": "object",
+            "properties": {
+                "flag": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "item_type_id": {
+                    "type": "integer"
+                },
+                "killmail_id": {
+                    "type": "integer"
+                },
+                "parent_index": {
+                    "type": "integer"
+                },
+                "quantity_destroyed": {
+                    "type": "integer"
+                },
+                "quantity_dropped": {
+                    "type": "integer"
+                },
+                "singleton": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.KillParticipant": {
+            "type": "object",
+            "properties": {
+                "character_id": {
+                    "type": "integer"
+                },
+                "damage_done": {
+                    "type": "integer"
+                },
+                "final_blow": {
+                    "type": "boolean"
+                },
+                "killmail_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.KillSpace": {
+            "type": "object",
+            "properties": {
+                "fwoccupierFactionID": {
+                    "type": "integer"
+                },
+                "sovAllianceID": {
+                    "type": "integer"
+                },
+                "sovCorporationID": {
+                    "type": "integer"
+                },
+                "sovFactionID": {
+                    "type": "integer"
+                },
+                "tagged": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.KillValue": {
+            "type": "object",
+            "properties": {
+                "computedAt": {
+                    "type": "string"
+                },
+                "destroyed": {
+                    "type": "number"
+                },
+                "dropped": {
+                    "type": "number"
+                },
+                "historical": {
+                    "type": "boolean"
+                },
+                "hull": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.MarketGroup": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "market_group_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_group_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Name": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refreshed_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PaginatedResponse": {
             "type": "object",
             "properties": {
-                "character_id": {
+                "data": {},
+                "page": {
                     "type": "integer"
                 },
-                "kill_count": {
+                "pageSize": {
                     "type": "integer"
                 },
-                "total_isk": {
+                "totalItems": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Position": {
+            "type": "object",
+            "properties": {
+                "x": {
+                    "type": "number"
+                },
+                "y": {
+                    "type": "number"
+                },
+                "z": {
                     "type": "number"
                 }
             }
         },
-        "models.Attacker": {
+        "models.PriceHistory": {
             "type": "object",
             "properties": {
-                "alliance_id": {
-                    "type": "integer"
+                "average": {
+                    "type": "number"
                 },
-                "character_id": {
+                "date": {
+                    "type": "string"
+                },
+                "highest": {
+                    "type": "number"
+                },
+                "lowest": {
+                    "type": "number"
+                },
+                "order_count": {
                     "type": "integer"
                 },
-                "corporation_id": {
+                "region_id": {
                     "type": "integer"
                 },
-                "damage_done": {
+                "type_id": {
                     "type": "integer"
                 },
-                "faction_id": {
+                "volume": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.Region": {
+            "type": "object",
+            "properties": {
+                "constellations": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "final_blow": {
-                    "type": "boolean"
+                "description": {
+                    "type": "string"
                 },
-                "security_status": {
-                    "type": "number"
+                "name": {
+                    "type": "string"
                 },
-                "ship_type_id": {
+                "region_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Route": {
+            "type": "object",
+            "properties": {
+                "from": {
                     "type": "integer"
                 },
-                "weapon_type_id": {
+                "jumps": {
+                    "type": "integer"
+                },
+                "systems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RouteSystem"
+                    }
+                },
+                "to": {
                     "type": "integer"
                 }
             }
         },
-        "models.Character": {
+        "models.RouteSystem": {
             "type": "object",
             "properties": {
-                "id": {
+                "jumps": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
-                "race_id": {
+                "region_id": {
                     "type": "integer"
                 },
                 "security_status": {
                     "type": "number"
                 },
-                "title": {
-                    "type": "string"
+                "system_id": {
+                    "type": "integer"
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.ShipUsage": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "damage_done": {
+                    "type": "integer"
+                },
+                "final_blows": {
+                    "type": "integer"
+                },
+                "pilot_count": {
+                    "type": "integer"
+                },
+                "ship_type_id": {
+                    "type": "integer"
+                },
+                "use_count": {
+                    "description": "UseCount is the number of attackers that flew the ship",
+                    "type": "integer"
                 }
             }
         },
-        "models.Item": {
+        "models.SpaceBreakdown": {
             "type": "object",
             "properties": {
-                "flag": {
+                "fw_occupier_faction_id": {
                     "type": "integer"
                 },
-                "item_type_id": {
+                "kill_count": {
                     "type": "integer"
                 },
-                "quantity_destroyed": {
+                "sov_alliance_id": {
                     "type": "integer"
                 },
-                "quantity_dropped": {
+                "sov_faction_id": {
                     "type": "integer"
                 },
-                "singleton": {
-                    "type": "integer"
+                "total_isk": {
+                    "type": "number"
                 }
             }
         },
-        "models.Kill": {
+        "models.SystemControl": {
             "type": "object",
             "properties": {
-                "attackers": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Attacker"
-                    }
+                "end_date": {
+                    "type": "string"
                 },
-                "awox": {
-                    "type": "boolean"
+                "fw_contested": {
+                    "type": "string"
                 },
-                "character_id": {
+                "fw_occupier_faction_id": {
                     "type": "integer"
                 },
-                "destroyed_value": {
-                    "type": "number"
+                "fw_owner_faction_id": {
+                    "type": "integer"
                 },
-                "dropped_value": {
-                    "type": "number"
+                "id": {
+                    "type": "integer"
                 },
-                "fitted_value": {
-                    "type": "number"
+                "sov_alliance_id": {
+                    "type": "integer"
                 },
-                "hash": {
-                    "type": "string"
+                "sov_corporation_id": {
+                    "type": "integer"
                 },
-                "killmail_id": {
+                "sov_faction_id": {
                     "type": "integer"
                 },
-                "killmail_time": {
+                "start_date": {
                     "type": "string"
                 },
-                "locationID": {
+                "system_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.TrackedEntity": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
-                "npc": {
-                    "type": "boolean"
-                },
-                "points": {
+                "entity_id": {
                     "type": "integer"
                 },
-                "solar_system_id": {
-                    "type": "integer"
+                "entity_type": {
+                    "type": "string"
                 },
-                "solo": {
-                    "type": "boolean"
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TrackedRegion": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
-                "total_value": {
-                    "type": "number"
+                "name": {
+                    "type": "string"
                 },
-                "victim": {
-                    "$ref": "#/definitions/models.Victim"
+                "region_id": {
+                    "type": "integer"
                 }
             }
         },
-        "models.PaginatedResponse": {
+        "models.Victim": {
             "type": "object",
             "properties": {
-                "data": {},
-                "page": {
+                "allianceID": {
                     "type": "integer"
                 },
-                "pageSize": {
+                "characterID": {
                     "type": "integer"
                 },
-                "totalItems": {
+                "corporationID": {
                     "type": "integer"
                 },
-                "totalPages": {
+                "damageTaken": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ZKillboardItem"
+                    }
+                },
+                "position": {
+                    "$ref": "#/definitions/models.Position"
+                },
+                "shipTypeID": {
                     "type": "integer"
                 }
             }
         },
-        "models.Position": {
+        "models.VictimCount": {
             "type": "object",
             "properties": {
-                "x": {
-                    "type": "number"
+                "corporation_id": {
+                    "type": "integer"
                 },
-                "y": {
-                    "type": "number"
+                "kill_count": {
+                    "type": "integer"
                 },
-                "z": {
+                "total_isk": {
                     "type": "number"
                 }
             }
         },
-        "models.Region": {
+        "models.ZKillboardItem": {
             "type": "object",
             "properties": {
-                "constellations": {
+                "flag": {
+                    "type": "integer"
+                },
+                "item_type_id": {
+                    "type": "integer"
+                },
+                "items": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/models.ZKillboardItem"
                     }
                 },
-                "description": {
-                    "type": "string"
+                "quantity_destroyed": {
+                    "type": "integer"
                 },
-                "name": {
-                    "type": "string"
+                "quantity_dropped": {
+                    "type": "integer"
                 },
-                "region_id": {
+                "singleton": {
                     "type": "integer"
                 }
             }
         },
-        "models.Victim": {
+        "models.Zkill": {
             "type": "object",
             "properties": {
-                "alliance_id": {
-                    "type": "integer"
+                "awox": {
+                    "type": "boolean"
                 },
-                "character_id": {
+                "characterID": {
                     "type": "integer"
                 },
-                "corporation_id": {
-                    "type": "integer"
+                "destroyedValue": {
+                    "type": "number"
+                },
+                "droppedValue": {
+                    "type": "number"
+                },
+                "fittedValue": {
+                    "type": "number"
+                },
+                "hash": {
+                    "type": "string"
                 },
-                "damage_taken": {
+                "id": {
                     "type": "integer"
                 },
-                "faction_id": {
+                "killmailID": {
                     "type": "integer"
                 },
-                "items": {
+                "labels": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Item"
+                        "type": "string"
                     }
                 },
-                "position": {
-                    "$ref": "#/definitions/models.Position"
+                "locationID": {
+                    "type": "integer"
                 },
-                "ship_type_id": {
+                "npc": {
+                    "type": "boolean"
+                },
+                "points": {
                     "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "solo": {
+                    "type": "boolean"
+                },
+                "totalValue": {
+                    "type": "number"
+                }
+            }
+        },
+        "routes.NamesRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "routes.RequeueRequest": {
+            "type": "object",
+            "properties": {
+                "killmail_ids": {
+                    "description": "KillmailIDs requeues these jobs; all failed jobs when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "state": {
+                    "description": "State narrows the jobs to failed or permanent ones",
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,28 @@
+// Command fakeupstream serves ESI and zKillboard fixtures over HTTP. Point the
+// backend at it with ESI_BASE_URL=http://<addr>/esi and
+// ZKILL_BASE_URL=http://<addr>/zkill to run ingestion offline, and
+// REDISQ_URL=http://<addr>/redisq/listen.php to consume the fake RedisQ.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/tadeasf/eve-ran/src/fakeupstream"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "listen address")
+	dir := flag.String("fixtures", "", "fixture directory (defaults to the bundled fixtures)")
+	flag.Parse()
+
+	fixtures := fakeupstream.Fixtures()
+	if *dir != "" {
+		fixtures = os.DirFS(*dir)
+	}
+
+	log.Printf("Serving fake ESI under /esi, fake zKillboard under /zkill and fake RedisQ at /redisq/listen.php on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, fakeupstream.NewHandler(fixtures)))
+}
@@ -0,0 +1,612 @@
+package db
+
+// The baseline schema is frozen as it was when versioned migrations were
+// introduced. Do not edit it to match the models; change the schema by
+// appending a migration.
+
+// baselineTables lists the tables of the baseline schema in creation order.
+var baselineTables = []string{
+	"characters",
+	"zkills",
+	"kills",
+	"kill_items",
+	"kill_attackers",
+	"kill_participants",
+	"regions",
+	"systems",
+	"stargates",
+	"system_controls",
+	"constellations",
+	"esi_items",
+	"market_prices",
+	"price_histories",
+	"item_categories",
+	"item_groups",
+	"market_groups",
+	"esi_cache_entries",
+	"names",
+	"corporations",
+	"alliances",
+	"character_affiliations",
+	"tracked_entities",
+	"tracked_regions",
+	"z_kill_cursors",
+	"enrichment_jobs",
+}
+
+// baselinePostgres creates the baseline tables on Postgres. Tables that
+// exist already are kept, so databases created by the boot-time AutoMigrate
+// of earlier releases are adopted as they are.
+var baselinePostgres = []string{
+	`CREATE TABLE IF NOT EXISTS characters (
+		id bigserial,
+		name text,
+		security_status decimal,
+		title text,
+		race_id bigint,
+		corporation_id bigint,
+		alliance_id bigint,
+		PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS zkills (
+		id bigserial,
+		killmail_id bigint,
+		character_id bigint,
+		role text,
+		location_id bigint,
+		hash text,
+		fitted_value decimal,
+		dropped_value decimal,
+		destroyed_value decimal,
+		total_value decimal,
+		points bigint,
+		npc boolean,
+		solo boolean,
+		awox boolean,
+		labels text[],
+		PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS kills (
+		id bigserial,
+		killmail_id bigint,
+		killmail_time timestamptz,
+		solar_system_id bigint,
+		character_id bigint,
+		role text,
+		victim_alliance_id bigint,
+		victim_character_id bigint,
+		victim_corporation_id bigint,
+		victim_damage_taken bigint,
+		victim_ship_type_id bigint,
+		victim_position_x decimal,
+		victim_position_y decimal,
+		victim_position_z decimal,
+		victim_items jsonb,
+		attackers jsonb,
+		value_hull decimal,
+		value_destroyed decimal,
+		value_dropped decimal,
+		value_total decimal,
+		value_historical boolean,
+		value_computed_at timestamptz,
+		space_sov_alliance_id bigint,
+		space_sov_corporation_id bigint,
+		space_sov_faction_id bigint,
+		space_fw_occupier_faction_id bigint,
+		space_tagged boolean,
+		PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS kill_items (
+		killmail_id bigint,
+		"index" bigint,
+		parent_index bigint,
+		flag bigint,
+		item_type_id bigint,
+		quantity_destroyed bigint,
+		quantity_dropped bigint,
+		singleton bigint,
+		PRIMARY KEY (killmail_id,"index")
+	)`,
+	`CREATE TABLE IF NOT EXISTS kill_attackers (
+		killmail_id bigint,
+		"index" bigint,
+		character_id bigint,
+		corporation_id bigint,
+		alliance_id bigint,
+		ship_type_id bigint,
+		weapon_type_id bigint,
+		damage_done bigint,
+		final_blow boolean,
+		security_status decimal,
+		PRIMARY KEY (killmail_id,"index")
+	)`,
+	`CREATE TABLE IF NOT EXISTS kill_participants (
+		killmail_id bigint,
+		character_id bigint,
+		role text,
+		final_blow boolean,
+		damage_done bigint,
+		PRIMARY KEY (killmail_id,character_id,role)
+	)`,
+	`CREATE TABLE IF NOT EXISTS regions (
+		region_id bigserial,
+		name text,
+		description text,
+		constellations jsonb,
+		PRIMARY KEY (region_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS systems (
+		system_id bigserial,
+		constellation_id bigint,
+		region_id bigint,
+		name text,
+		security_class text,
+		security_status decimal,
+		star_id bigint,
+		planets jsonb,
+		stargates jsonb,
+		stations jsonb,
+		position jsonb,
+		PRIMARY KEY (system_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS stargates (
+		stargate_id bigserial,
+		name text,
+		system_id bigint,
+		destination_stargate_id bigint,
+		destination_system_id bigint,
+		PRIMARY KEY (stargate_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS system_controls (
+		id bigserial,
+		system_id bigint,
+		sov_alliance_id bigint,
+		sov_corporation_id bigint,
+		sov_faction_id bigint,
+		fw_owner_faction_id bigint,
+		fw_occupier_faction_id bigint,
+		fw_contested text,
+		start_date timestamptz,
+		end_date timestamptz,
+		PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS constellations (
+		constellation_id bigserial,
+		name text,
+		region_id bigint,
+		systems jsonb,
+		position jsonb,
+		PRIMARY KEY (constellation_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS esi_items (
+		type_id bigserial,
+		group_id bigint,
+		market_group_id bigint,
+		name text,
+		description text,
+		mass decimal,
+		volume decimal,
+		capacity decimal,
+		portion_size bigint,
+		packaged_volume decimal,
+		published boolean,
+		radius decimal,
+		PRIMARY KEY (type_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS market_prices (
+		type_id bigserial,
+		average_price decimal,
+		adjusted_price decimal,
+		updated_at timestamptz,
+		PRIMARY KEY (type_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS price_histories (
+		region_id bigint,
+		type_id bigint,
+		date date,
+		average decimal,
+		highest decimal,
+		lowest decimal,
+		volume bigint,
+		order_count bigint,
+		PRIMARY KEY (region_id,type_id,date)
+	)`,
+	`CREATE TABLE IF NOT EXISTS item_categories (
+		category_id bigserial,
+		name text,
+		published boolean,
+		PRIMARY KEY (category_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS item_groups (
+		group_id bigserial,
+		category_id bigint,
+		name text,
+		published boolean,
+		PRIMARY KEY (group_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS market_groups (
+		market_group_id bigserial,
+		parent_group_id bigint,
+		name text,
+		description text,
+		PRIMARY KEY (market_group_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS esi_cache_entries (
+		path text,
+		etag text,
+		expires timestamptz,
+		body bytea,
+		updated_at timestamptz,
+		PRIMARY KEY (path)
+	)`,
+	`CREATE TABLE IF NOT EXISTS names (
+		id bigserial,
+		name text,
+		category text,
+		refreshed_at timestamptz,
+		PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS corporations (
+		id bigserial,
+		name text,
+		ticker text,
+		member_count bigint,
+		alliance_id bigint,
+		ceo_id bigint,
+		updated_at timestamptz,
+		PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS alliances (
+		id bigserial,
+		name text,
+		ticker text,
+		executor_corporation_id bigint,
+		corporation_count bigint,
+		member_count bigint,
+		updated_at timestamptz,
+		PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS character_affiliations (
+		id bigserial,
+		character_id bigint,
+		corporation_id bigint,
+		alliance_id bigint,
+		start_date timestamptz,
+		end_date timestamptz,
+		PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS tracked_entities (
+		entity_type text,
+		entity_id bigint,
+		name text,
+		created_at timestamptz,
+		PRIMARY KEY (entity_type,entity_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS tracked_regions (
+		region_id bigint,
+		name text,
+		created_at timestamptz,
+		PRIMARY KEY (region_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS z_kill_cursors (
+		path text,
+		last_killmail_id bigint,
+		last_page bigint,
+		backfill_done boolean,
+		updated_at timestamptz,
+		PRIMARY KEY (path)
+	)`,
+	`CREATE TABLE IF NOT EXISTS enrichment_jobs (
+		killmail_id bigint,
+		state text,
+		attempts bigint,
+		next_attempt_at timestamptz,
+		last_error text,
+		claimed_at timestamptz,
+		created_at timestamptz,
+		updated_at timestamptz,
+		PRIMARY KEY (killmail_id)
+	)`,
+	// Columns added after the first releases, whose tables were created by
+	// the boot-time AutoMigrate without them
+	`ALTER TABLE characters ADD COLUMN IF NOT EXISTS corporation_id bigint`,
+	`ALTER TABLE characters ADD COLUMN IF NOT EXISTS alliance_id bigint`,
+	`ALTER TABLE zkills ADD COLUMN IF NOT EXISTS role text`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS role text`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS value_hull decimal`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS value_destroyed decimal`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS value_dropped decimal`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS value_total decimal`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS value_historical boolean`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS value_computed_at timestamptz`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS space_sov_alliance_id bigint`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS space_sov_corporation_id bigint`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS space_sov_faction_id bigint`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS space_fw_occupier_faction_id bigint`,
+	`ALTER TABLE kills ADD COLUMN IF NOT EXISTS space_tagged boolean`,
+	`ALTER TABLE esi_items ADD COLUMN IF NOT EXISTS market_group_id bigint`,
+}
+
+// baselineSQLite creates the baseline tables on SQLite.
+var baselineSQLite = []string{
+	`CREATE TABLE IF NOT EXISTS characters (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		name text,
+		security_status real,
+		title text,
+		race_id integer,
+		corporation_id integer,
+		alliance_id integer
+	)`,
+	`CREATE TABLE IF NOT EXISTS zkills (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		killmail_id integer,
+		character_id integer,
+		role text,
+		location_id integer,
+		hash text,
+		fitted_value real,
+		dropped_value real,
+		destroyed_value real,
+		total_value real,
+		points integer,
+		npc numeric,
+		solo numeric,
+		awox numeric,
+		labels text[]
+	)`,
+	`CREATE TABLE IF NOT EXISTS kills (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		killmail_id integer,
+		killmail_time datetime,
+		solar_system_id integer,
+		character_id integer,
+		role text,
+		victim_alliance_id integer,
+		victim_character_id integer,
+		victim_corporation_id integer,
+		victim_damage_taken integer,
+		victim_ship_type_id integer,
+		victim_position_x real,
+		victim_position_y real,
+		victim_position_z real,
+		victim_items jsonb,
+		attackers jsonb,
+		value_hull real,
+		value_destroyed real,
+		value_dropped real,
+		value_total real,
+		value_historical numeric,
+		value_computed_at datetime,
+		space_sov_alliance_id integer,
+		space_sov_corporation_id integer,
+		space_sov_faction_id integer,
+		space_fw_occupier_faction_id integer,
+		space_tagged numeric
+	)`,
+	`CREATE TABLE IF NOT EXISTS kill_items (
+		killmail_id integer,
+		"index" integer,
+		parent_index integer,
+		flag integer,
+		item_type_id integer,
+		quantity_destroyed integer,
+		quantity_dropped integer,
+		singleton integer,
+		PRIMARY KEY (killmail_id,"index")
+	)`,
+	`CREATE TABLE IF NOT EXISTS kill_attackers (
+		killmail_id integer,
+		"index" integer,
+		character_id integer,
+		corporation_id integer,
+		alliance_id integer,
+		ship_type_id integer,
+		weapon_type_id integer,
+		damage_done integer,
+		final_blow numeric,
+		security_status real,
+		PRIMARY KEY (killmail_id,"index")
+	)`,
+	`CREATE TABLE IF NOT EXISTS kill_participants (
+		killmail_id integer,
+		character_id integer,
+		role text,
+		final_blow numeric,
+		damage_done integer,
+		PRIMARY KEY (killmail_id,character_id,role)
+	)`,
+	`CREATE TABLE IF NOT EXISTS regions (
+		region_id integer PRIMARY KEY AUTOINCREMENT,
+		name text,
+		description text,
+		constellations jsonb
+	)`,
+	`CREATE TABLE IF NOT EXISTS systems (
+		system_id integer PRIMARY KEY AUTOINCREMENT,
+		constellation_id integer,
+		region_id integer,
+		name text,
+		security_class text,
+		security_status real,
+		star_id integer,
+		planets jsonb,
+		stargates jsonb,
+		stations jsonb,
+		position jsonb
+	)`,
+	`CREATE TABLE IF NOT EXISTS stargates (
+		stargate_id integer PRIMARY KEY AUTOINCREMENT,
+		name text,
+		system_id integer,
+		destination_stargate_id integer,
+		destination_system_id integer
+	)`,
+	`CREATE TABLE IF NOT EXISTS system_controls (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		system_id integer,
+		sov_alliance_id integer,
+		sov_corporation_id integer,
+		sov_faction_id integer,
+		fw_owner_faction_id integer,
+		fw_occupier_faction_id integer,
+		fw_contested text,
+		start_date datetime,
+		end_date datetime
+	)`,
+	`CREATE TABLE IF NOT EXISTS constellations (
+		constellation_id integer PRIMARY KEY AUTOINCREMENT,
+		name text,
+		region_id integer,
+		systems jsonb,
+		position jsonb
+	)`,
+	`CREATE TABLE IF NOT EXISTS esi_items (
+		type_id integer PRIMARY KEY AUTOINCREMENT,
+		group_id integer,
+		market_group_id integer,
+		name text,
+		description text,
+		mass real,
+		volume real,
+		capacity real,
+		portion_size integer,
+		packaged_volume real,
+		published numeric,
+		radius real
+	)`,
+	`CREATE TABLE IF NOT EXISTS market_prices (
+		type_id integer PRIMARY KEY AUTOINCREMENT,
+		average_price real,
+		adjusted_price real,
+		updated_at datetime
+	)`,
+	`CREATE TABLE IF NOT EXISTS price_histories (
+		region_id integer,
+		type_id integer,
+		date date,
+		average real,
+		highest real,
+		lowest real,
+		volume integer,
+		order_count integer,
+		PRIMARY KEY (region_id,type_id,date)
+	)`,
+	`CREATE TABLE IF NOT EXISTS item_categories (
+		category_id integer PRIMARY KEY AUTOINCREMENT,
+		name text,
+		published numeric
+	)`,
+	`CREATE TABLE IF NOT EXISTS item_groups (
+		group_id integer PRIMARY KEY AUTOINCREMENT,
+		category_id integer,
+		name text,
+		published numeric
+	)`,
+	`CREATE TABLE IF NOT EXISTS market_groups (
+		market_group_id integer PRIMARY KEY AUTOINCREMENT,
+		parent_group_id integer,
+		name text,
+		description text
+	)`,
+	`CREATE TABLE IF NOT EXISTS esi_cache_entries (
+		path text,
+		etag text,
+		expires datetime,
+		body blob,
+		updated_at datetime,
+		PRIMARY KEY (path)
+	)`,
+	`CREATE TABLE IF NOT EXISTS names (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		name text,
+		category text,
+		refreshed_at datetime
+	)`,
+	`CREATE TABLE IF NOT EXISTS corporations (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		name text,
+		ticker text,
+		member_count integer,
+		alliance_id integer,
+		ceo_id integer,
+		updated_at datetime
+	)`,
+	`CREATE TABLE IF NOT EXISTS alliances (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		name text,
+		ticker text,
+		executor_corporation_id integer,
+		corporation_count integer,
+		member_count integer,
+		updated_at datetime
+	)`,
+	`CREATE TABLE IF NOT EXISTS character_affiliations (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		character_id integer,
+		corporation_id integer,
+		alliance_id integer,
+		start_date datetime,
+		end_date datetime
+	)`,
+	`CREATE TABLE IF NOT EXISTS tracked_entities (
+		entity_type text,
+		entity_id integer,
+		name text,
+		created_at datetime,
+		PRIMARY KEY (entity_type,entity_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS tracked_regions (
+		region_id integer,
+		name text,
+		created_at datetime,
+		PRIMARY KEY (region_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS z_kill_cursors (
+		path text,
+		last_killmail_id integer,
+		last_page integer,
+		backfill_done numeric,
+		updated_at datetime,
+		PRIMARY KEY (path)
+	)`,
+	`CREATE TABLE IF NOT EXISTS enrichment_jobs (
+		killmail_id integer,
+		state text,
+		attempts integer,
+		next_attempt_at datetime,
+		last_error text,
+		claimed_at datetime,
+		created_at datetime,
+		updated_at datetime,
+		PRIMARY KEY (killmail_id)
+	)`,
+}
+
+// baselineIndexes creates the indexes of the baseline tables on both
+// databases.
+var baselineIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_characters_alliance_id ON characters (alliance_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_characters_corporation_id ON characters (corporation_id)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_zkills_killmail_id ON zkills (killmail_id)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_kills_killmail_id ON kills (killmail_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_kills_role ON kills (role)`,
+	`CREATE INDEX IF NOT EXISTS idx_kill_items_item_type_id ON kill_items (item_type_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_kill_attackers_character_id ON kill_attackers (character_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_kill_attackers_ship_type_id ON kill_attackers (ship_type_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_kill_attackers_alliance_id ON kill_attackers (alliance_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_kill_attackers_corporation_id ON kill_attackers (corporation_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_kill_participants_character_id ON kill_participants (character_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_stargates_destination_system_id ON stargates (destination_system_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_stargates_system_id ON stargates (system_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_system_controls_system_id ON system_controls (system_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_esi_items_group_id ON esi_items (group_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_esi_items_market_group_id ON esi_items (market_group_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_item_groups_category_id ON item_groups (category_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_market_groups_parent_group_id ON market_groups (parent_group_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_names_category ON names (category)`,
+	`CREATE INDEX IF NOT EXISTS idx_corporations_alliance_id ON corporations (alliance_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_character_affiliations_corporation_id ON character_affiliations (corporation_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_character_affiliations_character_id ON character_affiliations (character_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_claim ON enrichment_jobs (state, next_attempt_at)`,
+}
@@ -6,7 +6,7 @@ import (
 	"os"
 	"time"
 
-	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -14,21 +14,18 @@ import (
 // DB is a package-level variable that holds the database connection
 var DB *gorm.DB
 
+// InitDB connects to the database selected by DB_DRIVER: postgres (the
+// default), configured by DB_HOST, DB_PORT, DB_USER, DB_PASSWORD and DB_NAME,
+// or sqlite, a single file at DB_PATH for local use without a Postgres server.
 func InitDB() {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
-
-	log.Printf("Attempting to connect to database with DSN: host=%s port=%s user=%s dbname=%s", host, port, user, dbname)
+	dialector := postgresDialector()
+	if os.Getenv("DB_DRIVER") == "sqlite" {
+		dialector = sqliteDialector()
+	}
 
 	var err error
 	for i := 0; i < 5; i++ {
-		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		DB, err = gorm.Open(dialector, &gorm.Config{})
 		if err == nil {
 			break
 		}
@@ -41,31 +38,35 @@ func InitDB() {
 	}
 
 	log.Println("Successfully connected to the database")
+}
 
-	err = MigrateSchema()
-	if err != nil {
-		log.Fatal("Failed to migrate schema:", err)
-	}
+func postgresDialector() gorm.Dialector {
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+	dbname := os.Getenv("DB_NAME")
+
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		host, port, user, password, dbname)
+
+	log.Printf("Attempting to connect to database with DSN: host=%s port=%s user=%s dbname=%s", host, port, user, dbname)
+	return postgres.Open(dsn)
 }
 
-func MigrateSchema() error {
-	// List of all models that should be migrated
-	models := []interface{}{
-		&models.Character{},
-		&models.Zkill{},
-		&models.Kill{},
-		&models.Region{},
-		&models.System{},
-		&models.Constellation{},
-		&models.ESIItem{},
+func sqliteDialector() gorm.Dialector {
+	path := os.Getenv("DB_PATH")
+	if path == "" {
+		path = "eve-ran.db"
 	}
 
-	for _, model := range models {
-		if err := DB.AutoMigrate(model); err != nil {
-			return fmt.Errorf("failed to migrate %T: %v", model, err)
-		}
-	}
+	log.Printf("Attempting to open SQLite database %s", path)
+	// Writers wait for each other instead of failing with SQLITE_BUSY, and
+	// foreign keys are enforced as in Postgres
+	return sqlite.Open(path + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+}
 
-	fmt.Println("Schema migration completed successfully")
-	return nil
+// IsSQLite reports whether conn is a SQLite connection.
+func IsSQLite(conn *gorm.DB) bool {
+	return conn.Dialector.Name() == "sqlite"
 }
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a versioned schema change. Up applies it and Down reverts
+// it, both inside a transaction.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+	// Destructive migrations lose data when reverted, e.g. by dropping
+	// tables, and are only reverted when forced.
+	Destructive bool
+}
+
+// SchemaVersion records an applied migration in the schema_version table.
+type SchemaVersion struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (SchemaVersion) TableName() string {
+	return "schema_version"
+}
+
+// MigrationStatus reports whether a migration is applied.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// migrationLockID keys the advisory lock serializing concurrent migrators,
+// e.g. several replicas booting at once.
+const migrationLockID = 7266434
+
+// Migrate applies every pending migration in order.
+func Migrate() error {
+	return MigrateTo(latestVersion(), false)
+}
+
+// MigrateTo applies or reverts migrations until the schema is at version.
+// Version 0 reverts every migration. Destructive migrations are only
+// reverted when force is set; otherwise nothing is reverted.
+func MigrateTo(version int, force bool) error {
+	if version < 0 || version > latestVersion() {
+		return fmt.Errorf("unknown schema version %d, latest is %d", version, latestVersion())
+	}
+	if err := DB.AutoMigrate(&SchemaVersion{}); err != nil {
+		return fmt.Errorf("failed to create schema_version: %w", err)
+	}
+
+	current, err := CurrentVersion()
+	if err != nil {
+		return err
+	}
+	for _, migration := range sortedMigrations() {
+		if migration.Version > current && migration.Version <= version {
+			if err := runMigration(migration, true); err != nil {
+				return err
+			}
+		}
+	}
+	reversed := sortedMigrations()
+	sort.Slice(reversed, func(i, j int) bool { return reversed[i].Version > reversed[j].Version })
+	for _, migration := range reversed {
+		if migration.Version <= current && migration.Version > version && migration.Destructive && !force {
+			return fmt.Errorf("reverting migration %d %s loses data; force it to revert", migration.Version, migration.Name)
+		}
+	}
+	for _, migration := range reversed {
+		if migration.Version <= current && migration.Version > version {
+			if err := runMigration(migration, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Rollback reverts the last steps applied migrations. Destructive
+// migrations are only reverted when force is set.
+func Rollback(steps int, force bool) error {
+	current, err := CurrentVersion()
+	if err != nil {
+		return err
+	}
+	target := 0
+	applied := 0
+	reversed := sortedMigrations()
+	for i := len(reversed) - 1; i >= 0; i-- {
+		if reversed[i].Version > current {
+			continue
+		}
+		if applied == steps {
+			target = reversed[i].Version
+			break
+		}
+		applied++
+	}
+	return MigrateTo(target, force)
+}
+
+// CurrentVersion returns the version of the newest applied migration, 0
+// when none is applied.
+func CurrentVersion() (int, error) {
+	var version int
+	err := DB.Model(&SchemaVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// GetMigrationStatus lists every known migration with when it was applied.
+func GetMigrationStatus() ([]MigrationStatus, error) {
+	if err := DB.AutoMigrate(&SchemaVersion{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_version: %w", err)
+	}
+	var applied []SchemaVersion
+	if err := DB.Find(&applied).Error; err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int]time.Time, len(applied))
+	for _, version := range applied {
+		appliedAt[version.Version] = version.AppliedAt
+	}
+
+	var status []MigrationStatus
+	for _, migration := range sortedMigrations() {
+		entry := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if at, ok := appliedAt[migration.Version]; ok {
+			entry.AppliedAt = &at
+		}
+		status = append(status, entry)
+	}
+	return status, nil
+}
+
+// lockMigrations takes the migration lock until tx ends. SQLite needs none:
+// its single writer already serializes migrators.
+func lockMigrations(tx *gorm.DB) error {
+	if IsSQLite(tx) {
+		return nil
+	}
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error
+}
+
+// runMigration applies (up) or reverts a migration and records it. Another
+// migrator holding the lock makes this one wait, after which a migration it
+// already ran is skipped.
+func runMigration(migration Migration, up bool) error {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+
+	ran := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockMigrations(tx); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&SchemaVersion{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if (count > 0) == up {
+			return nil
+		}
+
+		ran = true
+		if up {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaVersion{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		}
+		if migration.Down == nil {
+			return errors.New("migration cannot be reverted")
+		}
+		if err := migration.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaVersion{}, migration.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d %s (%s) failed: %w", migration.Version, migration.Name, direction, err)
+	}
+	if ran {
+		log.Printf("Migrated %s: %d %s", direction, migration.Version, migration.Name)
+	}
+	return nil
+}
+
+func sortedMigrations() []Migration {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
+
+func latestVersion() int {
+	latest := 0
+	for _, migration := range migrations {
+		latest = max(latest, migration.Version)
+	}
+	return latest
+}
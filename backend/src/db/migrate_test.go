@@ -0,0 +1,76 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "eve-ran.db"))
+
+	InitDB()
+	t.Cleanup(func() {
+		if sqlDB, err := DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
+
+func assertVersion(t *testing.T, want int) {
+	t.Helper()
+	version, err := CurrentVersion()
+	if err != nil {
+		t.Fatalf("CurrentVersion: %v", err)
+	}
+	if version != want {
+		t.Fatalf("schema at version %d, want %d", version, want)
+	}
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	setupTestDB(t)
+
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	assertVersion(t, latestVersion())
+	for _, table := range baselineTables {
+		if !DB.Migrator().HasTable(table) {
+			t.Errorf("table %s missing after migrating up", table)
+		}
+	}
+	if !DB.Migrator().HasIndex("kills", "idx_kills_killmail_time") {
+		t.Error("query index missing after migrating up")
+	}
+
+	if err := Rollback(1, false); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	assertVersion(t, latestVersion()-1)
+	if DB.Migrator().HasIndex("kills", "idx_kills_killmail_time") {
+		t.Error("query index left after reverting its migration")
+	}
+
+	// The baseline drops every table, so it needs force
+	if err := MigrateTo(0, false); err == nil {
+		t.Fatal("reverted the baseline without force")
+	}
+	assertVersion(t, latestVersion()-1)
+
+	if err := MigrateTo(0, true); err != nil {
+		t.Fatalf("MigrateTo(0, force): %v", err)
+	}
+	assertVersion(t, 0)
+	for _, table := range baselineTables {
+		if DB.Migrator().HasTable(table) {
+			t.Errorf("table %s left after reverting the baseline", table)
+		}
+	}
+
+	if err := Migrate(); err != nil {
+		t.Fatalf("Migrate after reverting everything: %v", err)
+	}
+	assertVersion(t, latestVersion())
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// migrations is every schema change in version order. Applied migrations
+// must not be edited; change the schema by appending a new one.
+var migrations = []Migration{
+	{
+		// Creates the schema as it was when versioned migrations were
+		// introduced, from the frozen statements in baseline.go. Reverting
+		// it drops every table and is refused unless forced.
+		Version:     1,
+		Name:        "baseline",
+		Destructive: true,
+		Up: func(tx *gorm.DB) error {
+			tables := baselinePostgres
+			if IsSQLite(tx) {
+				tables = baselineSQLite
+			}
+			for _, statements := range [][]string{tables, baselineIndexes} {
+				for _, statement := range statements {
+					if err := tx.Exec(statement).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for i := len(baselineTables) - 1; i >= 0; i-- {
+				if err := tx.Exec("DROP TABLE IF EXISTS " + baselineTables[i]).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		// Fills kill_attackers from the attackers JSON of kills stored
+		// before the table existed.
+		Version: 2,
+		Name:    "backfill_kill_attackers",
+		Up:      backfillKillAttackers,
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	},
+	{
+		// Indexes the filters, joins and orderings of the query layer.
+		Version: 3,
+		Name:    "query_indexes",
+		Up: func(tx *gorm.DB) error {
+			for _, index := range queryIndexes {
+				if index.postgresOnly && IsSQLite(tx) {
+					continue
+				}
+				if err := tx.Exec(index.create).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, index := range queryIndexes {
+				if err := tx.Exec("DROP INDEX IF EXISTS " + index.name).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+type queryIndex struct {
+	name   string
+	create string
+	// postgresOnly indexes are skipped on SQLite, which lacks the index
+	// method.
+	postgresOnly bool
+}
+
+var queryIndexes = []queryIndex{
+	{"idx_kills_killmail_time", "CREATE INDEX IF NOT EXISTS idx_kills_killmail_time ON kills (killmail_time)", false},
+	{"idx_kills_character_role_time", "CREATE INDEX IF NOT EXISTS idx_kills_character_role_time ON kills (character_id, role, killmail_time)", false},
+	{"idx_kills_solar_system_id", "CREATE INDEX IF NOT EXISTS idx_kills_solar_system_id ON kills (solar_system_id)", false},
+	{"idx_kills_victim_character_id", "CREATE INDEX IF NOT EXISTS idx_kills_victim_character_id ON kills (victim_character_id)", false},
+	{"idx_kills_victim_corporation_id", "CREATE INDEX IF NOT EXISTS idx_kills_victim_corporation_id ON kills (victim_corporation_id)", false},
+	{"idx_kills_victim_alliance_id", "CREATE INDEX IF NOT EXISTS idx_kills_victim_alliance_id ON kills (victim_alliance_id)", false},
+	{"idx_kills_victim_ship_type_id", "CREATE INDEX IF NOT EXISTS idx_kills_victim_ship_type_id ON kills (victim_ship_type_id)", false},
+	{"idx_kills_space_sov_alliance_id", "CREATE INDEX IF NOT EXISTS idx_kills_space_sov_alliance_id ON kills (space_sov_alliance_id)", false},
+	{"idx_zkills_character_id", "CREATE INDEX IF NOT EXISTS idx_zkills_character_id ON zkills (character_id)", false},
+	{"idx_zkills_labels", "CREATE INDEX IF NOT EXISTS idx_zkills_labels ON zkills USING GIN (labels)", true},
+	{"idx_kill_participants_character_role", "CREATE INDEX IF NOT EXISTS idx_kill_participants_character_role ON kill_participants (character_id, role)", false},
+	{"idx_systems_region_id", "CREATE INDEX IF NOT EXISTS idx_systems_region_id ON systems (region_id)", false},
+	{"idx_character_affiliations_character_start", "CREATE INDEX IF NOT EXISTS idx_character_affiliations_character_start ON character_affiliations (character_id, start_date)", false},
+}
+
+// backfillKillAttackers fills kill_attackers from the attackers JSON of kills
+// stored before the table existed.
+func backfillKillAttackers(tx *gorm.DB) error {
+	query := `
+		INSERT INTO kill_attackers (killmail_id, "index", character_id, corporation_id, alliance_id,
+			ship_type_id, weapon_type_id, damage_done, final_blow, security_status)
+		SELECT kills.killmail_id, attacker.ordinality - 1,
+			COALESCE((attacker.value->>'character_id')::bigint, 0),
+			COALESCE((attacker.value->>'corporation_id')::bigint, 0),
+			COALESCE((attacker.value->>'alliance_id')::bigint, 0),
+			COALESCE((attacker.value->>'ship_type_id')::integer, 0),
+			COALESCE((attacker.value->>'weapon_type_id')::integer, 0),
+			COALESCE((attacker.value->>'damage_done')::integer, 0),
+			COALESCE((attacker.value->>'final_blow')::boolean, false),
+			COALESCE((attacker.value->>'security_status')::double precision, 0)
+		FROM kills
+		CROSS JOIN LATERAL jsonb_array_elements(
+			CASE WHEN jsonb_typeof(kills.attackers) = 'array' THEN kills.attackers ELSE '[]'::jsonb END
+		) WITH ORDINALITY AS attacker(value, ordinality)
+		WHERE NOT EXISTS (SELECT 1 FROM kill_attackers WHERE kill_attackers.killmail_id = kills.killmail_id)
+		ON CONFLICT DO NOTHING`
+	if IsSQLite(tx) {
+		query = sqliteBackfillKillAttackers
+	}
+
+	result := tx.Exec(query)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Backfilled %d kill attackers", result.RowsAffected)
+	}
+	return nil
+}
+
+// sqliteBackfillKillAttackers is backfillKillAttackers for SQLite, which
+// stores the attackers JSON as a blob.
+const sqliteBackfillKillAttackers = `
+	INSERT INTO kill_attackers (killmail_id, "index", character_id, corporation_id, alliance_id,
+		ship_type_id, weapon_type_id, damage_done, final_blow, security_status)
+	SELECT kills.killmail_id, attacker.key,
+		COALESCE(json_extract(attacker.value, '$.character_id'), 0),
+		COALESCE(json_extract(attacker.value, '$.corporation_id'), 0),
+		COALESCE(json_extract(attacker.value, '$.alliance_id'), 0),
+		COALESCE(json_extract(attacker.value, '$.ship_type_id'), 0),
+		COALESCE(json_extract(attacker.value, '$.weapon_type_id'), 0),
+		COALESCE(json_extract(attacker.value, '$.damage_done'), 0),
+		COALESCE(json_extract(attacker.value, '$.final_blow'), false),
+		COALESCE(json_extract(attacker.value, '$.security_status'), 0)
+	FROM kills, json_each(CASE
+		WHEN json_valid(kills.attackers) AND json_type(CAST(kills.attackers AS TEXT)) = 'array'
+		THEN CAST(kills.attackers AS TEXT) ELSE '[]' END) AS attacker
+	WHERE NOT EXISTS (SELECT 1 FROM kill_attackers WHERE kill_attackers.killmail_id = kills.killmail_id)
+	ON CONFLICT DO NOTHING`
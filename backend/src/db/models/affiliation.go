@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// CharacterAffiliation records the corporation and alliance a character
+// belonged to from StartDate until EndDate. The current affiliation has no
+// EndDate.
+type CharacterAffiliation struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	CharacterID   int64      `gorm:"index" json:"character_id"`
+	CorporationID int64      `gorm:"index" json:"corporation_id"`
+	AllianceID    int64      `json:"alliance_id"`
+	StartDate     time.Time  `json:"start_date"`
+	EndDate       *time.Time `json:"end_date"`
+}
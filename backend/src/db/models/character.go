@@ -5,6 +5,10 @@ type CharacterStats struct {
 	CharacterID int64   `json:"character_id"`
 	KillCount   int     `json:"kill_count"`
 	TotalISK    float64 `json:"total_isk"`
+	LossCount   int     `json:"loss_count"`
+	LossISK     float64 `json:"loss_isk"`
+	// ISKEfficiency is the share of ISK destroyed in ISK destroyed and lost, in percent
+	ISKEfficiency float64 `json:"isk_efficiency"`
 }
 
 // Character model
@@ -14,4 +18,6 @@ type Character struct {
 	SecurityStatus float64 `json:"security_status"`
 	Title          string  `json:"title"`
 	RaceID         int     `json:"race_id"`
+	CorporationID  int64   `gorm:"index" json:"corporation_id"`
+	AllianceID     int64   `gorm:"index" json:"alliance_id"`
 }
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Corporation model
+type Corporation struct {
+	ID          int64     `gorm:"primaryKey" json:"id"`
+	Name        string    `json:"name"`
+	Ticker      string    `json:"ticker"`
+	MemberCount int       `json:"member_count"`
+	AllianceID  int64     `gorm:"index" json:"alliance_id"`
+	CEOID       int64     `json:"ceo_id"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Alliance model
+type Alliance struct {
+	ID                    int64     `gorm:"primaryKey" json:"id"`
+	Name                  string    `json:"name"`
+	Ticker                string    `json:"ticker"`
+	ExecutorCorporationID int64     `json:"executor_corporation_id"`
+	CorporationCount      int       `json:"corporation_count"`
+	MemberCount           int       `json:"member_count"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// VictimCount counts the kills scored against one victim corporation
+type VictimCount struct {
+	CorporationID int64   `json:"corporation_id"`
+	KillCount     int     `json:"kill_count"`
+	TotalISK      float64 `json:"total_isk"`
+}
+
+// EntityKillStats aggregates the kills and losses of a corporation or alliance
+type EntityKillStats struct {
+	KillCount  int           `json:"kill_count"`
+	KillISK    float64       `json:"kill_isk"`
+	LossCount  int           `json:"loss_count"`
+	LossISK    float64       `json:"loss_isk"`
+	TopVictims []VictimCount `json:"top_victims"`
+}
+
+// CorporationDetails is a corporation together with its kill statistics
+type CorporationDetails struct {
+	Corporation
+	Stats EntityKillStats `json:"stats"`
+}
+
+// AllianceDetails is an alliance together with its kill statistics
+type AllianceDetails struct {
+	Alliance
+	Stats EntityKillStats `json:"stats"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// States of an enrichment job
+const (
+	// EnrichmentPending jobs wait for their first attempt
+	EnrichmentPending = "pending"
+	// EnrichmentInFlight jobs are claimed by a worker
+	EnrichmentInFlight = "in_flight"
+	// EnrichmentDone jobs stored their killmail
+	EnrichmentDone = "done"
+	// EnrichmentFailed jobs failed and are retried at NextAttemptAt
+	EnrichmentFailed = "failed"
+	// EnrichmentPermanent jobs failed for good and are only retried when
+	// requeued by hand
+	EnrichmentPermanent = "permanent"
+)
+
+// EnrichmentJob queues the fetch of the full killmail from ESI for a Zkill.
+type EnrichmentJob struct {
+	KillmailID    int64      `gorm:"primaryKey;autoIncrement:false" json:"killmail_id"`
+	State         string     `gorm:"index:idx_enrichment_jobs_claim,priority:1" json:"state"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index:idx_enrichment_jobs_claim,priority:2" json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	ClaimedAt     *time.Time `json:"claimed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// EnrichmentStateCount counts the enrichment jobs in one state
+type EnrichmentStateCount struct {
+	State string `json:"state"`
+	Count int64  `json:"count"`
+}
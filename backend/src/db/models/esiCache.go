@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ESICacheEntry stores the last ESI response for a path together with its
+// ETag and Expires headers, so later syncs can send conditional requests.
+type ESICacheEntry struct {
+	Path      string    `gorm:"primaryKey" json:"path"`
+	ETag      string    `gorm:"column:etag" json:"etag"`
+	Expires   time.Time `json:"expires"`
+	Body      []byte    `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -10,6 +10,7 @@ import (
 type ESIItem struct {
 	TypeID         int     `gorm:"primaryKey" json:"type_id"`
 	GroupID        int     `gorm:"index" json:"group_id"`
+	MarketGroupID  int     `gorm:"index" json:"market_group_id"`
 	Name           string  `gorm:"type:text" json:"name"`
 	Description    string  `gorm:"type:text" json:"description"`
 	Mass           float64 `json:"mass"`
@@ -21,6 +22,9 @@ type ESIItem struct {
 	Radius         float64 `json:"radius"`
 }
 
+// ZKillboardItem is an item on a killmail victim as ESI reports it. Items
+// inside containers, e.g. cargo containers or ship hangars, are nested
+// under Items.
 type ZKillboardItem struct {
 	Flag              int              `json:"flag"`
 	ItemTypeID        int              `json:"item_type_id"`
@@ -30,17 +34,29 @@ type ZKillboardItem struct {
 	Items             []ZKillboardItem `json:"items,omitempty"`
 }
 
-type Item struct {
-	ItemTypeID        int   `json:"item_type_id"`
-	QuantityDestroyed int64 `json:"quantity_destroyed,omitempty"`
-	QuantityDropped   int64 `json:"quantity_dropped,omitempty"`
-	Flag              int   `json:"flag"`
-	Singleton         int   `json:"singleton"`
+// Destroyed returns the destroyed quantity, 0 when none was destroyed.
+func (i ZKillboardItem) Destroyed() int64 {
+	if i.QuantityDestroyed == nil {
+		return 0
+	}
+	return *i.QuantityDestroyed
+}
+
+// Dropped returns the dropped quantity, 0 when none dropped.
+func (i ZKillboardItem) Dropped() int64 {
+	if i.QuantityDropped == nil {
+		return 0
+	}
+	return *i.QuantityDropped
 }
 
-type ItemArray []Item
+// ItemArray is the item tree of a victim, stored as JSONB.
+type ItemArray []ZKillboardItem
 
 func (a ItemArray) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
 	return json.Marshal(a)
 }
 
@@ -50,8 +66,61 @@ func (a *ItemArray) Scan(value interface{}) error {
 		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
 	}
 
-	var items []Item
+	var items []ZKillboardItem
 	err := json.Unmarshal(bytes, &items)
 	*a = ItemArray(items)
 	return err
 }
+
+// Walk calls fn for every item of the tree, containers before their
+// contents. parent is the index, in walk order, of the containing item or
+// -1 for top-level items.
+func (a ItemArray) Walk(fn func(index, parent int, item ZKillboardItem)) {
+	index := 0
+	var walk func(items []ZKillboardItem, parent int)
+	walk = func(items []ZKillboardItem, parent int) {
+		for _, item := range items {
+			current := index
+			index++
+			fn(current, parent, item)
+			walk(item.Items, current)
+		}
+	}
+	walk(a, -1)
+}
+
+// KillItem is an item of a victim's item tree in the flattened kill_items
+// table. Index numbers the items of a kill in walk order, and ParentIndex
+// points at the containing item.
+type KillItem struct {
+	KillmailID        int64 `gorm:"primaryKey;autoIncrement:false" json:"killmail_id"`
+	Index             int   `gorm:"primaryKey;autoIncrement:false" json:"index"`
+	ParentIndex       *int  `json:"parent_index"`
+	Flag              int   `json:"flag"`
+	ItemTypeID        int   `gorm:"index" json:"item_type_id"`
+	QuantityDestroyed int64 `json:"quantity_destroyed"`
+	QuantityDropped   int64 `json:"quantity_dropped"`
+	Singleton         int   `json:"singleton"`
+}
+
+// FlattenItems returns the rows of the kill_items table for a victim's item
+// tree.
+func FlattenItems(killmailID int64, items ItemArray) []KillItem {
+	var rows []KillItem
+	items.Walk(func(index, parent int, item ZKillboardItem) {
+		row := KillItem{
+			KillmailID:        killmailID,
+			Index:             index,
+			Flag:              item.Flag,
+			ItemTypeID:        item.ItemTypeID,
+			QuantityDestroyed: item.Destroyed(),
+			QuantityDropped:   item.Dropped(),
+			Singleton:         item.Singleton,
+		}
+		if parent >= 0 {
+			row.ParentIndex = &parent
+		}
+		rows = append(rows, row)
+	})
+	return rows
+}
@@ -0,0 +1,44 @@
+package models
+
+import "testing"
+
+func TestFlattenItems(t *testing.T) {
+	one, two, five := int64(1), int64(2), int64(5)
+	// A cargo container in the hull holding ammunition inside a launcher,
+	// next to a fitted module
+	items := ItemArray{
+		{Flag: 5, ItemTypeID: 3465, Singleton: 1, QuantityDropped: &one, Items: []ZKillboardItem{
+			{Flag: 5, ItemTypeID: 499, QuantityDestroyed: &one, Items: []ZKillboardItem{
+				{Flag: 5, ItemTypeID: 209, QuantityDestroyed: &two, QuantityDropped: &five},
+			}},
+		}},
+		{Flag: 27, ItemTypeID: 3170, QuantityDropped: &one},
+	}
+
+	rows := FlattenItems(42, items)
+	want := []struct {
+		itemTypeID         int
+		parent             int
+		destroyed, dropped int64
+	}{
+		{3465, -1, 0, 1},
+		{499, 0, 1, 0},
+		{209, 1, 2, 5},
+		{3170, -1, 0, 1},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("flattened %d items, want %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		row := rows[i]
+		parent := -1
+		if row.ParentIndex != nil {
+			parent = *row.ParentIndex
+		}
+		if row.KillmailID != 42 || row.Index != i || row.ItemTypeID != w.itemTypeID || parent != w.parent ||
+			row.QuantityDestroyed != w.destroyed || row.QuantityDropped != w.dropped {
+			t.Errorf("row %d = %+v (parent %d), want type %d in %d, %d destroyed and %d dropped",
+				i, row, parent, w.itemTypeID, w.parent, w.destroyed, w.dropped)
+		}
+	}
+}
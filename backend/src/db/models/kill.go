@@ -5,15 +5,30 @@ import (
 	"time"
 )
 
+// Roles of the tracked character on a kill
+const (
+	RoleAttacker = "attacker"
+	RoleVictim   = "victim"
+)
+
 type Kill struct {
 	ID            uint  `gorm:"primaryKey"`
 	KillmailID    int64 `gorm:"uniqueIndex"`
 	KillmailTime  time.Time
 	SolarSystemID int
 	CharacterID   int64
-	Victim        Victim `gorm:"embedded;embeddedPrefix:victim_"`
-	Attackers     []byte `gorm:"type:jsonb"`
-	ZkillData     Zkill  `gorm:"foreignKey:KillmailID;references:KillmailID"`
+	// Role is whether CharacterID was an attacker or the victim. Kills in
+	// tracked regions without a tracked character have neither.
+	Role      string `gorm:"index"`
+	Victim    Victim `gorm:"embedded;embeddedPrefix:victim_"`
+	Attackers []byte `gorm:"type:jsonb"`
+	ZkillData Zkill  `gorm:"foreignKey:KillmailID;references:KillmailID"`
+	// Value is our own valuation from market prices, next to ZkillData's
+	Value KillValue `gorm:"embedded;embeddedPrefix:value_"`
+	// Space holds who held the solar system when the kill happened
+	Space KillSpace `gorm:"embedded;embeddedPrefix:space_"`
+	// Names holds resolved names of the IDs in the kill when requested
+	Names map[int64]string `gorm:"-" json:",omitempty"`
 }
 
 type Victim struct {
@@ -41,6 +56,7 @@ type Zkill struct {
 	ID             uint  `gorm:"primaryKey"`
 	KillmailID     int64 `gorm:"uniqueIndex"`
 	CharacterID    int64
+	Role           string
 	LocationID     int64
 	Hash           string
 	FittedValue    float64
@@ -51,7 +67,30 @@ type Zkill struct {
 	NPC            bool
 	Solo           bool
 	Awox           bool
-	Labels         []string `gorm:"type:text[]"`
+	Labels         StringArray `gorm:"type:text[]"`
+}
+
+// EntityIDs returns every character, corporation, alliance, type and solar
+// system ID referenced by the kill.
+func (k *Kill) EntityIDs() []int64 {
+	ids := []int64{
+		int64(k.SolarSystemID),
+		k.Victim.CharacterID,
+		k.Victim.CorporationID,
+		k.Victim.AllianceID,
+		int64(k.Victim.ShipTypeID),
+	}
+	attackers, _ := k.GetAttackers()
+	for _, attacker := range attackers {
+		ids = append(ids,
+			attacker.CharacterID,
+			attacker.CorporationID,
+			attacker.AllianceID,
+			int64(attacker.ShipTypeID),
+			int64(attacker.WeaponTypeID),
+		)
+	}
+	return ids
 }
 
 func (k *Kill) GetAttackers() ([]Attacker, error) {
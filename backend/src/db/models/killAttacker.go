@@ -0,0 +1,65 @@
+package models
+
+// KillAttacker is an attacker of a kill in the kill_attackers table. Index
+// is the attacker's position in the killmail. Attackers that are not
+// characters, e.g. NPCs or structures, have CharacterID 0.
+type KillAttacker struct {
+	KillmailID     int64   `gorm:"primaryKey;autoIncrement:false" json:"killmail_id"`
+	Index          int     `gorm:"primaryKey;autoIncrement:false" json:"index"`
+	CharacterID    int64   `gorm:"index" json:"character_id"`
+	CorporationID  int64   `gorm:"index" json:"corporation_id"`
+	AllianceID     int64   `gorm:"index" json:"alliance_id"`
+	ShipTypeID     int     `gorm:"index" json:"ship_type_id"`
+	WeaponTypeID   int     `json:"weapon_type_id"`
+	DamageDone     int     `json:"damage_done"`
+	FinalBlow      bool    `json:"final_blow"`
+	SecurityStatus float64 `json:"security_status"`
+}
+
+// AttackerRows returns the rows of the kill_attackers table for the kill.
+func (k *Kill) AttackerRows() ([]KillAttacker, error) {
+	if len(k.Attackers) == 0 {
+		return nil, nil
+	}
+	attackers, err := k.GetAttackers()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]KillAttacker, len(attackers))
+	for i, attacker := range attackers {
+		rows[i] = KillAttacker{
+			KillmailID:     k.KillmailID,
+			Index:          i,
+			CharacterID:    attacker.CharacterID,
+			CorporationID:  attacker.CorporationID,
+			AllianceID:     attacker.AllianceID,
+			ShipTypeID:     attacker.ShipTypeID,
+			WeaponTypeID:   attacker.WeaponTypeID,
+			DamageDone:     attacker.DamageDone,
+			FinalBlow:      attacker.FinalBlow,
+			SecurityStatus: attacker.SecurityStatus,
+		}
+	}
+	return rows, nil
+}
+
+// AttackerStats sums up what a character did as an attacker
+type AttackerStats struct {
+	CharacterID int64 `json:"character_id"`
+	// KillCount is the number of kills the character was on
+	KillCount  int `json:"kill_count"`
+	FinalBlows int `json:"final_blows"`
+	// TopDamage is the number of kills the character dealt the most damage on
+	TopDamage  int   `json:"top_damage"`
+	DamageDone int64 `json:"damage_done"`
+}
+
+// ShipUsage sums up how often a ship type was flown by attackers
+type ShipUsage struct {
+	ShipTypeID int `json:"ship_type_id"`
+	// UseCount is the number of attackers that flew the ship
+	UseCount   int   `json:"use_count"`
+	PilotCount int   `json:"pilot_count"`
+	FinalBlows int   `json:"final_blows"`
+	DamageDone int64 `json:"damage_done"`
+}
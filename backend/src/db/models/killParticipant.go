@@ -0,0 +1,13 @@
+package models
+
+// KillParticipant credits a tracked character for a kill, as the victim or
+// as one of the attackers. Unlike Kill.CharacterID, which holds the single
+// character the kill was first stored for, every tracked character on the
+// kill has a participant row.
+type KillParticipant struct {
+	KillmailID  int64  `gorm:"primaryKey;autoIncrement:false" json:"killmail_id"`
+	CharacterID int64  `gorm:"primaryKey;autoIncrement:false;index" json:"character_id"`
+	Role        string `gorm:"primaryKey" json:"role"`
+	FinalBlow   bool   `json:"final_blow"`
+	DamageDone  int    `json:"damage_done"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// NameUnresolved is the category of a negative entry: ESI did not know the
+// ID when it was last looked up, so it is not asked again until the entry
+// is stale.
+const NameUnresolved = "unresolved"
+
+// Name is the resolved name of an EVE entity as returned by ESI
+// /universe/names/. Category is one of alliance, character, constellation,
+// corporation, inventory_type, region, solar_system, station or faction, or
+// NameUnresolved for an ID that ESI could not resolve.
+type Name struct {
+	ID          int64     `gorm:"primaryKey" json:"id"`
+	Name        string    `json:"name"`
+	Category    string    `gorm:"index" json:"category"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
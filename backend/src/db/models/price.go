@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// MarketPrice is the universe-wide price of a type as reported by ESI
+// /markets/prices/.
+type MarketPrice struct {
+	TypeID        int       `gorm:"primaryKey" json:"type_id"`
+	AveragePrice  float64   `json:"average_price"`
+	AdjustedPrice float64   `json:"adjusted_price"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// KillValue is our own valuation of a kill. Destroyed includes the hull, as
+// zKillboard's DestroyedValue does. Historical is set once the kill was
+// valued at the prices of its own day; such values no longer change.
+type KillValue struct {
+	Hull       float64
+	Destroyed  float64
+	Dropped    float64
+	Total      float64
+	Historical bool
+	ComputedAt *time.Time
+}
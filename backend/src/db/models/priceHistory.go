@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PriceHistory is the daily market summary of a type in a region as reported
+// by ESI /markets/{region_id}/history/.
+type PriceHistory struct {
+	RegionID   int       `gorm:"primaryKey;autoIncrement:false" json:"region_id"`
+	TypeID     int       `gorm:"primaryKey;autoIncrement:false" json:"type_id"`
+	Date       time.Time `gorm:"primaryKey;type:date" json:"date"`
+	Average    float64   `json:"average"`
+	Highest    float64   `json:"highest"`
+	Lowest     float64   `json:"lowest"`
+	Volume     int64     `json:"volume"`
+	OrderCount int64     `json:"order_count"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// Stargate connects SystemID to DestinationSystemID through the stargate
+// DestinationStargateID on the other side.
+type Stargate struct {
+	StargateID            int    `gorm:"primaryKey" json:"stargate_id"`
+	Name                  string `json:"name"`
+	SystemID              int    `gorm:"index" json:"system_id"`
+	DestinationStargateID int    `json:"destination_stargate_id"`
+	DestinationSystemID   int    `gorm:"index" json:"destination_system_id"`
+}
+
+// RouteSystem is a system on a route or in a jump radius.
+type RouteSystem struct {
+	SystemID       int     `json:"system_id"`
+	Name           string  `json:"name"`
+	SecurityStatus float64 `json:"security_status"`
+	RegionID       int     `json:"region_id"`
+	Jumps          int     `json:"jumps"`
+}
+
+// Route is the shortest path between two systems. Systems include both ends.
+type Route struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Jumps   int           `json:"jumps"`
+	Systems []RouteSystem `json:"systems"`
+}
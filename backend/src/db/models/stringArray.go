@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// StringArray stores a []string in a Postgres text[] column. nil is stored
+// as an empty array.
+type StringArray []string
+
+func (a StringArray) Value() (driver.Value, error) {
+	quoted := make([]string, len(a))
+	for i, s := range a {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		s = strings.ReplaceAll(s, `"`, `\"`)
+		quoted[i] = `"` + s + `"`
+	}
+	return "{" + strings.Join(quoted, ",") + "}", nil
+}
+
+func (a *StringArray) Scan(value interface{}) error {
+	var literal string
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		literal = string(v)
+	case string:
+		literal = v
+	default:
+		return fmt.Errorf("cannot scan %T into StringArray", value)
+	}
+
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return fmt.Errorf("invalid array literal %q", literal)
+	}
+	literal = literal[1 : len(literal)-1]
+
+	elements := StringArray{}
+	var element strings.Builder
+	quoted, escaped, inQuotes := false, false, false
+	for _, r := range literal {
+		switch {
+		case escaped:
+			element.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case r == ',' && !inQuotes:
+			elements = append(elements, element.String())
+			element.Reset()
+			quoted = false
+		default:
+			element.WriteRune(r)
+		}
+	}
+	if element.Len() > 0 || quoted || len(elements) > 0 {
+		elements = append(elements, element.String())
+	}
+	*a = elements
+	return nil
+}
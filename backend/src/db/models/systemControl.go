@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// SystemControl records who held a solar system from StartDate until
+// EndDate: the sovereignty holder and, for faction warfare systems, the
+// owning and occupying factions. The current state has no EndDate.
+type SystemControl struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	SystemID            int        `gorm:"index" json:"system_id"`
+	SovAllianceID       int64      `json:"sov_alliance_id"`
+	SovCorporationID    int64      `json:"sov_corporation_id"`
+	SovFactionID        int64      `json:"sov_faction_id"`
+	FWOwnerFactionID    int64      `gorm:"column:fw_owner_faction_id" json:"fw_owner_faction_id"`
+	FWOccupierFactionID int64      `gorm:"column:fw_occupier_faction_id" json:"fw_occupier_faction_id"`
+	FWContested         string     `gorm:"column:fw_contested" json:"fw_contested"`
+	StartDate           time.Time  `json:"start_date"`
+	EndDate             *time.Time `json:"end_date"`
+}
+
+// SameHolder reports whether c and other describe the same holders.
+func (c SystemControl) SameHolder(other SystemControl) bool {
+	return c.SovAllianceID == other.SovAllianceID &&
+		c.SovCorporationID == other.SovCorporationID &&
+		c.SovFactionID == other.SovFactionID &&
+		c.FWOwnerFactionID == other.FWOwnerFactionID &&
+		c.FWOccupierFactionID == other.FWOccupierFactionID &&
+		c.FWContested == other.FWContested
+}
+
+// KillSpace tags a kill with the holders of its solar system at the time of
+// the kill. Tagged is set once the lookup ran.
+type KillSpace struct {
+	SovAllianceID       int64
+	SovCorporationID    int64
+	SovFactionID        int64
+	FWOccupierFactionID int64 `gorm:"column:fw_occupier_faction_id"`
+	Tagged              bool
+}
+
+// SpaceBreakdown aggregates kills by the holder of the space they happened
+// in.
+type SpaceBreakdown struct {
+	SovAllianceID       int64   `json:"sov_alliance_id"`
+	SovFactionID        int64   `json:"sov_faction_id"`
+	FWOccupierFactionID int64   `json:"fw_occupier_faction_id"`
+	KillCount           int     `json:"kill_count"`
+	TotalISK            float64 `json:"total_isk"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// ItemCategory is the top level of the type taxonomy, e.g. Ship, Module or
+// Structure.
+type ItemCategory struct {
+	CategoryID int    `gorm:"primaryKey" json:"category_id"`
+	Name       string `json:"name"`
+	Published  bool   `json:"published"`
+}
+
+// ItemGroup groups types within a category, e.g. Frigate or Titan.
+type ItemGroup struct {
+	GroupID    int    `gorm:"primaryKey" json:"group_id"`
+	CategoryID int    `gorm:"index" json:"category_id"`
+	Name       string `json:"name"`
+	Published  bool   `json:"published"`
+}
+
+// MarketGroup is a node of the market browser tree. Top level groups have no
+// ParentGroupID.
+type MarketGroup struct {
+	MarketGroupID int    `gorm:"primaryKey" json:"market_group_id"`
+	ParentGroupID int    `gorm:"index" json:"parent_group_id"`
+	Name          string `json:"name"`
+	Description   string `gorm:"type:text" json:"description"`
+}
+
+// CategoryDetails is a category together with its groups
+type CategoryDetails struct {
+	ItemCategory
+	Groups []ItemGroup `json:"groups"`
+}
+
+// GroupDetails is a group together with its types
+type GroupDetails struct {
+	ItemGroup
+	Types []ESIItem `json:"types"`
+}
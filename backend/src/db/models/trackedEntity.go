@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Types of entities whose kills are ingested as a whole
+const (
+	EntityCorporation = "corporation"
+	EntityAlliance    = "alliance"
+)
+
+// TrackedEntity is a corporation or alliance whose zKillboard kills and
+// losses are ingested. Members showing up on them are added as characters.
+type TrackedEntity struct {
+	EntityType string    `gorm:"primaryKey" json:"entity_type"`
+	EntityID   int64     `gorm:"primaryKey;autoIncrement:false" json:"entity_id"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ZKillField returns the zKillboard API modifier selecting the entity's
+// kills, e.g. corporationID.
+func (e TrackedEntity) ZKillField() string {
+	return e.EntityType + "ID"
+}
+
+// Member returns the member of the entity on kill in role, or 0 if the
+// entity has no character there.
+func (e TrackedEntity) Member(kill *Kill, role string) int64 {
+	if role == RoleVictim {
+		if e.matches(kill.Victim.CorporationID, kill.Victim.AllianceID) {
+			return kill.Victim.CharacterID
+		}
+		return 0
+	}
+
+	attackers, _ := kill.GetAttackers()
+	for _, attacker := range attackers {
+		if attacker.CharacterID != 0 && e.matches(attacker.CorporationID, attacker.AllianceID) {
+			return attacker.CharacterID
+		}
+	}
+	return 0
+}
+
+func (e TrackedEntity) matches(corporationID, allianceID int64) bool {
+	switch e.EntityType {
+	case EntityCorporation:
+		return corporationID == e.EntityID
+	case EntityAlliance:
+		return allianceID == e.EntityID
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// TrackedRegion is a region in which every kill is ingested, whether or not
+// it involves a tracked character. Kills without one are stored without a
+// character and role.
+type TrackedRegion struct {
+	RegionID  int       `gorm:"primaryKey;autoIncrement:false" json:"region_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ZKillCursor records how far a zKillboard API list, e.g.
+// kills/characterID/123, has been synced.
+type ZKillCursor struct {
+	Path string `gorm:"primaryKey" json:"path"`
+	// LastKillmailID is the newest killmail seen in the list
+	LastKillmailID int64 `json:"last_killmail_id"`
+	// LastPage is the last page stored by the backfill, which resumes after it
+	LastPage int `json:"last_page"`
+	// BackfillDone is set once the backfill reached the end of the list
+	BackfillDone bool      `json:"backfill_done"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
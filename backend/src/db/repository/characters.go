@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresCharacters struct {
+	db *gorm.DB
+}
+
+func (r *postgresCharacters) GetCharacterByID(id int64) (*models.Character, error) {
+	var character models.Character
+	result := r.db.First(&character, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &character, nil
+}
+
+func (r *postgresCharacters) GetAllCharacters() ([]models.Character, error) {
+	var characters []models.Character
+	err := r.db.Find(&characters).Error
+	return characters, err
+}
+
+func (r *postgresCharacters) UpsertCharacter(character *models.Character) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "security_status", "title", "race_id", "corporation_id", "alliance_id"}),
+		}).Create(character).Error
+		if err != nil {
+			return err
+		}
+		return syncKillParticipants(tx, "kill_attackers.character_id = ?", "kills.victim_character_id = ?", character.ID)
+	})
+}
+
+// DeleteCharacter keeps the kills themselves.
+func (r *postgresCharacters) DeleteCharacter(characterID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("character_id = ?", characterID).Delete(&models.KillParticipant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Character{}, characterID).Error
+	})
+}
+
+func (r *postgresCharacters) GetCharacterAffiliations(characterID int64) ([]models.CharacterAffiliation, error) {
+	var affiliations []models.CharacterAffiliation
+	err := r.db.Where("character_id = ?", characterID).Order("start_date").Find(&affiliations).Error
+	return affiliations, err
+}
+
+func (r *postgresCharacters) HasAffiliationHistory(characterID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.CharacterAffiliation{}).Where("character_id = ?", characterID).Count(&count).Error
+	return count > 0, err
+}
+
+// SeedAffiliationHistory also sets the current affiliation of the character.
+func (r *postgresCharacters) SeedAffiliationHistory(characterID int64, history []models.CharacterAffiliation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+		current := history[len(history)-1]
+		return updateCharacterAffiliation(tx, characterID, current.CorporationID, current.AllianceID)
+	})
+}
+
+// RecordAffiliation closes the open affiliation of a character and opens a
+// new one at time at, unless the character is still in the same corporation
+// and alliance.
+func (r *postgresCharacters) RecordAffiliation(characterID, corporationID, allianceID int64, at time.Time) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.CharacterAffiliation
+		result := tx.Where("character_id = ? AND end_date IS NULL", characterID).Limit(1).Find(&current)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected > 0 {
+			if current.CorporationID == corporationID && current.AllianceID == allianceID {
+				return nil
+			}
+			if err := tx.Model(&current).Update("end_date", at).Error; err != nil {
+				return err
+			}
+		}
+
+		changed = true
+		next := models.CharacterAffiliation{
+			CharacterID:   characterID,
+			CorporationID: corporationID,
+			AllianceID:    allianceID,
+			StartDate:     at,
+		}
+		if err := tx.Create(&next).Error; err != nil {
+			return err
+		}
+		return updateCharacterAffiliation(tx, characterID, corporationID, allianceID)
+	})
+	return changed, err
+}
+
+func updateCharacterAffiliation(tx *gorm.DB, characterID, corporationID, allianceID int64) error {
+	return tx.Model(&models.Character{}).Where("id = ?", characterID).Updates(map[string]interface{}{
+		"corporation_id": corporationID,
+		"alliance_id":    allianceID,
+	}).Error
+}
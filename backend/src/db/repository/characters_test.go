@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func TestCharacters(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	// A kill of pilotA stored before a third character is tracked
+	storeKill(t, repos, testKill(t, 1,
+		models.Attacker{CharacterID: pilotA, DamageDone: 400},
+		models.Attacker{CharacterID: 90000003, DamageDone: 600, FinalBlow: true},
+	), 1000)
+
+	if err := repos.Characters.UpsertCharacter(&models.Character{ID: 90000003, Name: "Pilot C"}); err != nil {
+		t.Fatalf("UpsertCharacter: %v", err)
+	}
+	if err := repos.Characters.UpsertCharacter(&models.Character{ID: 90000003, Name: "Pilot C", Title: "CEO"}); err != nil {
+		t.Fatalf("UpsertCharacter: %v", err)
+	}
+
+	characters, err := repos.Characters.GetAllCharacters()
+	if err != nil || len(characters) != 3 {
+		t.Fatalf("GetAllCharacters = %d characters, %v, want 3", len(characters), err)
+	}
+	character, err := repos.Characters.GetCharacterByID(90000003)
+	if err != nil || character == nil || character.Title != "CEO" {
+		t.Errorf("GetCharacterByID = %+v, %v, want the updated character", character, err)
+	}
+	if character, err := repos.Characters.GetCharacterByID(1); character != nil || err != nil {
+		t.Errorf("GetCharacterByID of an untracked character = %+v, %v, want nil", character, err)
+	}
+
+	// Tracking credits the kill they were on
+	participants, err := repos.Kills.GetKillParticipants(1)
+	if err != nil || len(participants) != 2 {
+		t.Fatalf("GetKillParticipants = %+v, %v, want both tracked attackers", participants, err)
+	}
+
+	if err := repos.Characters.DeleteCharacter(90000003); err != nil {
+		t.Fatalf("DeleteCharacter: %v", err)
+	}
+	if character, _ := repos.Characters.GetCharacterByID(90000003); character != nil {
+		t.Errorf("character %+v still tracked after deleting", character)
+	}
+	participants, err = repos.Kills.GetKillParticipants(1)
+	if err != nil || len(participants) != 1 || participants[0].CharacterID != pilotA {
+		t.Errorf("GetKillParticipants after deleting = %+v, %v, want only pilotA", participants, err)
+	}
+	if exists, _ := repos.Kills.KillExists(1); !exists {
+		t.Error("kill deleted together with the character")
+	}
+}
+
+func TestCharacterAffiliations(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	joined := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	moved := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if has, err := repos.Characters.HasAffiliationHistory(pilotA); has || err != nil {
+		t.Fatalf("HasAffiliationHistory before seeding = %v, %v, want false", has, err)
+	}
+	err := repos.Characters.SeedAffiliationHistory(pilotA, []models.CharacterAffiliation{
+		{CharacterID: pilotA, CorporationID: 1000, StartDate: joined, EndDate: &moved},
+		{CharacterID: pilotA, CorporationID: 2000, AllianceID: 3000, StartDate: moved},
+	})
+	if err != nil {
+		t.Fatalf("SeedAffiliationHistory: %v", err)
+	}
+	if has, err := repos.Characters.HasAffiliationHistory(pilotA); !has || err != nil {
+		t.Fatalf("HasAffiliationHistory after seeding = %v, %v, want true", has, err)
+	}
+	if character, _ := repos.Characters.GetCharacterByID(pilotA); character.CorporationID != 2000 || character.AllianceID != 3000 {
+		t.Errorf("seeded character in %d/%d, want the current 2000/3000", character.CorporationID, character.AllianceID)
+	}
+
+	// The same affiliation is not recorded twice
+	changed, err := repos.Characters.RecordAffiliation(pilotA, 2000, 3000, moved.Add(time.Hour))
+	if err != nil || changed {
+		t.Errorf("RecordAffiliation of the same corporation = %v, %v, want unchanged", changed, err)
+	}
+	left := moved.AddDate(0, 1, 0)
+	changed, err = repos.Characters.RecordAffiliation(pilotA, 4000, 0, left)
+	if err != nil || !changed {
+		t.Errorf("RecordAffiliation of a new corporation = %v, %v, want changed", changed, err)
+	}
+
+	affiliations, err := repos.Characters.GetCharacterAffiliations(pilotA)
+	if err != nil || len(affiliations) != 3 {
+		t.Fatalf("GetCharacterAffiliations = %+v, %v, want 3", affiliations, err)
+	}
+	if ended := affiliations[1].EndDate; ended == nil || !ended.Equal(left) {
+		t.Errorf("previous affiliation ends at %v, want %v", ended, left)
+	}
+	if current := affiliations[2]; current.CorporationID != 4000 || current.EndDate != nil {
+		t.Errorf("current affiliation %+v, want the open one in 4000", current)
+	}
+	if character, _ := repos.Characters.GetCharacterByID(pilotA); character.CorporationID != 4000 || character.AllianceID != 0 {
+		t.Errorf("character in %d/%d, want 4000 without alliance", character.CorporationID, character.AllianceID)
+	}
+}
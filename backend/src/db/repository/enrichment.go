@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresEnrichment struct {
+	db *gorm.DB
+}
+
+func (r *postgresEnrichment) EnqueueEnrichment(killmailIDs []int64) error {
+	if len(killmailIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	jobs := make([]models.EnrichmentJob, len(killmailIDs))
+	for i, id := range killmailIDs {
+		jobs[i] = models.EnrichmentJob{KillmailID: id, State: models.EnrichmentPending, NextAttemptAt: now}
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&jobs).Error
+}
+
+func (r *postgresEnrichment) EnqueueUnenrichedZKills() (int64, error) {
+	now := time.Now()
+	result := r.db.Exec(`
+		INSERT INTO enrichment_jobs (killmail_id, state, attempts, next_attempt_at, last_error, created_at, updated_at)
+		SELECT zkills.killmail_id, ?, 0, ?, '', ?, ? FROM zkills
+		WHERE NOT EXISTS (SELECT 1 FROM kills WHERE kills.killmail_id = zkills.killmail_id)
+		ON CONFLICT (killmail_id) DO NOTHING`, models.EnrichmentPending, now, now, now)
+	return result.RowsAffected, result.Error
+}
+
+func (r *postgresEnrichment) EnqueueKillsWithoutItems() (int64, error) {
+	now := time.Now()
+	result := r.db.Exec(`
+		INSERT INTO enrichment_jobs (killmail_id, state, attempts, next_attempt_at, last_error, created_at, updated_at)
+		SELECT kills.killmail_id, ?, 0, ?, '', ?, ? FROM kills
+		WHERE EXISTS (SELECT 1 FROM zkills WHERE zkills.killmail_id = kills.killmail_id)
+		AND NOT EXISTS (SELECT 1 FROM kill_items WHERE kill_items.killmail_id = kills.killmail_id)
+		AND (kills.victim_items IS NULL OR CAST(kills.victim_items AS TEXT) <> '[]')
+		ON CONFLICT (killmail_id) DO UPDATE
+		SET state = EXCLUDED.state, attempts = 0, next_attempt_at = EXCLUDED.next_attempt_at, last_error = '', updated_at = EXCLUDED.updated_at
+		WHERE enrichment_jobs.state = ?`, models.EnrichmentPending, now, now, now, models.EnrichmentDone)
+	return result.RowsAffected, result.Error
+}
+
+// ClaimEnrichmentJobs skips rows locked by another worker, so concurrent
+// workers never claim the same job.
+func (r *postgresEnrichment) ClaimEnrichmentJobs(limit int) ([]models.EnrichmentJob, error) {
+	return r.claim(limit, "FOR UPDATE SKIP LOCKED")
+}
+
+// claim claims the jobs, locking the selected rows with lock.
+func (r *postgresEnrichment) claim(limit int, lock string) ([]models.EnrichmentJob, error) {
+	now := time.Now()
+	var jobs []models.EnrichmentJob
+	err := r.db.Raw(`
+		UPDATE enrichment_jobs
+		SET state = ?, attempts = attempts + 1, claimed_at = ?, updated_at = ?
+		WHERE killmail_id IN (
+			SELECT killmail_id FROM enrichment_jobs
+			WHERE state IN (?, ?) AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			`+lock+`
+		)
+		RETURNING *`,
+		models.EnrichmentInFlight, now, now, models.EnrichmentPending, models.EnrichmentFailed, now, limit).
+		Scan(&jobs).Error
+	return jobs, err
+}
+
+func (r *postgresEnrichment) ReleaseStaleEnrichmentJobs(claimedBefore time.Time) (int64, error) {
+	result := r.db.Model(&models.EnrichmentJob{}).
+		Where("state = ? AND claimed_at < ?", models.EnrichmentInFlight, claimedBefore).
+		Updates(map[string]interface{}{
+			"state":           models.EnrichmentFailed,
+			"next_attempt_at": time.Now(),
+			"last_error":      "claim expired",
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *postgresEnrichment) MarkEnrichmentDone(killmailID int64) error {
+	return r.db.Model(&models.EnrichmentJob{}).Where("killmail_id = ?", killmailID).
+		Updates(map[string]interface{}{"state": models.EnrichmentDone, "last_error": ""}).Error
+}
+
+func (r *postgresEnrichment) MarkEnrichmentFailed(killmailID int64, state string, nextAttempt time.Time, lastError string) error {
+	return r.db.Model(&models.EnrichmentJob{}).Where("killmail_id = ?", killmailID).
+		Updates(map[string]interface{}{"state": state, "next_attempt_at": nextAttempt, "last_error": lastError}).Error
+}
+
+func (r *postgresEnrichment) GetEnrichmentJobs(state string, page, pageSize int) ([]models.EnrichmentJob, int64, error) {
+	query := r.db.Model(&models.EnrichmentJob{})
+	if state != "" {
+		query = query.Where("state = ?", state)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []models.EnrichmentJob
+	err := query.Order("updated_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&jobs).Error
+	return jobs, total, err
+}
+
+func (r *postgresEnrichment) GetEnrichmentStateCounts() ([]models.EnrichmentStateCount, error) {
+	var counts []models.EnrichmentStateCount
+	err := r.db.Model(&models.EnrichmentJob{}).
+		Select("state, COUNT(*) AS count").
+		Group("state").
+		Order("state").
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *postgresEnrichment) RequeueEnrichmentJobs(killmailIDs []int64, state string) (int64, error) {
+	query := r.db.Model(&models.EnrichmentJob{}).
+		Where("state IN ?", []string{models.EnrichmentFailed, models.EnrichmentPermanent})
+	if len(killmailIDs) > 0 {
+		query = query.Where("killmail_id IN ?", killmailIDs)
+	}
+	if state != "" {
+		query = query.Where("state = ?", state)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"state":           models.EnrichmentPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"last_error":      "",
+	})
+	return result.RowsAffected, result.Error
+}
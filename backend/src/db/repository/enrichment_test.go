@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db"
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func getEnrichmentJob(t *testing.T, killmailID int64) models.EnrichmentJob {
+	t.Helper()
+	var job models.EnrichmentJob
+	if err := db.DB.First(&job, "killmail_id = ?", killmailID).Error; err != nil {
+		t.Fatalf("loading job %d: %v", killmailID, err)
+	}
+	return job
+}
+
+func TestClaimEnrichmentJobs(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	if err := repos.Enrichment.EnqueueEnrichment([]int64{1, 2, 3}); err != nil {
+		t.Fatalf("EnqueueEnrichment: %v", err)
+	}
+
+	claimed, err := repos.Enrichment.ClaimEnrichmentJobs(2)
+	if err != nil {
+		t.Fatalf("ClaimEnrichmentJobs: %v", err)
+	}
+	if len(claimed) != 2 {
+		t.Fatalf("claimed %d jobs, want the limit of 2", len(claimed))
+	}
+	for _, job := range claimed {
+		if job.State != models.EnrichmentInFlight || job.Attempts != 1 || job.ClaimedAt == nil {
+			t.Errorf("claimed job %+v, want in flight on its first attempt", job)
+		}
+	}
+
+	// Jobs in flight are not claimed twice
+	rest, err := repos.Enrichment.ClaimEnrichmentJobs(10)
+	if err != nil {
+		t.Fatalf("ClaimEnrichmentJobs: %v", err)
+	}
+	if len(rest) != 1 {
+		t.Fatalf("claimed %d more jobs, want the one left pending", len(rest))
+	}
+	for _, job := range claimed {
+		if job.KillmailID == rest[0].KillmailID {
+			t.Errorf("job %d claimed twice", job.KillmailID)
+		}
+	}
+
+	// Failed jobs are claimed again once due, permanent and done ones never
+	if err := repos.Enrichment.MarkEnrichmentFailed(claimed[0].KillmailID, models.EnrichmentFailed, time.Now().Add(-time.Minute), "timeout"); err != nil {
+		t.Fatalf("MarkEnrichmentFailed: %v", err)
+	}
+	if err := repos.Enrichment.MarkEnrichmentFailed(claimed[1].KillmailID, models.EnrichmentFailed, time.Now().Add(time.Hour), "timeout"); err != nil {
+		t.Fatalf("MarkEnrichmentFailed: %v", err)
+	}
+	if err := repos.Enrichment.MarkEnrichmentFailed(rest[0].KillmailID, models.EnrichmentPermanent, time.Now().Add(-time.Minute), "not found"); err != nil {
+		t.Fatalf("MarkEnrichmentFailed: %v", err)
+	}
+
+	retried, err := repos.Enrichment.ClaimEnrichmentJobs(10)
+	if err != nil {
+		t.Fatalf("ClaimEnrichmentJobs: %v", err)
+	}
+	if len(retried) != 1 || retried[0].KillmailID != claimed[0].KillmailID {
+		t.Fatalf("claimed %+v, want only the due failed job %d", retried, claimed[0].KillmailID)
+	}
+	if retried[0].Attempts != 2 {
+		t.Errorf("retried job at attempt %d, want 2", retried[0].Attempts)
+	}
+
+	if job := getEnrichmentJob(t, claimed[1].KillmailID); job.State != models.EnrichmentFailed || job.Attempts != 1 {
+		t.Errorf("job retried later is %+v, want failed after one attempt", job)
+	}
+	if job := getEnrichmentJob(t, rest[0].KillmailID); job.State != models.EnrichmentPermanent {
+		t.Errorf("permanently failed job is %q, want %q", job.State, models.EnrichmentPermanent)
+	}
+}
+
+func TestEnqueueEnrichment(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	// 1 lacks its kill, 2 was stored before items were kept, 3 has none and
+	// 4 has its item rows
+	one := int64(1)
+	withItems := testKill(t, 4)
+	withItems.Victim.Items = models.ItemArray{{ItemTypeID: 34, QuantityDropped: &one}}
+	for _, kill := range []*models.Kill{testKill(t, 2), testKill(t, 3), withItems} {
+		storeKill(t, repos, kill, 100)
+	}
+	if err := repos.ZKills.UpsertZKills([]models.Zkill{{KillmailID: 1}}); err != nil {
+		t.Fatalf("UpsertZKills: %v", err)
+	}
+	if err := db.DB.Exec("UPDATE kills SET victim_items = 'null' WHERE killmail_id = 2").Error; err != nil {
+		t.Fatalf("clearing items: %v", err)
+	}
+
+	added, err := repos.Enrichment.EnqueueUnenrichedZKills()
+	if err != nil || added != 1 {
+		t.Fatalf("EnqueueUnenrichedZKills = %d, %v, want the Zkill without a kill", added, err)
+	}
+	if added, _ := repos.Enrichment.EnqueueUnenrichedZKills(); added != 0 {
+		t.Errorf("EnqueueUnenrichedZKills queued %d jobs again, want 0", added)
+	}
+
+	// A done job is reset
+	if err := repos.Enrichment.EnqueueEnrichment([]int64{2}); err != nil {
+		t.Fatalf("EnqueueEnrichment: %v", err)
+	}
+	if err := repos.Enrichment.MarkEnrichmentDone(2); err != nil {
+		t.Fatalf("MarkEnrichmentDone: %v", err)
+	}
+	queued, err := repos.Enrichment.EnqueueKillsWithoutItems()
+	if err != nil || queued != 1 {
+		t.Fatalf("EnqueueKillsWithoutItems = %d, %v, want the kill stored before items were kept", queued, err)
+	}
+	if job := getEnrichmentJob(t, 2); job.State != models.EnrichmentPending || job.Attempts != 0 {
+		t.Errorf("job of the kill without items is %+v, want pending again", job)
+	}
+	var jobs int64
+	db.DB.Model(&models.EnrichmentJob{}).Count(&jobs)
+	if jobs != 2 {
+		t.Errorf("%d jobs queued, want those of 1 and 2", jobs)
+	}
+}
+
+func TestEnrichmentJobAdministration(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	if err := repos.Enrichment.EnqueueEnrichment([]int64{1, 2, 3, 4}); err != nil {
+		t.Fatalf("EnqueueEnrichment: %v", err)
+	}
+	claimed, err := repos.Enrichment.ClaimEnrichmentJobs(4)
+	if err != nil || len(claimed) != 4 {
+		t.Fatalf("ClaimEnrichmentJobs = %d jobs, %v, want 4", len(claimed), err)
+	}
+	if err := repos.Enrichment.MarkEnrichmentDone(1); err != nil {
+		t.Fatalf("MarkEnrichmentDone: %v", err)
+	}
+	if err := repos.Enrichment.MarkEnrichmentFailed(2, models.EnrichmentFailed, time.Now().Add(time.Hour), "timeout"); err != nil {
+		t.Fatalf("MarkEnrichmentFailed: %v", err)
+	}
+	if err := repos.Enrichment.MarkEnrichmentFailed(3, models.EnrichmentPermanent, time.Now(), "not found"); err != nil {
+		t.Fatalf("MarkEnrichmentFailed: %v", err)
+	}
+
+	// 4 stays in flight until its claim expires
+	if released, err := repos.Enrichment.ReleaseStaleEnrichmentJobs(time.Now().Add(-time.Hour)); released != 0 || err != nil {
+		t.Errorf("ReleaseStaleEnrichmentJobs of fresh claims = %d, %v, want 0", released, err)
+	}
+	if released, err := repos.Enrichment.ReleaseStaleEnrichmentJobs(time.Now().Add(time.Second)); released != 1 || err != nil {
+		t.Errorf("ReleaseStaleEnrichmentJobs = %d, %v, want the job in flight", released, err)
+	}
+	if job := getEnrichmentJob(t, 4); job.State != models.EnrichmentFailed || job.LastError != "claim expired" {
+		t.Errorf("released job is %+v, want failed with an expired claim", job)
+	}
+
+	counts, err := repos.Enrichment.GetEnrichmentStateCounts()
+	want := []models.EnrichmentStateCount{
+		{State: models.EnrichmentDone, Count: 1},
+		{State: models.EnrichmentFailed, Count: 2},
+		{State: models.EnrichmentPermanent, Count: 1},
+	}
+	if err != nil || !reflect.DeepEqual(counts, want) {
+		t.Errorf("GetEnrichmentStateCounts = %+v, %v, want %+v", counts, err, want)
+	}
+
+	failed, total, err := repos.Enrichment.GetEnrichmentJobs(models.EnrichmentFailed, 1, 1)
+	if err != nil || total != 2 || len(failed) != 1 {
+		t.Errorf("GetEnrichmentJobs(failed) = %d jobs of %d, %v, want a page of 1 of 2", len(failed), total, err)
+	}
+	if all, total, err := repos.Enrichment.GetEnrichmentJobs("", 2, 3); err != nil || total != 4 || len(all) != 1 {
+		t.Errorf("second page of all jobs = %d jobs of %d, %v, want 1 of 4", len(all), total, err)
+	}
+
+	// Only failed and permanent jobs are requeued
+	if requeued, err := repos.Enrichment.RequeueEnrichmentJobs([]int64{1, 2}, ""); requeued != 1 || err != nil {
+		t.Errorf("RequeueEnrichmentJobs of 1 and 2 = %d, %v, want only the failed 2", requeued, err)
+	}
+	if job := getEnrichmentJob(t, 2); job.State != models.EnrichmentPending || job.Attempts != 0 || job.LastError != "" {
+		t.Errorf("requeued job is %+v, want pending without attempts", job)
+	}
+	if requeued, err := repos.Enrichment.RequeueEnrichmentJobs(nil, models.EnrichmentPermanent); requeued != 1 || err != nil {
+		t.Errorf("RequeueEnrichmentJobs(permanent) = %d, %v, want 3", requeued, err)
+	}
+	if job := getEnrichmentJob(t, 4); job.State != models.EnrichmentFailed {
+		t.Errorf("failed job 4 is %q after requeueing permanent jobs, want it left alone", job.State)
+	}
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresEntities struct {
+	db *gorm.DB
+}
+
+func (r *postgresEntities) GetCorporationByID(id int64) (*models.Corporation, error) {
+	var corporation models.Corporation
+	err := r.db.First(&corporation, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &corporation, nil
+}
+
+func (r *postgresEntities) GetAllianceByID(id int64) (*models.Alliance, error) {
+	var alliance models.Alliance
+	err := r.db.First(&alliance, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &alliance, nil
+}
+
+// UpsertCorporations stores the corporations in batches, as region and
+// alliance member syncs can exceed the bind parameter limit of one insert.
+func (r *postgresEntities) UpsertCorporations(corporations []models.Corporation) error {
+	if len(corporations) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "ticker", "member_count", "alliance_id", "ceo_id", "updated_at"}),
+	}).CreateInBatches(corporations, 1000).Error
+}
+
+func (r *postgresEntities) UpsertAlliance(alliance *models.Alliance) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "ticker", "executor_corporation_id", "corporation_count", "member_count", "updated_at"}),
+	}).Create(alliance).Error
+}
+
+func (r *postgresEntities) SumCorporationMembers(ids []int64) (int, error) {
+	var total int
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	err := r.db.Model(&models.Corporation{}).
+		Select("COALESCE(SUM(member_count), 0)").
+		Where("id IN ?", ids).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *postgresEntities) GetReferencedEntityIDs(field string) ([]int64, error) {
+	var ids []int64
+	err := r.db.Raw(`
+		SELECT victim_` + field + ` AS id FROM kills WHERE victim_` + field + ` <> 0
+		UNION
+		SELECT ` + field + ` AS id FROM kill_attackers WHERE ` + field + ` <> 0
+	`).Scan(&ids).Error
+	return ids, err
+}
+
+func (r *postgresEntities) GetFreshEntityIDs(table string, since time.Time) (map[int64]bool, error) {
+	var ids []int64
+	err := r.db.Table(table).Where("updated_at > ?", since).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		fresh[id] = true
+	}
+	return fresh, nil
+}
+
+func (r *postgresEntities) GetTrackedEntities() ([]models.TrackedEntity, error) {
+	var entities []models.TrackedEntity
+	err := r.db.Order("entity_type, entity_id").Find(&entities).Error
+	return entities, err
+}
+
+func (r *postgresEntities) GetTrackedEntity(entityType string, entityID int64) (*models.TrackedEntity, error) {
+	var entity models.TrackedEntity
+	err := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (r *postgresEntities) UpsertTrackedEntity(entity *models.TrackedEntity) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(entity).Error
+}
+
+func (r *postgresEntities) DeleteTrackedEntity(entityType string, entityID int64) error {
+	return r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Delete(&models.TrackedEntity{}).Error
+}
+
+func (r *postgresEntities) GetNamesByIDs(ids []int64) ([]models.Name, error) {
+	var names []models.Name
+	if len(ids) == 0 {
+		return names, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&names).Error
+	return names, err
+}
+
+func (r *postgresEntities) UpsertNames(names []models.Name) error {
+	if len(names) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "category", "refreshed_at"}),
+	}).Create(&names).Error
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func TestCorporationsAndAlliances(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	stale := time.Now().Add(-48 * time.Hour)
+	err := repos.Entities.UpsertCorporations([]models.Corporation{
+		{ID: 1000, Name: "Old Name", MemberCount: 10, AllianceID: 3000, UpdatedAt: stale},
+		{ID: 2000, Name: "Second", MemberCount: 5, AllianceID: 3000, UpdatedAt: stale},
+	})
+	if err != nil {
+		t.Fatalf("UpsertCorporations: %v", err)
+	}
+	if err := repos.Entities.UpsertCorporations([]models.Corporation{{ID: 1000, Name: "First", MemberCount: 12, AllianceID: 3000}}); err != nil {
+		t.Fatalf("UpsertCorporations: %v", err)
+	}
+	if err := repos.Entities.UpsertCorporations(nil); err != nil {
+		t.Fatalf("UpsertCorporations(nil): %v", err)
+	}
+	err = repos.Entities.UpsertAlliance(&models.Alliance{ID: 3000, Name: "Alliance", ExecutorCorporationID: 1000, CorporationCount: 2})
+	if err != nil {
+		t.Fatalf("UpsertAlliance: %v", err)
+	}
+
+	corporation, err := repos.Entities.GetCorporationByID(1000)
+	if err != nil || corporation == nil || corporation.Name != "First" || corporation.MemberCount != 12 {
+		t.Errorf("GetCorporationByID = %+v, %v, want the renamed corporation", corporation, err)
+	}
+	if corporation, err := repos.Entities.GetCorporationByID(1); corporation != nil || err != nil {
+		t.Errorf("GetCorporationByID of an unknown corporation = %+v, %v, want nil", corporation, err)
+	}
+	alliance, err := repos.Entities.GetAllianceByID(3000)
+	if err != nil || alliance == nil || alliance.ExecutorCorporationID != 1000 {
+		t.Errorf("GetAllianceByID = %+v, %v, want the alliance", alliance, err)
+	}
+	if alliance, err := repos.Entities.GetAllianceByID(1); alliance != nil || err != nil {
+		t.Errorf("GetAllianceByID of an unknown alliance = %+v, %v, want nil", alliance, err)
+	}
+
+	if total, err := repos.Entities.SumCorporationMembers([]int64{1000, 2000, 9999}); total != 17 || err != nil {
+		t.Errorf("SumCorporationMembers = %d, %v, want 17", total, err)
+	}
+	if total, err := repos.Entities.SumCorporationMembers(nil); total != 0 || err != nil {
+		t.Errorf("SumCorporationMembers(nil) = %d, %v, want 0", total, err)
+	}
+
+	fresh, err := repos.Entities.GetFreshEntityIDs("corporations", time.Now().Add(-time.Hour))
+	if err != nil || !reflect.DeepEqual(fresh, map[int64]bool{1000: true}) {
+		t.Errorf("GetFreshEntityIDs = %v, %v, want only the corporation updated just now", fresh, err)
+	}
+}
+
+func TestGetReferencedEntityIDs(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	kill := testKill(t, 1,
+		models.Attacker{CharacterID: pilotA, CorporationID: 1000, AllianceID: 3000},
+		models.Attacker{CorporationID: 1000},
+	)
+	kill.Victim.AllianceID = 4000
+	storeKill(t, repos, kill, 100)
+
+	tests := []struct {
+		field string
+		want  []int64
+	}{
+		{"corporation_id", []int64{200, 1000}},
+		{"alliance_id", []int64{3000, 4000}},
+	}
+	for _, tt := range tests {
+		ids, err := repos.Entities.GetReferencedEntityIDs(tt.field)
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		if err != nil || !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("GetReferencedEntityIDs(%s) = %v, %v, want %v", tt.field, ids, err, tt.want)
+		}
+	}
+}
+
+func TestTrackedEntities(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	entities := []models.TrackedEntity{
+		{EntityType: models.EntityCorporation, EntityID: 1000, Name: "First"},
+		{EntityType: models.EntityAlliance, EntityID: 3000, Name: "Alliance"},
+		{EntityType: models.EntityCorporation, EntityID: 3000, Name: "Same ID"},
+	}
+	for _, entity := range entities {
+		if err := repos.Entities.UpsertTrackedEntity(&entity); err != nil {
+			t.Fatalf("UpsertTrackedEntity: %v", err)
+		}
+	}
+	renamed := models.TrackedEntity{EntityType: models.EntityCorporation, EntityID: 1000, Name: "Renamed"}
+	if err := repos.Entities.UpsertTrackedEntity(&renamed); err != nil {
+		t.Fatalf("UpsertTrackedEntity: %v", err)
+	}
+
+	tracked, err := repos.Entities.GetTrackedEntities()
+	if err != nil || len(tracked) != 3 || tracked[0].EntityType != models.EntityAlliance || tracked[2].EntityID != 3000 {
+		t.Fatalf("GetTrackedEntities = %+v, %v, want all 3 ordered by type and ID", tracked, err)
+	}
+	entity, err := repos.Entities.GetTrackedEntity(models.EntityCorporation, 1000)
+	if err != nil || entity == nil || entity.Name != "Renamed" {
+		t.Errorf("GetTrackedEntity = %+v, %v, want the renamed corporation", entity, err)
+	}
+
+	if err := repos.Entities.DeleteTrackedEntity(models.EntityCorporation, 3000); err != nil {
+		t.Fatalf("DeleteTrackedEntity: %v", err)
+	}
+	if entity, err := repos.Entities.GetTrackedEntity(models.EntityCorporation, 3000); entity != nil || err != nil {
+		t.Errorf("GetTrackedEntity after deleting = %+v, %v, want nil", entity, err)
+	}
+	if entity, _ := repos.Entities.GetTrackedEntity(models.EntityAlliance, 3000); entity == nil {
+		t.Error("alliance with the same ID deleted together with the corporation")
+	}
+}
+
+func TestNames(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	refreshed := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	err := repos.Entities.UpsertNames([]models.Name{
+		{ID: pilotA, Name: "Pilot A", Category: "character", RefreshedAt: refreshed},
+		{ID: 42, Category: models.NameUnresolved, RefreshedAt: refreshed},
+	})
+	if err != nil {
+		t.Fatalf("UpsertNames: %v", err)
+	}
+	if err := repos.Entities.UpsertNames([]models.Name{{ID: 42, Name: "Resolved", Category: "corporation", RefreshedAt: refreshed}}); err != nil {
+		t.Fatalf("UpsertNames: %v", err)
+	}
+	if err := repos.Entities.UpsertNames(nil); err != nil {
+		t.Fatalf("UpsertNames(nil): %v", err)
+	}
+
+	names, err := repos.Entities.GetNamesByIDs([]int64{42, pilotA, 7})
+	if err != nil || len(names) != 2 {
+		t.Fatalf("GetNamesByIDs = %+v, %v, want the 2 stored names", names, err)
+	}
+	for _, name := range names {
+		if name.ID == 42 && (name.Name != "Resolved" || name.Category != "corporation") {
+			t.Errorf("name %+v, want the resolved corporation", name)
+		}
+	}
+	if names, err := repos.Entities.GetNamesByIDs(nil); len(names) != 0 || err != nil {
+		t.Errorf("GetNamesByIDs(nil) = %+v, %v, want none", names, err)
+	}
+}
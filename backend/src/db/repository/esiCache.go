@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresESICache struct {
+	db *gorm.DB
+}
+
+func (r *postgresESICache) Get(path string) (*models.ESICacheEntry, error) {
+	var entry models.ESICacheEntry
+	err := r.db.Where("path = ?", path).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *postgresESICache) Put(entry *models.ESICacheEntry) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"etag", "expires", "body", "updated_at"}),
+	}).Create(entry).Error
+}
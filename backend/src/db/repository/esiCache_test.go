@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func TestESICache(t *testing.T) {
+	repos := setupTestRepositories(t)
+	const path = "/universe/regions/10000048/"
+
+	if entry, err := repos.ESICache.Get(path); entry != nil || err != nil {
+		t.Fatalf("Get before Put = %+v, %v, want nil", entry, err)
+	}
+
+	expires := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := repos.ESICache.Put(&models.ESICacheEntry{Path: path, ETag: `"a"`, Expires: expires, Body: []byte(`{"name":"Placid"}`)}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := repos.ESICache.Put(&models.ESICacheEntry{Path: path, ETag: `"b"`, Expires: expires.Add(time.Hour), Body: []byte(`{"name":"Placid!"}`)}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	entry, err := repos.ESICache.Get(path)
+	if err != nil || entry == nil {
+		t.Fatalf("Get = %+v, %v", entry, err)
+	}
+	if entry.ETag != `"b"` || !entry.Expires.Equal(expires.Add(time.Hour)) || string(entry.Body) != `{"name":"Placid!"}` {
+		t.Errorf("Get = %+v, want the second response", entry)
+	}
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresItems struct {
+	db *gorm.DB
+}
+
+func (r *postgresItems) GetAllESIItems() ([]models.ESIItem, error) {
+	var items []models.ESIItem
+	err := r.db.Find(&items).Error
+	return items, err
+}
+
+func (r *postgresItems) GetESIItemByTypeID(typeID int) (*models.ESIItem, error) {
+	var item models.ESIItem
+	err := r.db.First(&item, typeID).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &item, err
+}
+
+func (r *postgresItems) GetItemsByGroupID(groupID int) ([]models.ESIItem, error) {
+	var items []models.ESIItem
+	err := r.db.Where("group_id = ?", groupID).Order("type_id").Find(&items).Error
+	return items, err
+}
+
+func (r *postgresItems) UpsertESIItem(item *models.ESIItem) error {
+	return r.db.Save(item).Error
+}
+
+func (r *postgresItems) BatchUpsertESIItems(items []*models.ESIItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "type_id"}},
+		UpdateAll: true,
+	}).CreateInBatches(items, 1000).Error
+}
+
+func (r *postgresItems) GetAllCategories() ([]models.ItemCategory, error) {
+	var categories []models.ItemCategory
+	err := r.db.Order("category_id").Find(&categories).Error
+	return categories, err
+}
+
+func (r *postgresItems) GetCategoryByID(categoryID int) (*models.ItemCategory, error) {
+	var category models.ItemCategory
+	err := r.db.First(&category, categoryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &category, err
+}
+
+func (r *postgresItems) GetGroups(categoryID int) ([]models.ItemGroup, error) {
+	query := r.db.Order("group_id")
+	if categoryID != 0 {
+		query = query.Where("category_id = ?", categoryID)
+	}
+
+	var groups []models.ItemGroup
+	err := query.Find(&groups).Error
+	return groups, err
+}
+
+func (r *postgresItems) GetGroupByID(groupID int) (*models.ItemGroup, error) {
+	var group models.ItemGroup
+	err := r.db.First(&group, groupID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &group, err
+}
+
+func (r *postgresItems) GetAllMarketGroups() ([]models.MarketGroup, error) {
+	var marketGroups []models.MarketGroup
+	err := r.db.Order("market_group_id").Find(&marketGroups).Error
+	return marketGroups, err
+}
+
+func (r *postgresItems) BatchUpsertCategories(categories []*models.ItemCategory) error {
+	if len(categories) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}},
+		UpdateAll: true,
+	}).CreateInBatches(categories, 1000).Error
+}
+
+func (r *postgresItems) BatchUpsertGroups(groups []*models.ItemGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group_id"}},
+		UpdateAll: true,
+	}).CreateInBatches(groups, 1000).Error
+}
+
+func (r *postgresItems) BatchUpsertMarketGroups(marketGroups []*models.MarketGroup) error {
+	if len(marketGroups) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "market_group_id"}},
+		UpdateAll: true,
+	}).CreateInBatches(marketGroups, 1000).Error
+}
+
+func (r *postgresItems) GetStoredIDs(table, column string) (map[int]bool, error) {
+	return storedIDs(r.db, table, column)
+}
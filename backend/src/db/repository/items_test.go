@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func TestItems(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	err := repos.Items.BatchUpsertESIItems([]*models.ESIItem{
+		{TypeID: 587, GroupID: 25, MarketGroupID: 64, Name: "Rifter", Published: true},
+		{TypeID: 603, GroupID: 25, MarketGroupID: 64, Name: "Merlin", Published: true},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertESIItems: %v", err)
+	}
+	if err := repos.Items.UpsertESIItem(&models.ESIItem{TypeID: 34, GroupID: 18, Name: "Tritanium"}); err != nil {
+		t.Fatalf("UpsertESIItem: %v", err)
+	}
+	if err := repos.Items.UpsertESIItem(&models.ESIItem{TypeID: 34, GroupID: 18, Name: "Tritanium", MarketGroupID: 1857}); err != nil {
+		t.Fatalf("UpsertESIItem: %v", err)
+	}
+
+	items, err := repos.Items.GetAllESIItems()
+	if err != nil || len(items) != 3 {
+		t.Fatalf("GetAllESIItems = %d types, %v, want 3", len(items), err)
+	}
+	item, err := repos.Items.GetESIItemByTypeID(34)
+	if err != nil || item == nil || item.MarketGroupID != 1857 {
+		t.Errorf("GetESIItemByTypeID = %+v, %v, want Tritanium updated by the second upsert", item, err)
+	}
+	if item, err := repos.Items.GetESIItemByTypeID(1); item != nil || err != nil {
+		t.Errorf("GetESIItemByTypeID of an unknown type = %+v, %v, want nil", item, err)
+	}
+	inGroup, err := repos.Items.GetItemsByGroupID(25)
+	if err != nil || len(inGroup) != 2 || inGroup[0].TypeID != 587 || inGroup[1].TypeID != 603 {
+		t.Errorf("GetItemsByGroupID = %+v, %v, want the frigates ordered by ID", inGroup, err)
+	}
+
+	stored, err := repos.Items.GetStoredIDs("esi_items", "type_id")
+	if err != nil || len(stored) != 3 || !stored[587] {
+		t.Errorf("GetStoredIDs = %v, %v, want the 3 types", stored, err)
+	}
+}
+
+func TestItemTaxonomy(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	err := repos.Items.BatchUpsertCategories([]*models.ItemCategory{
+		{CategoryID: 6, Name: "Ship", Published: true},
+		{CategoryID: 4, Name: "Material", Published: true},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertCategories: %v", err)
+	}
+	err = repos.Items.BatchUpsertGroups([]*models.ItemGroup{
+		{GroupID: 25, CategoryID: 6, Name: "Frigate", Published: true},
+		{GroupID: 26, CategoryID: 6, Name: "Cruiser", Published: true},
+		{GroupID: 18, CategoryID: 4, Name: "Mineral", Published: true},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertGroups: %v", err)
+	}
+	err = repos.Items.BatchUpsertMarketGroups([]*models.MarketGroup{
+		{MarketGroupID: 64, ParentGroupID: 4, Name: "Minmatar"},
+		{MarketGroupID: 4, Name: "Ships"},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertMarketGroups: %v", err)
+	}
+	// Upserting again replaces the stored rows
+	if err := repos.Items.BatchUpsertGroups([]*models.ItemGroup{{GroupID: 26, CategoryID: 6, Name: "Cruisers"}}); err != nil {
+		t.Fatalf("BatchUpsertGroups: %v", err)
+	}
+
+	categories, err := repos.Items.GetAllCategories()
+	if err != nil || len(categories) != 2 || categories[0].CategoryID != 4 {
+		t.Errorf("GetAllCategories = %+v, %v, want both ordered by ID", categories, err)
+	}
+	category, err := repos.Items.GetCategoryByID(6)
+	if err != nil || category == nil || category.Name != "Ship" {
+		t.Errorf("GetCategoryByID = %+v, %v, want Ship", category, err)
+	}
+	if category, err := repos.Items.GetCategoryByID(1); category != nil || err != nil {
+		t.Errorf("GetCategoryByID of an unknown category = %+v, %v, want nil", category, err)
+	}
+
+	all, err := repos.Items.GetGroups(0)
+	if err != nil || len(all) != 3 {
+		t.Errorf("GetGroups(0) = %d groups, %v, want all 3", len(all), err)
+	}
+	ships, err := repos.Items.GetGroups(6)
+	if err != nil || len(ships) != 2 || ships[0].GroupID != 25 {
+		t.Errorf("GetGroups(6) = %+v, %v, want the ship groups ordered by ID", ships, err)
+	}
+	group, err := repos.Items.GetGroupByID(26)
+	if err != nil || group == nil || group.Name != "Cruisers" || group.Published {
+		t.Errorf("GetGroupByID = %+v, %v, want the replaced cruiser group", group, err)
+	}
+	if group, err := repos.Items.GetGroupByID(1); group != nil || err != nil {
+		t.Errorf("GetGroupByID of an unknown group = %+v, %v, want nil", group, err)
+	}
+
+	marketGroups, err := repos.Items.GetAllMarketGroups()
+	if err != nil || len(marketGroups) != 2 || marketGroups[0].MarketGroupID != 4 || marketGroups[1].ParentGroupID != 4 {
+		t.Errorf("GetAllMarketGroups = %+v, %v, want both ordered by ID", marketGroups, err)
+	}
+}
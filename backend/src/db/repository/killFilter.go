@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+)
+
+// KillFilter narrows a query on the kills table. Zero fields are ignored.
+type KillFilter struct {
+	StartTime time.Time
+	EndTime   time.Time
+	SystemID  int64
+	// SystemIDs keeps kills in any of the systems, e.g. those within a
+	// number of jumps of a staging system.
+	SystemIDs []int
+	RegionIDs []int64
+	// CorporationID keeps kills made while a tracked participant was a
+	// member of the corporation, according to the affiliation history.
+	CorporationID int64
+	// ShipGroups and ShipCategories keep kills whose victim ship belongs to
+	// one of the groups or categories, given by ID or case-insensitive name.
+	ShipGroups     []string
+	ShipCategories []string
+	// SovAllianceID and FWOccupierFactionID keep kills in space held by the
+	// alliance or occupied by the faction at the time of the kill.
+	SovAllianceID       int64
+	FWOccupierFactionID int64
+	// Labels keeps kills carrying all of the zKillboard labels, e.g. solo
+	// or loc:nullsec.
+	Labels []string
+	// Role keeps kills on which a tracked character was an attacker
+	// (models.RoleAttacker) or the victim (models.RoleVictim).
+	Role string
+}
+
+// Apply adds the filter conditions to query, which must select from kills.
+func (f KillFilter) Apply(query *gorm.DB) *gorm.DB {
+	return f.apply(query, "kills")
+}
+
+// apply adds the filter conditions to query, which must select from kills.
+// The character and role conditions hold for any participant of the kill,
+// or for the joined participant when characterTable is kill_participants.
+func (f KillFilter) apply(query *gorm.DB, characterTable string) *gorm.DB {
+	if !f.StartTime.IsZero() {
+		query = query.Where("kills.killmail_time >= ?", f.StartTime)
+	}
+	if !f.EndTime.IsZero() {
+		query = query.Where("kills.killmail_time < ?", f.EndTime)
+	}
+	if f.SystemID != 0 {
+		query = query.Where("kills.solar_system_id = ?", f.SystemID)
+	}
+	if f.SystemIDs != nil {
+		query = query.Where("kills.solar_system_id IN ?", f.SystemIDs)
+	}
+	if len(f.RegionIDs) > 0 {
+		query = query.Joins("JOIN systems ON kills.solar_system_id = systems.system_id").
+			Where("systems.region_id IN ?", f.RegionIDs)
+	}
+	if characterTable == "kills" {
+		// kills.character_id is only the character the kill was first
+		// stored for, while every tracked participant is credited
+		if conditions, args := f.participantConditions("kill_participants"); len(conditions) > 0 {
+			query = query.Where(`EXISTS (
+				SELECT 1 FROM kill_participants
+				WHERE kill_participants.killmail_id = kills.killmail_id
+				AND `+strings.Join(conditions, " AND ")+`
+			)`, args...)
+		}
+	} else if conditions, args := f.participantConditions(characterTable); len(conditions) > 0 {
+		query = query.Where(strings.Join(conditions, " AND "), args...)
+	}
+	if len(f.Labels) > 0 {
+		query = f.applyLabels(query)
+	}
+	if f.SovAllianceID != 0 {
+		query = query.Where("kills.space_sov_alliance_id = ?", f.SovAllianceID)
+	}
+	if f.FWOccupierFactionID != 0 {
+		query = query.Where("kills.space_fw_occupier_faction_id = ?", f.FWOccupierFactionID)
+	}
+	if len(f.ShipGroups) > 0 {
+		ids, names := splitIDsAndNames(f.ShipGroups)
+		query = query.Where(`kills.victim_ship_type_id IN (
+			SELECT esi_items.type_id FROM esi_items
+			JOIN item_groups ON item_groups.group_id = esi_items.group_id
+			WHERE item_groups.group_id IN ? OR LOWER(item_groups.name) IN ?
+		)`, ids, names)
+	}
+	if len(f.ShipCategories) > 0 {
+		ids, names := splitIDsAndNames(f.ShipCategories)
+		query = query.Where(`kills.victim_ship_type_id IN (
+			SELECT esi_items.type_id FROM esi_items
+			JOIN item_groups ON item_groups.group_id = esi_items.group_id
+			JOIN item_categories ON item_categories.category_id = item_groups.category_id
+			WHERE item_categories.category_id IN ? OR LOWER(item_categories.name) IN ?
+		)`, ids, names)
+	}
+	return query
+}
+
+// participantConditions returns the conditions on the character and role
+// of a kill participant held in table, with their arguments.
+func (f KillFilter) participantConditions(table string) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if f.CorporationID != 0 {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM character_affiliations
+			WHERE character_affiliations.character_id = `+table+`.character_id
+			AND character_affiliations.corporation_id = ?
+			AND character_affiliations.start_date <= kills.killmail_time
+			AND (character_affiliations.end_date IS NULL OR character_affiliations.end_date > kills.killmail_time)
+		)`)
+		args = append(args, f.CorporationID)
+	}
+	if f.Role != "" {
+		conditions = append(conditions, table+".role = ?")
+		args = append(args, f.Role)
+	}
+	return conditions, args
+}
+
+// applyLabels keeps kills carrying all of f.Labels. SQLite has no arrays,
+// so there the labels are looked up in the stored array literal.
+func (f KillFilter) applyLabels(query *gorm.DB) *gorm.DB {
+	if !db.IsSQLite(query) {
+		return query.Where("kills.killmail_id IN (SELECT zkills.killmail_id FROM zkills WHERE zkills.labels @> ?)", models.StringArray(f.Labels))
+	}
+
+	conditions := make([]string, len(f.Labels))
+	args := make([]interface{}, len(f.Labels))
+	for i, label := range f.Labels {
+		literal, _ := models.StringArray{label}.Value()
+		conditions[i] = "instr(zkills.labels, ?) > 0"
+		// The quoted element between the braces, e.g. "solo"
+		args[i] = strings.Trim(literal.(string), "{}")
+	}
+	return query.Where("kills.killmail_id IN (SELECT zkills.killmail_id FROM zkills WHERE "+strings.Join(conditions, " AND ")+")", args...)
+}
+
+// splitIDsAndNames separates numeric IDs from lower-cased names. Both lists
+// hold at least one element so they can be used with IN.
+func splitIDsAndNames(values []string) ([]int, []string) {
+	ids := []int{0}
+	names := []string{""}
+	for _, value := range values {
+		if id, err := strconv.Atoi(value); err == nil {
+			ids = append(ids, id)
+		} else {
+			names = append(names, strings.ToLower(value))
+		}
+	}
+	return ids, names
+}
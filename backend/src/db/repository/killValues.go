@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// itemsDecoded holds for kills whose victim items were decoded. Kills
+// stored before the item tree was kept hold JSON null until they are
+// enriched again, and valuing them would count the hull only.
+const itemsDecoded = "kills.victim_items IS NOT NULL AND CAST(kills.victim_items AS TEXT) <> 'null'"
+
+func (r *postgresKills) GetUnvaluedKills(limit int) ([]models.Kill, error) {
+	var kills []models.Kill
+	err := r.db.Where("value_computed_at IS NULL").Where(itemsDecoded).Order("killmail_id").Limit(limit).Find(&kills).Error
+	return kills, err
+}
+
+func (r *postgresKills) GetKillsToReprice(before time.Time, afterID int64, limit int) ([]models.Kill, error) {
+	var kills []models.Kill
+	err := r.db.
+		Where("value_historical IS NOT TRUE AND killmail_time < ? AND killmail_id > ?", before, afterID).
+		Where(itemsDecoded).
+		Order("killmail_id").
+		Limit(limit).
+		Find(&kills).Error
+	return kills, err
+}
+
+func (r *postgresKills) UpdateKillValue(killmailID int64, value models.KillValue) error {
+	return r.db.Model(&models.Kill{}).Where("killmail_id = ?", killmailID).Updates(map[string]interface{}{
+		"value_hull":        value.Hull,
+		"value_destroyed":   value.Destroyed,
+		"value_dropped":     value.Dropped,
+		"value_total":       value.Total,
+		"value_historical":  value.Historical,
+		"value_computed_at": value.ComputedAt,
+	}).Error
+}
+
+func (r *postgresKills) TagKillSpace() (int64, error) {
+	result := r.db.Exec(`
+		UPDATE kills SET
+			space_sov_alliance_id = system_controls.sov_alliance_id,
+			space_sov_corporation_id = system_controls.sov_corporation_id,
+			space_sov_faction_id = system_controls.sov_faction_id,
+			space_fw_occupier_faction_id = system_controls.fw_occupier_faction_id,
+			space_tagged = true
+		FROM system_controls
+		WHERE system_controls.system_id = kills.solar_system_id
+		AND system_controls.start_date <= kills.killmail_time
+		AND (system_controls.end_date IS NULL OR system_controls.end_date > kills.killmail_time)
+		AND kills.space_tagged IS NOT TRUE
+	`)
+	return result.RowsAffected, result.Error
+}
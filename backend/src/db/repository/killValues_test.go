@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm/clause"
+)
+
+func TestGetUnvaluedKillsSkipsUndecodedItems(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	killTime := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	kills := []models.Kill{
+		{KillmailID: 1, KillmailTime: killTime, Victim: models.Victim{Items: models.ItemArray{{ItemTypeID: 34}}}},
+		{KillmailID: 2, KillmailTime: killTime},
+		{KillmailID: 3, KillmailTime: killTime},
+	}
+	if err := db.DB.Omit(clause.Associations).Create(&kills).Error; err != nil {
+		t.Fatalf("storing kills: %v", err)
+	}
+	// Kill 3 was stored before items were decoded
+	if err := db.DB.Exec("UPDATE kills SET victim_items = 'null' WHERE killmail_id = 3").Error; err != nil {
+		t.Fatalf("clearing items: %v", err)
+	}
+
+	unvalued, err := repos.Kills.GetUnvaluedKills(10)
+	if err != nil {
+		t.Fatalf("GetUnvaluedKills: %v", err)
+	}
+	if len(unvalued) != 2 || unvalued[0].KillmailID != 1 || unvalued[1].KillmailID != 2 {
+		t.Errorf("unvalued kills %+v, want 1 and the empty fit 2", unvalued)
+	}
+}
+
+func TestTagKillSpace(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	const systemID = 30003830
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	handover := start.Add(24 * time.Hour)
+	if err := repos.Universe.RecordSystemControl([]models.SystemControl{{SystemID: systemID, SovAllianceID: 1}}, start); err != nil {
+		t.Fatalf("RecordSystemControl: %v", err)
+	}
+	if err := repos.Universe.RecordSystemControl([]models.SystemControl{{SystemID: systemID, SovAllianceID: 2}}, handover); err != nil {
+		t.Fatalf("RecordSystemControl: %v", err)
+	}
+
+	kills := []models.Kill{
+		{KillmailID: 1, SolarSystemID: systemID, KillmailTime: start.Add(-time.Hour)},
+		{KillmailID: 2, SolarSystemID: systemID, KillmailTime: start.Add(time.Hour)},
+		{KillmailID: 3, SolarSystemID: systemID, KillmailTime: handover.Add(time.Hour)},
+		{KillmailID: 4, SolarSystemID: systemID + 1, KillmailTime: start.Add(time.Hour)},
+	}
+	if err := db.DB.Omit(clause.Associations).Create(&kills).Error; err != nil {
+		t.Fatalf("storing kills: %v", err)
+	}
+
+	tagged, err := repos.Kills.TagKillSpace()
+	if err != nil {
+		t.Fatalf("TagKillSpace: %v", err)
+	}
+	if tagged != 2 {
+		t.Errorf("tagged %d kills, want the 2 within a control period", tagged)
+	}
+
+	want := map[int64]models.KillSpace{
+		1: {},
+		2: {SovAllianceID: 1, Tagged: true},
+		3: {SovAllianceID: 2, Tagged: true},
+		4: {},
+	}
+	var stored []models.Kill
+	if err := db.DB.Order("killmail_id").Find(&stored).Error; err != nil {
+		t.Fatalf("loading kills: %v", err)
+	}
+	for _, kill := range stored {
+		if kill.Space != want[kill.KillmailID] {
+			t.Errorf("kill %d tagged %+v, want %+v", kill.KillmailID, kill.Space, want[kill.KillmailID])
+		}
+	}
+
+	tagged, err = repos.Kills.TagKillSpace()
+	if err != nil {
+		t.Fatalf("TagKillSpace: %v", err)
+	}
+	if tagged != 0 {
+		t.Errorf("tagged %d kills again, want 0", tagged)
+	}
+}
+
+func TestGetKillsToReprice(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	kills := []models.Kill{
+		{KillmailID: 1, KillmailTime: day},
+		{KillmailID: 2, KillmailTime: day},
+		{KillmailID: 3, KillmailTime: day},
+		{KillmailID: 4, KillmailTime: day},
+		{KillmailID: 5, KillmailTime: day.AddDate(0, 0, 1)},
+	}
+	if err := db.DB.Omit(clause.Associations).Create(&kills).Error; err != nil {
+		t.Fatalf("storing kills: %v", err)
+	}
+	// 2 is valued at historical prices already and 3 was stored before
+	// items were decoded
+	computed := day.Add(time.Hour)
+	value := models.KillValue{Hull: 1, Destroyed: 2, Dropped: 3, Total: 6, Historical: true, ComputedAt: &computed}
+	if err := repos.Kills.UpdateKillValue(2, value); err != nil {
+		t.Fatalf("UpdateKillValue: %v", err)
+	}
+	if err := db.DB.Exec("UPDATE kills SET victim_items = 'null' WHERE killmail_id = 3").Error; err != nil {
+		t.Fatalf("clearing items: %v", err)
+	}
+
+	stored, err := repos.Kills.GetKillmail(2)
+	if err != nil || stored == nil {
+		t.Fatalf("GetKillmail = %v, %v", stored, err)
+	}
+	if stored.Value.Total != 6 || !stored.Value.Historical || !stored.Value.ComputedAt.Equal(computed) {
+		t.Errorf("stored value %+v, want %+v", stored.Value, value)
+	}
+
+	tests := []struct {
+		afterID int64
+		limit   int
+		want    []int64
+	}{
+		{0, 10, []int64{1, 4}},
+		{1, 10, []int64{4}},
+		{0, 1, []int64{1}},
+	}
+	for _, tt := range tests {
+		reprice, err := repos.Kills.GetKillsToReprice(day.AddDate(0, 0, 1), tt.afterID, tt.limit)
+		if err != nil {
+			t.Fatalf("GetKillsToReprice: %v", err)
+		}
+		var ids []int64
+		for _, kill := range reprice {
+			ids = append(ids, kill.KillmailID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("GetKillsToReprice after %d limited to %d = %v, want %v", tt.afterID, tt.limit, ids, tt.want)
+		}
+	}
+}
@@ -0,0 +1,448 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CountingMode selects how kills shared by several tracked characters are
+// credited in character stats.
+type CountingMode string
+
+const (
+	// CountParticipant credits every tracked attacker with the kill and its
+	// full value.
+	CountParticipant CountingMode = "participant"
+	// CountFinalBlow credits only the attacker who landed the final blow.
+	CountFinalBlow CountingMode = "finalBlow"
+	// CountDamageShare credits every tracked attacker with the kill and the
+	// share of its value matching their share of the damage taken.
+	CountDamageShare CountingMode = "damageShare"
+)
+
+// AttackerStatsOrder selects what attacker stats are ranked by.
+type AttackerStatsOrder string
+
+const (
+	OrderByFinalBlows AttackerStatsOrder = "finalBlows"
+	OrderByTopDamage  AttackerStatsOrder = "topDamage"
+	OrderByDamage     AttackerStatsOrder = "damage"
+	OrderByKills      AttackerStatsOrder = "kills"
+)
+
+// orderClause is the ORDER BY clause of the ranking.
+func (o AttackerStatsOrder) orderClause() string {
+	switch o {
+	case OrderByTopDamage:
+		return "top_damage DESC, damage_done DESC"
+	case OrderByDamage:
+		return "damage_done DESC"
+	case OrderByKills:
+		return "kill_count DESC, final_blows DESC"
+	default:
+		return "final_blows DESC, kill_count DESC"
+	}
+}
+
+// byIndex orders kill_items and kill_attackers rows by their position.
+var byIndex = clause.OrderByColumn{Column: clause.Column{Name: "index"}}
+
+type postgresKills struct {
+	db *gorm.DB
+}
+
+func (r *postgresKills) GetKillmail(killmailID int64) (*models.Kill, error) {
+	var kill models.Kill
+	err := r.db.Preload("ZkillData").Where("killmail_id = ?", killmailID).First(&kill).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &kill, nil
+}
+
+func (r *postgresKills) KillExists(killmailID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Kill{}).Where("killmail_id = ?", killmailID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *postgresKills) GetKills(filter KillFilter) ([]models.Kill, error) {
+	var kills []models.Kill
+	err := filter.Apply(r.db.Table("kills").Select("kills.*")).Preload("ZkillData").Find(&kills).Error
+	return kills, err
+}
+
+func (r *postgresKills) GetKillsForCharacter(characterID int64, role string, page, pageSize int) ([]models.Kill, error) {
+	var kills []models.Kill
+	offset := (page - 1) * pageSize
+	err := r.characterKillsQuery(characterID, role).Order("killmail_time DESC").Offset(offset).Limit(pageSize).Find(&kills).Error
+	return kills, err
+}
+
+func (r *postgresKills) GetTotalKillsForCharacter(characterID int64, role string) (int64, error) {
+	var count int64
+	err := r.characterKillsQuery(characterID, role).Count(&count).Error
+	return count, err
+}
+
+// characterKillsQuery selects the kills the character is credited for in
+// role.
+func (r *postgresKills) characterKillsQuery(characterID int64, role string) *gorm.DB {
+	return r.db.Model(&models.Kill{}).Where(
+		"kills.killmail_id IN (SELECT killmail_id FROM kill_participants WHERE character_id = ? AND role = ?)",
+		characterID, role)
+}
+
+func (r *postgresKills) GetCharacterKillmails(characterID int64, role string, startTime, endTime time.Time, systemID, regionID int64) ([]models.Kill, error) {
+	query := r.characterKillsQuery(characterID, role)
+
+	if !startTime.IsZero() {
+		query = query.Where("kills.killmail_time >= ?", startTime)
+	}
+	if !endTime.IsZero() {
+		query = query.Where("kills.killmail_time <= ?", endTime)
+	}
+	if systemID != 0 {
+		query = query.Where("kills.solar_system_id = ?", systemID)
+	}
+
+	if regionID != 0 {
+		query = query.Joins("JOIN systems ON kills.solar_system_id = systems.system_id").
+			Where("systems.region_id = ?", regionID)
+	}
+
+	var kills []models.Kill
+	err := query.Find(&kills).Error
+	return kills, err
+}
+
+func (r *postgresKills) GetKillItems(killmailID int64) ([]models.KillItem, error) {
+	var items []models.KillItem
+	err := r.db.Where("killmail_id = ?", killmailID).Order(byIndex).Find(&items).Error
+	return items, err
+}
+
+func (r *postgresKills) GetKillAttackers(killmailID int64) ([]models.KillAttacker, error) {
+	var attackers []models.KillAttacker
+	err := r.db.Where("killmail_id = ?", killmailID).Order(byIndex).Find(&attackers).Error
+	return attackers, err
+}
+
+func (r *postgresKills) GetKillParticipants(killmailID int64) ([]models.KillParticipant, error) {
+	var participants []models.KillParticipant
+	err := r.db.Where("killmail_id = ?", killmailID).Order("role, character_id").Find(&participants).Error
+	return participants, err
+}
+
+func (r *postgresKills) GetSystemKillCounts(since time.Time) (map[int]int, error) {
+	var rows []struct {
+		SolarSystemID int
+		Count         int
+	}
+	err := r.db.Table("kills").
+		Select("solar_system_id, COUNT(*) AS count").
+		Where("killmail_time >= ?", since).
+		Group("solar_system_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.SolarSystemID] = row.Count
+	}
+	return counts, nil
+}
+
+// valueColumns hold a kill's own valuation, see models.KillValue.
+var valueColumns = []string{
+	"value_hull",
+	"value_destroyed",
+	"value_dropped",
+	"value_total",
+	"value_historical",
+	"value_computed_at",
+}
+
+// sameItems holds when an upsert keeps the stored victim items.
+const sameItems = "CAST(kills.victim_items AS TEXT) = CAST(EXCLUDED.victim_items AS TEXT)"
+
+func (r *postgresKills) UpsertKill(kill *models.Kill) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates := clause.AssignmentColumns([]string{
+			"killmail_time",
+			"solar_system_id",
+			"victim_alliance_id",
+			"victim_character_id",
+			"victim_corporation_id",
+			"victim_damage_taken",
+			"victim_ship_type_id",
+			"victim_position_x",
+			"victim_position_y",
+			"victim_position_z",
+			"victim_items",
+			"attackers",
+		})
+		// Like its Zkill, a kill keeps the character it was first stored for
+		updates = append(updates,
+			clause.Assignment{Column: clause.Column{Name: "character_id"}, Value: gorm.Expr("CASE WHEN kills.character_id = 0 THEN EXCLUDED.character_id ELSE kills.character_id END")},
+			clause.Assignment{Column: clause.Column{Name: "role"}, Value: gorm.Expr("CASE WHEN kills.character_id = 0 THEN EXCLUDED.role ELSE kills.role END")},
+		)
+		// Replaced items, e.g. decoded for a kill stored without them, void
+		// its valuation so the valuation jobs pick it up again
+		for _, column := range valueColumns {
+			updates = append(updates, clause.Assignment{
+				Column: clause.Column{Name: column},
+				Value:  gorm.Expr(fmt.Sprintf("CASE WHEN %s THEN kills.%s ELSE NULL END", sameItems, column)),
+			})
+		}
+		// The Zkill is stored through the ZKillRepository beforehand
+		err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "killmail_id"}},
+			DoUpdates: updates,
+		}).Create(kill).Error
+		if err != nil {
+			return err
+		}
+		if err := replaceKillItems(tx, kill.KillmailID, kill.Victim.Items); err != nil {
+			return err
+		}
+		if err := replaceKillAttackers(tx, kill); err != nil {
+			return err
+		}
+		return replaceKillParticipants(tx, kill.KillmailID)
+	})
+}
+
+func replaceKillItems(tx *gorm.DB, killmailID int64, items models.ItemArray) error {
+	if err := tx.Where("killmail_id = ?", killmailID).Delete(&models.KillItem{}).Error; err != nil {
+		return err
+	}
+	rows := models.FlattenItems(killmailID, items)
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(rows, 500).Error
+}
+
+func replaceKillAttackers(tx *gorm.DB, kill *models.Kill) error {
+	rows, err := kill.AttackerRows()
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("killmail_id = ?", kill.KillmailID).Delete(&models.KillAttacker{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(rows, 500).Error
+}
+
+// syncKillParticipants adds the tracked characters among the attackers
+// selected by attackerCondition on kill_attackers and the victims selected by
+// victimCondition on kills. Both conditions take args.
+func syncKillParticipants(tx *gorm.DB, attackerCondition, victimCondition string, args ...interface{}) error {
+	values := []interface{}{models.RoleAttacker}
+	values = append(values, args...)
+	values = append(values, models.RoleVictim)
+	values = append(values, args...)
+	return tx.Exec(fmt.Sprintf(`
+		INSERT INTO kill_participants (killmail_id, character_id, role, final_blow, damage_done)
+		SELECT kill_attackers.killmail_id, kill_attackers.character_id, ?, kill_attackers.final_blow, kill_attackers.damage_done
+		FROM kill_attackers
+		JOIN characters ON characters.id = kill_attackers.character_id
+		WHERE %s
+		UNION ALL
+		SELECT kills.killmail_id, kills.victim_character_id, ?, false, 0
+		FROM kills
+		JOIN characters ON characters.id = kills.victim_character_id
+		WHERE %s
+		ON CONFLICT (killmail_id, character_id, role) DO UPDATE
+		SET final_blow = EXCLUDED.final_blow, damage_done = EXCLUDED.damage_done`, attackerCondition, victimCondition),
+		values...).Error
+}
+
+// replaceKillParticipants rebuilds the participants of a kill from its
+// stored attackers and victim.
+func replaceKillParticipants(tx *gorm.DB, killmailID int64) error {
+	if err := tx.Where("killmail_id = ?", killmailID).Delete(&models.KillParticipant{}).Error; err != nil {
+		return err
+	}
+	return syncKillParticipants(tx, "kill_attackers.killmail_id = ?", "kills.killmail_id = ?", killmailID)
+}
+
+func (r *postgresKills) BackfillKillRoles() (int64, error) {
+	// Kills in tracked regions without a tracked character keep an empty
+	// role; the others are losses only when the character was the victim
+	result := r.db.Exec(`
+		UPDATE kills SET role = CASE WHEN victim_character_id = character_id THEN ? ELSE ? END
+		WHERE (role IS NULL OR role = '') AND character_id <> 0`, models.RoleVictim, models.RoleAttacker)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	err := r.db.Exec(`
+		UPDATE zkills SET role = COALESCE((SELECT kills.role FROM kills WHERE kills.killmail_id = zkills.killmail_id), ?)
+		WHERE (role IS NULL OR role = '') AND character_id <> 0`, models.RoleAttacker).Error
+	return result.RowsAffected, err
+}
+
+func (r *postgresKills) BackfillKillParticipants() error {
+	return syncKillParticipants(r.db, "TRUE", "TRUE")
+}
+
+func (r *postgresKills) GetCharacterStats(filter KillFilter, source ValueSource, mode CountingMode) ([]models.CharacterStats, error) {
+	total := source.totalColumn()
+	credited := "kill_participants.role = ?"
+	if mode == CountFinalBlow {
+		credited += " AND kill_participants.final_blow"
+	}
+	killISK := total
+	if mode == CountDamageShare {
+		killISK = total + " * kill_participants.damage_done / NULLIF(kills.victim_damage_taken, 0)"
+	}
+
+	query := r.db.Table("kill_participants").
+		Select(`kill_participants.character_id,
+			COUNT(*) FILTER (WHERE `+credited+`) AS kill_count,
+			COALESCE(SUM(`+killISK+`) FILTER (WHERE `+credited+`), 0) AS total_isk,
+			COUNT(*) FILTER (WHERE kill_participants.role = ?) AS loss_count,
+			COALESCE(SUM(`+total+`) FILTER (WHERE kill_participants.role = ?), 0) AS loss_isk`,
+			models.RoleAttacker, models.RoleAttacker, models.RoleVictim, models.RoleVictim).
+		Joins("JOIN kills ON kills.killmail_id = kill_participants.killmail_id").
+		Joins("LEFT JOIN zkills ON zkills.killmail_id = kills.killmail_id").
+		Group("kill_participants.character_id")
+	query = filter.apply(query, "kill_participants")
+
+	var stats []models.CharacterStats
+	if err := query.Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	for i := range stats {
+		if isk := stats[i].TotalISK + stats[i].LossISK; isk > 0 {
+			stats[i].ISKEfficiency = stats[i].TotalISK / isk * 100
+		}
+	}
+	return stats, nil
+}
+
+// killAttackersQuery selects from kill_attackers joined with the kills
+// matching filter.
+func (r *postgresKills) killAttackersQuery(filter KillFilter) *gorm.DB {
+	query := r.db.Table("kill_attackers").
+		Joins("JOIN kills ON kills.killmail_id = kill_attackers.killmail_id")
+	return filter.Apply(query)
+}
+
+func (r *postgresKills) GetAttackerStats(filter KillFilter, order AttackerStatsOrder, limit int) ([]models.AttackerStats, error) {
+	query := r.killAttackersQuery(filter).
+		Select(`kill_attackers.character_id,
+			COUNT(*) AS kill_count,
+			COUNT(*) FILTER (WHERE kill_attackers.final_blow) AS final_blows,
+			COUNT(*) FILTER (WHERE kill_attackers.damage_done = (
+				SELECT MAX(top.damage_done) FROM kill_attackers AS top
+				WHERE top.killmail_id = kill_attackers.killmail_id
+			)) AS top_damage,
+			COALESCE(SUM(kill_attackers.damage_done), 0) AS damage_done`).
+		Where("kill_attackers.character_id <> 0").
+		Group("kill_attackers.character_id").
+		Order(order.orderClause()).
+		Limit(limit)
+
+	var stats []models.AttackerStats
+	err := query.Scan(&stats).Error
+	return stats, err
+}
+
+func (r *postgresKills) GetShipUsage(filter KillFilter, limit int) ([]models.ShipUsage, error) {
+	query := r.killAttackersQuery(filter).
+		Select(`kill_attackers.ship_type_id,
+			COUNT(*) AS use_count,
+			COUNT(DISTINCT kill_attackers.character_id) FILTER (WHERE kill_attackers.character_id <> 0) AS pilot_count,
+			COUNT(*) FILTER (WHERE kill_attackers.final_blow) AS final_blows,
+			COALESCE(SUM(kill_attackers.damage_done), 0) AS damage_done`).
+		Where("kill_attackers.ship_type_id <> 0").
+		Group("kill_attackers.ship_type_id").
+		Order("use_count DESC").
+		Limit(limit)
+
+	var usage []models.ShipUsage
+	err := query.Scan(&usage).Error
+	return usage, err
+}
+
+func (r *postgresKills) GetSpaceBreakdown(filter KillFilter, source ValueSource) ([]models.SpaceBreakdown, error) {
+	query := r.db.Table("kills").
+		Select("kills.space_sov_alliance_id AS sov_alliance_id, kills.space_sov_faction_id AS sov_faction_id, " +
+			"kills.space_fw_occupier_faction_id AS fw_occupier_faction_id, " +
+			"COUNT(*) AS kill_count, COALESCE(SUM(" + source.totalColumn() + "), 0) AS total_isk").
+		Joins("LEFT JOIN zkills ON zkills.killmail_id = kills.killmail_id").
+		Where("kills.space_tagged IS TRUE").
+		Group("kills.space_sov_alliance_id, kills.space_sov_faction_id, kills.space_fw_occupier_faction_id").
+		Order("kill_count DESC")
+	query = filter.Apply(query)
+
+	var breakdown []models.SpaceBreakdown
+	err := query.Scan(&breakdown).Error
+	return breakdown, err
+}
+
+func (r *postgresKills) GetEntityKillStats(field string, id int64, source ValueSource) (*models.EntityKillStats, error) {
+	byAttacker := "kills.killmail_id IN (SELECT kill_attackers.killmail_id FROM kill_attackers WHERE kill_attackers." + field + " = ?)"
+
+	var stats models.EntityKillStats
+
+	var kills struct {
+		Count int
+		ISK   float64
+	}
+	err := r.db.Table("kills").
+		Select("COUNT(*) AS count, COALESCE(SUM("+source.totalColumn()+"), 0) AS isk").
+		Joins("LEFT JOIN zkills ON zkills.killmail_id = kills.killmail_id").
+		Where(byAttacker, id).
+		Scan(&kills).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.KillCount, stats.KillISK = kills.Count, kills.ISK
+
+	var losses struct {
+		Count int
+		ISK   float64
+	}
+	err = r.db.Table("kills").
+		Select("COUNT(*) AS count, COALESCE(SUM("+source.totalColumn()+"), 0) AS isk").
+		Joins("LEFT JOIN zkills ON zkills.killmail_id = kills.killmail_id").
+		Where("kills.victim_"+field+" = ?", id).
+		Scan(&losses).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.LossCount, stats.LossISK = losses.Count, losses.ISK
+
+	err = r.db.Table("kills").
+		Select("kills.victim_corporation_id AS corporation_id, COUNT(*) AS kill_count, COALESCE(SUM("+source.totalColumn()+"), 0) AS total_isk").
+		Joins("LEFT JOIN zkills ON zkills.killmail_id = kills.killmail_id").
+		Where(byAttacker, id).
+		Group("kills.victim_corporation_id").
+		Order("kill_count DESC").
+		Limit(10).
+		Scan(&stats.TopVictims).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
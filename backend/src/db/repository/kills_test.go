@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm/clause"
+)
+
+func TestKillsForCharacter(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	for id := int64(1); id <= 3; id++ {
+		kill := testKill(t, id, models.Attacker{CharacterID: pilotA, DamageDone: 1000, FinalBlow: true})
+		kill.KillmailTime = kill.KillmailTime.Add(time.Duration(id) * time.Hour)
+		storeKill(t, repos, kill, 100)
+	}
+	loss := testKill(t, 4, models.Attacker{CharacterID: 555, DamageDone: 1000, FinalBlow: true})
+	loss.Victim.CharacterID = pilotA
+	storeKill(t, repos, loss, 100)
+
+	if exists, err := repos.Kills.KillExists(1); !exists || err != nil {
+		t.Errorf("KillExists(1) = %v, %v, want true", exists, err)
+	}
+	if exists, err := repos.Kills.KillExists(5); exists || err != nil {
+		t.Errorf("KillExists(5) = %v, %v, want false", exists, err)
+	}
+
+	if total, err := repos.Kills.GetTotalKillsForCharacter(pilotA, models.RoleAttacker); total != 3 || err != nil {
+		t.Errorf("GetTotalKillsForCharacter(attacker) = %d, %v, want 3", total, err)
+	}
+	if total, err := repos.Kills.GetTotalKillsForCharacter(pilotA, models.RoleVictim); total != 1 || err != nil {
+		t.Errorf("GetTotalKillsForCharacter(victim) = %d, %v, want 1", total, err)
+	}
+
+	page, err := repos.Kills.GetKillsForCharacter(pilotA, models.RoleAttacker, 1, 2)
+	if err != nil || len(page) != 2 || page[0].KillmailID != 3 || page[1].KillmailID != 2 {
+		t.Errorf("first page of kills = %v, %v, want 3 and 2, newest first", killmailIDs(page), err)
+	}
+	page, err = repos.Kills.GetKillsForCharacter(pilotA, models.RoleAttacker, 2, 2)
+	if err != nil || len(page) != 1 || page[0].KillmailID != 1 {
+		t.Errorf("second page of kills = %v, %v, want 1", killmailIDs(page), err)
+	}
+	if kills, _ := repos.Kills.GetKillsForCharacter(pilotB, models.RoleAttacker, 1, 10); len(kills) != 0 {
+		t.Errorf("pilotB credited with %v, want no kills", killmailIDs(kills))
+	}
+}
+
+func TestGetSystemKillCounts(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	old := testKill(t, 1)
+	old.KillmailTime = old.KillmailTime.AddDate(0, 0, -2)
+	other := testKill(t, 3)
+	other.SolarSystemID = 30003831
+	for _, kill := range []*models.Kill{old, testKill(t, 2), other, testKill(t, 4)} {
+		storeKill(t, repos, kill, 100)
+	}
+
+	counts, err := repos.Kills.GetSystemKillCounts(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || !reflect.DeepEqual(counts, map[int]int{30003830: 2, 30003831: 1}) {
+		t.Errorf("GetSystemKillCounts = %v, %v, want 2 in 30003830 and 1 in 30003831", counts, err)
+	}
+}
+
+func TestBackfillKillRoles(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	// Stored before losses were tracked, and in a tracked region without a
+	// tracked character
+	killTime := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	kills := []models.Kill{
+		{KillmailID: 1, KillmailTime: killTime, CharacterID: pilotA},
+		{KillmailID: 2, KillmailTime: killTime, CharacterID: pilotA, Victim: models.Victim{CharacterID: pilotA}},
+		{KillmailID: 3, KillmailTime: killTime},
+		{KillmailID: 4, KillmailTime: killTime, CharacterID: pilotB, Role: models.RoleVictim},
+	}
+	if err := db.DB.Omit(clause.Associations).Create(&kills).Error; err != nil {
+		t.Fatalf("storing kills: %v", err)
+	}
+	err := repos.ZKills.UpsertZKills([]models.Zkill{
+		{KillmailID: 1, CharacterID: pilotA},
+		{KillmailID: 2, CharacterID: pilotA},
+		{KillmailID: 3},
+		{KillmailID: 5, CharacterID: pilotB},
+	})
+	if err != nil {
+		t.Fatalf("UpsertZKills: %v", err)
+	}
+
+	set, err := repos.Kills.BackfillKillRoles()
+	if err != nil || set != 2 {
+		t.Fatalf("BackfillKillRoles = %d, %v, want the 2 kills of a tracked character", set, err)
+	}
+
+	want := map[int64]string{1: models.RoleAttacker, 2: models.RoleVictim, 3: "", 4: models.RoleVictim}
+	var stored []models.Kill
+	if err := db.DB.Omit("ZkillData").Find(&stored).Error; err != nil {
+		t.Fatalf("loading kills: %v", err)
+	}
+	for _, kill := range stored {
+		if kill.Role != want[kill.KillmailID] {
+			t.Errorf("kill %d has role %q, want %q", kill.KillmailID, kill.Role, want[kill.KillmailID])
+		}
+	}
+
+	// Zkills take the role of their kill, or attacker without one
+	want = map[int64]string{1: models.RoleAttacker, 2: models.RoleVictim, 3: "", 5: models.RoleAttacker}
+	for id, role := range want {
+		zkill, err := repos.ZKills.GetZKillByID(id)
+		if err != nil || zkill.Role != role {
+			t.Errorf("zkill %d has role %q, %v, want %q", id, zkill.Role, err, role)
+		}
+	}
+}
+
+func TestBackfillKillParticipants(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	kill := testKill(t, 1, models.Attacker{CharacterID: pilotA, DamageDone: 1000, FinalBlow: true})
+	kill.Victim.CharacterID = pilotB
+	storeKill(t, repos, kill, 100)
+	// Stored before participants were kept
+	if err := db.DB.Exec("DELETE FROM kill_participants").Error; err != nil {
+		t.Fatalf("clearing participants: %v", err)
+	}
+
+	if err := repos.Kills.BackfillKillParticipants(); err != nil {
+		t.Fatalf("BackfillKillParticipants: %v", err)
+	}
+	if err := repos.Kills.BackfillKillParticipants(); err != nil {
+		t.Fatalf("BackfillKillParticipants again: %v", err)
+	}
+	participants, err := repos.Kills.GetKillParticipants(1)
+	if err != nil || len(participants) != 2 {
+		t.Fatalf("GetKillParticipants = %+v, %v, want the attacker and the victim", participants, err)
+	}
+	attacker, victim := participants[0], participants[1]
+	if attacker.CharacterID != pilotA || attacker.Role != models.RoleAttacker || !attacker.FinalBlow || attacker.DamageDone != 1000 {
+		t.Errorf("attacker %+v, want pilotA landing the final blow", attacker)
+	}
+	if victim.CharacterID != pilotB || victim.Role != models.RoleVictim {
+		t.Errorf("victim %+v, want pilotB", victim)
+	}
+}
+
+func TestAttackerStatsAndShipUsage(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	// pilotA lands more final blows, pilotB is on more kills and deals more
+	// damage
+	storeKill(t, repos, testKill(t, 1,
+		models.Attacker{CharacterID: pilotA, DamageDone: 200, FinalBlow: true, ShipTypeID: 587},
+		models.Attacker{CharacterID: pilotB, DamageDone: 800, ShipTypeID: 11371},
+	), 100)
+	storeKill(t, repos, testKill(t, 2,
+		models.Attacker{CharacterID: pilotA, DamageDone: 600, FinalBlow: true, ShipTypeID: 587},
+		models.Attacker{CharacterID: pilotB, DamageDone: 300, ShipTypeID: 587},
+		models.Attacker{DamageDone: 100, ShipTypeID: 23913},
+	), 100)
+	storeKill(t, repos, testKill(t, 3,
+		models.Attacker{CharacterID: pilotB, DamageDone: 1000, FinalBlow: true, ShipTypeID: 11371},
+	), 100)
+	storeKill(t, repos, testKill(t, 4,
+		models.Attacker{CharacterID: pilotA, DamageDone: 500, FinalBlow: true, ShipTypeID: 587},
+		models.Attacker{CharacterID: pilotB, DamageDone: 500, ShipTypeID: 587},
+	), 100)
+
+	stats, err := repos.Kills.GetAttackerStats(KillFilter{}, OrderByFinalBlows, 10)
+	want := []models.AttackerStats{
+		{CharacterID: pilotA, KillCount: 3, FinalBlows: 3, TopDamage: 2, DamageDone: 1300},
+		{CharacterID: pilotB, KillCount: 4, FinalBlows: 1, TopDamage: 3, DamageDone: 2600},
+	}
+	if err != nil || !reflect.DeepEqual(stats, want) {
+		t.Fatalf("GetAttackerStats = %+v, %v, want %+v", stats, err, want)
+	}
+
+	tests := []struct {
+		order AttackerStatsOrder
+		first int64
+	}{
+		{OrderByFinalBlows, pilotA},
+		{OrderByTopDamage, pilotB},
+		{OrderByDamage, pilotB},
+		{OrderByKills, pilotB},
+	}
+	for _, tt := range tests {
+		stats, err := repos.Kills.GetAttackerStats(KillFilter{}, tt.order, 1)
+		if err != nil || len(stats) != 1 || stats[0].CharacterID != tt.first {
+			t.Errorf("GetAttackerStats(%s) = %+v, %v, want %d first", tt.order, stats, err, tt.first)
+		}
+	}
+
+	usage, err := repos.Kills.GetShipUsage(KillFilter{}, 10)
+	wantUsage := []models.ShipUsage{
+		{ShipTypeID: 587, UseCount: 5, PilotCount: 2, FinalBlows: 3, DamageDone: 2100},
+		{ShipTypeID: 11371, UseCount: 2, PilotCount: 1, FinalBlows: 1, DamageDone: 1800},
+		{ShipTypeID: 23913, UseCount: 1, PilotCount: 0, FinalBlows: 0, DamageDone: 100},
+	}
+	if err != nil || !reflect.DeepEqual(usage, wantUsage) {
+		t.Errorf("GetShipUsage = %+v, %v, want %+v", usage, err, wantUsage)
+	}
+}
+
+func TestGetSpaceBreakdown(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	err := repos.Universe.RecordSystemControl([]models.SystemControl{
+		{SystemID: 30003830, SovAllianceID: 1},
+		{SystemID: 30003831, SovFactionID: 500001, FWOccupierFactionID: 500002},
+	}, start)
+	if err != nil {
+		t.Fatalf("RecordSystemControl: %v", err)
+	}
+	other := testKill(t, 3)
+	other.SolarSystemID = 30003831
+	untagged := testKill(t, 4)
+	untagged.SolarSystemID = 30003832
+	storeKill(t, repos, testKill(t, 1), 100)
+	storeKill(t, repos, testKill(t, 2), 200)
+	storeKill(t, repos, other, 300)
+	storeKill(t, repos, untagged, 400)
+	if _, err := repos.Kills.TagKillSpace(); err != nil {
+		t.Fatalf("TagKillSpace: %v", err)
+	}
+	now := time.Now()
+	if err := repos.Kills.UpdateKillValue(3, models.KillValue{Total: 350, ComputedAt: &now}); err != nil {
+		t.Fatalf("UpdateKillValue: %v", err)
+	}
+
+	tests := []struct {
+		source ValueSource
+		want   []models.SpaceBreakdown
+	}{
+		{ValueSourceZKill, []models.SpaceBreakdown{
+			{SovAllianceID: 1, KillCount: 2, TotalISK: 300},
+			{SovFactionID: 500001, FWOccupierFactionID: 500002, KillCount: 1, TotalISK: 300},
+		}},
+		{ValueSourceOwn, []models.SpaceBreakdown{
+			{SovAllianceID: 1, KillCount: 2, TotalISK: 0},
+			{SovFactionID: 500001, FWOccupierFactionID: 500002, KillCount: 1, TotalISK: 350},
+		}},
+	}
+	for _, tt := range tests {
+		breakdown, err := repos.Kills.GetSpaceBreakdown(KillFilter{}, tt.source)
+		if err != nil || !reflect.DeepEqual(breakdown, tt.want) {
+			t.Errorf("GetSpaceBreakdown(%s) = %+v, %v, want %+v", tt.source, breakdown, err, tt.want)
+		}
+	}
+}
+
+func TestGetEntityKillStats(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	// Corporation 1000 of alliance 3000 kills two ships of 200 and one of
+	// 300, and loses one to them
+	for id, victimCorporation := range map[int64]int64{1: 200, 2: 200, 3: 300} {
+		kill := testKill(t, id,
+			models.Attacker{CorporationID: 1000, AllianceID: 3000, DamageDone: 500, FinalBlow: true},
+			models.Attacker{CorporationID: 1000, AllianceID: 3000, DamageDone: 500},
+		)
+		kill.Victim.CorporationID = victimCorporation
+		storeKill(t, repos, kill, float64(100*id))
+	}
+	loss := testKill(t, 4, models.Attacker{CorporationID: 200, DamageDone: 1000, FinalBlow: true})
+	loss.Victim.CorporationID, loss.Victim.AllianceID = 1000, 3000
+	storeKill(t, repos, loss, 1000)
+
+	want := &models.EntityKillStats{
+		KillCount: 3,
+		KillISK:   600,
+		LossCount: 1,
+		LossISK:   1000,
+		TopVictims: []models.VictimCount{
+			{CorporationID: 200, KillCount: 2, TotalISK: 300},
+			{CorporationID: 300, KillCount: 1, TotalISK: 300},
+		},
+	}
+	for _, field := range []string{"corporation_id", "alliance_id"} {
+		id := int64(1000)
+		if field == "alliance_id" {
+			id = 3000
+		}
+		stats, err := repos.Kills.GetEntityKillStats(field, id, ValueSourceZKill)
+		if err != nil || !reflect.DeepEqual(stats, want) {
+			t.Errorf("GetEntityKillStats(%s) = %+v, %v, want %+v", field, stats, err, want)
+		}
+	}
+
+	stats, err := repos.Kills.GetEntityKillStats("corporation_id", 1, ValueSourceZKill)
+	if err != nil || stats.KillCount != 0 || stats.LossCount != 0 || len(stats.TopVictims) != 0 {
+		t.Errorf("GetEntityKillStats of an unknown corporation = %+v, %v, want nothing", stats, err)
+	}
+}
+
+func TestGetCharacterStatsOwnValue(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	storeKill(t, repos, testKill(t, 1, models.Attacker{CharacterID: pilotA, DamageDone: 1000, FinalBlow: true}), 1000)
+	loss := testKill(t, 2, models.Attacker{CharacterID: 555, DamageDone: 1000, FinalBlow: true})
+	loss.Victim.CharacterID = pilotA
+	storeKill(t, repos, loss, 500)
+	now := time.Now()
+	if err := repos.Kills.UpdateKillValue(1, models.KillValue{Total: 1500, ComputedAt: &now}); err != nil {
+		t.Fatalf("UpdateKillValue: %v", err)
+	}
+	if err := repos.Kills.UpdateKillValue(2, models.KillValue{Total: 500, ComputedAt: &now}); err != nil {
+		t.Fatalf("UpdateKillValue: %v", err)
+	}
+
+	stats, err := repos.Kills.GetCharacterStats(KillFilter{}, ValueSourceOwn, CountParticipant)
+	want := []models.CharacterStats{{CharacterID: pilotA, KillCount: 1, TotalISK: 1500, LossCount: 1, LossISK: 500, ISKEfficiency: 75}}
+	if err != nil || !reflect.DeepEqual(stats, want) {
+		t.Errorf("GetCharacterStats = %+v, %v, want %+v", stats, err, want)
+	}
+}
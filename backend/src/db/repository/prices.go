@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresPrices struct {
+	db *gorm.DB
+}
+
+func (r *postgresPrices) UpsertMarketPrices(prices []models.MarketPrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "type_id"}},
+		UpdateAll: true,
+	}).CreateInBatches(prices, 1000).Error
+}
+
+func (r *postgresPrices) GetPriceMap() (map[int]float64, error) {
+	var prices []models.MarketPrice
+	if err := r.db.Find(&prices).Error; err != nil {
+		return nil, err
+	}
+
+	priceMap := make(map[int]float64, len(prices))
+	for _, price := range prices {
+		if price.AveragePrice > 0 {
+			priceMap[price.TypeID] = price.AveragePrice
+		} else {
+			priceMap[price.TypeID] = price.AdjustedPrice
+		}
+	}
+	return priceMap, nil
+}
+
+func (r *postgresPrices) UpsertPriceHistory(history []models.PriceHistory) error {
+	if len(history) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "region_id"}, {Name: "type_id"}, {Name: "date"}},
+		UpdateAll: true,
+	}).CreateInBatches(history, 1000).Error
+}
+
+func (r *postgresPrices) GetKillMarketTypeIDs() ([]int, error) {
+	var ids []int
+	err := r.db.Raw(`
+		SELECT kill_types.type_id FROM (
+			SELECT victim_ship_type_id AS type_id FROM kills WHERE victim_ship_type_id <> 0
+			UNION
+			SELECT item_type_id AS type_id FROM kill_items
+		) AS kill_types
+		JOIN esi_items ON esi_items.type_id = kill_types.type_id
+		WHERE esi_items.market_group_id > 0
+	`).Scan(&ids).Error
+	return ids, err
+}
+
+func (r *postgresPrices) GetLatestHistoryDates(regionID int) (map[int]time.Time, error) {
+	// The newest rows are selected rather than MAX(date), which SQLite
+	// returns as text instead of a date
+	var rows []models.PriceHistory
+	err := r.db.Select("type_id, date").
+		Where("region_id = ?", regionID).
+		Where(`date = (
+			SELECT MAX(newest.date) FROM price_histories AS newest
+			WHERE newest.region_id = price_histories.region_id AND newest.type_id = price_histories.type_id
+		)`).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		latest[row.TypeID] = row.Date
+	}
+	return latest, nil
+}
+
+func (r *postgresPrices) GetPriceHistory(regionID int, typeIDs []int, from, to time.Time) ([]models.PriceHistory, error) {
+	var history []models.PriceHistory
+	err := r.db.
+		Where("region_id = ? AND type_id IN ? AND date BETWEEN ? AND ?", regionID, typeIDs, from, to).
+		Order("type_id, date").
+		Find(&history).Error
+	return history, err
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func TestMarketPrices(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	err := repos.Prices.UpsertMarketPrices([]models.MarketPrice{
+		{TypeID: 34, AveragePrice: 4, AdjustedPrice: 3},
+		{TypeID: 35, AdjustedPrice: 9},
+	})
+	if err != nil {
+		t.Fatalf("UpsertMarketPrices: %v", err)
+	}
+	if err := repos.Prices.UpsertMarketPrices([]models.MarketPrice{{TypeID: 34, AveragePrice: 5}}); err != nil {
+		t.Fatalf("UpsertMarketPrices: %v", err)
+	}
+
+	// Types without an average price fall back to the adjusted one
+	prices, err := repos.Prices.GetPriceMap()
+	if err != nil || !reflect.DeepEqual(prices, map[int]float64{34: 5, 35: 9}) {
+		t.Errorf("GetPriceMap = %v, %v, want 34 at 5 and 35 at 9", prices, err)
+	}
+}
+
+func TestPriceHistory(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	history := []models.PriceHistory{
+		{RegionID: 10000002, TypeID: 34, Date: day, Average: 4},
+		{RegionID: 10000002, TypeID: 34, Date: day.AddDate(0, 0, 1), Average: 5},
+		{RegionID: 10000002, TypeID: 35, Date: day.AddDate(0, 0, -3), Average: 9},
+		{RegionID: 10000043, TypeID: 34, Date: day.AddDate(0, 0, 5), Average: 6},
+	}
+	if err := repos.Prices.UpsertPriceHistory(history); err != nil {
+		t.Fatalf("UpsertPriceHistory: %v", err)
+	}
+	if err := repos.Prices.UpsertPriceHistory([]models.PriceHistory{{RegionID: 10000002, TypeID: 34, Date: day, Average: 4.5}}); err != nil {
+		t.Fatalf("UpsertPriceHistory: %v", err)
+	}
+
+	latest, err := repos.Prices.GetLatestHistoryDates(10000002)
+	if err != nil || len(latest) != 2 || !latest[34].Equal(day.AddDate(0, 0, 1)) || !latest[35].Equal(day.AddDate(0, 0, -3)) {
+		t.Errorf("GetLatestHistoryDates = %v, %v, want the newest day of both types in The Forge", latest, err)
+	}
+
+	rows, err := repos.Prices.GetPriceHistory(10000002, []int{34, 35}, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("GetPriceHistory = %+v, %v, want both days of 34", rows, err)
+	}
+	if rows[0].Average != 4.5 || !rows[0].Date.Equal(day) || rows[1].Average != 5 {
+		t.Errorf("GetPriceHistory = %+v, want the updated first day before the second", rows)
+	}
+}
+
+func TestGetKillMarketTypeIDs(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	// Tritanium inside a container and the Rifter hull are on the market,
+	// the container is not
+	err := repos.Items.BatchUpsertESIItems([]*models.ESIItem{
+		{TypeID: 587, MarketGroupID: 64},
+		{TypeID: 34, MarketGroupID: 1857},
+		{TypeID: 3465},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertESIItems: %v", err)
+	}
+	one := int64(1)
+	kill := testKill(t, 1)
+	kill.Victim.Items = models.ItemArray{
+		{ItemTypeID: 3465, QuantityDropped: &one, Items: []models.ZKillboardItem{{ItemTypeID: 34, QuantityDropped: &one}}},
+	}
+	storeKill(t, repos, kill, 100)
+
+	ids, err := repos.Prices.GetKillMarketTypeIDs()
+	sort.Ints(ids)
+	if err != nil || !reflect.DeepEqual(ids, []int{34, 587}) {
+		t.Errorf("GetKillMarketTypeIDs = %v, %v, want 34 and 587", ids, err)
+	}
+}
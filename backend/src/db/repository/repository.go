@@ -0,0 +1,334 @@
+// Package repository holds the data access of kills, zKillboard metadata,
+// characters, the universe, items, corporations and alliances, prices, the
+// enrichment queue and the ESI cache behind interfaces, so routes, jobs and
+// services run against Postgres in production and SQLite for local use.
+package repository
+
+import (
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+)
+
+// KillRepository stores killmails with their items, attackers and tracked
+// participants, and aggregates them.
+type KillRepository interface {
+	// GetKillmail returns a kill with its zKillboard data, or nil when it is
+	// not stored.
+	GetKillmail(killmailID int64) (*models.Kill, error)
+	KillExists(killmailID int64) (bool, error)
+	// GetKills returns the kills matching filter with their zKillboard data.
+	GetKills(filter KillFilter) ([]models.Kill, error)
+	// GetKillsForCharacter returns a page of the kills the character is
+	// credited for in role, newest first.
+	GetKillsForCharacter(characterID int64, role string, page, pageSize int) ([]models.Kill, error)
+	GetTotalKillsForCharacter(characterID int64, role string) (int64, error)
+	// GetCharacterKillmails returns the kills the character is credited for
+	// in role within the optional time range, system and region.
+	GetCharacterKillmails(characterID int64, role string, startTime, endTime time.Time, systemID, regionID int64) ([]models.Kill, error)
+	GetKillItems(killmailID int64) ([]models.KillItem, error)
+	GetKillAttackers(killmailID int64) ([]models.KillAttacker, error)
+	GetKillParticipants(killmailID int64) ([]models.KillParticipant, error)
+	// GetSystemKillCounts returns the number of kills per solar system since
+	// the given time.
+	GetSystemKillCounts(since time.Time) (map[int]int, error)
+
+	// UpsertKill stores a kill and replaces its items, attackers and
+	// participants. Its ZkillData is not written; store it with
+	// ZKillRepository.UpsertZKills first.
+	UpsertKill(kill *models.Kill) error
+	// BackfillKillRoles sets the role of kills stored before losses were
+	// tracked and returns how many were set.
+	BackfillKillRoles() (int64, error)
+	// BackfillKillParticipants credits the tracked characters on every
+	// stored kill.
+	BackfillKillParticipants() error
+
+	GetCharacterStats(filter KillFilter, source ValueSource, mode CountingMode) ([]models.CharacterStats, error)
+	GetAttackerStats(filter KillFilter, order AttackerStatsOrder, limit int) ([]models.AttackerStats, error)
+	GetShipUsage(filter KillFilter, limit int) ([]models.ShipUsage, error)
+	GetSpaceBreakdown(filter KillFilter, source ValueSource) ([]models.SpaceBreakdown, error)
+	// GetEntityKillStats aggregates kills and losses for the corporation or
+	// alliance id. field is "corporation_id" or "alliance_id".
+	GetEntityKillStats(field string, id int64, source ValueSource) (*models.EntityKillStats, error)
+
+	// GetUnvaluedKills returns up to limit kills with decoded items that
+	// have no own valuation yet.
+	GetUnvaluedKills(limit int) ([]models.Kill, error)
+	// GetKillsToReprice returns up to limit kills with decoded items from
+	// before the given time, with IDs above afterID, that have not been
+	// valued at historical prices yet.
+	GetKillsToReprice(before time.Time, afterID int64, limit int) ([]models.Kill, error)
+	UpdateKillValue(killmailID int64, value models.KillValue) error
+	// TagKillSpace tags every untagged kill that falls within a recorded
+	// control period of its solar system and returns how many were tagged.
+	TagKillSpace() (int64, error)
+}
+
+// ZKillRepository stores zKillboard metadata of killmails.
+type ZKillRepository interface {
+	ZKillExists(killmailID int64) (bool, error)
+	GetZKillByID(killmailID int64) (*models.Zkill, error)
+	// UpsertZKills stores zKillboard metadata. A killmail keeps the
+	// character and role it was first stored for.
+	UpsertZKills(zkills []models.Zkill) error
+	// GetZKillsWithoutLabels returns up to limit killmail IDs whose zkb
+	// flags and labels were never stored.
+	GetZKillsWithoutLabels(limit int) ([]int64, error)
+	UpdateZKillLabels(killmailID int64, solo, awox bool, labels models.StringArray) error
+
+	// GetZKillCursor returns the cursor of the list at path, or a fresh one
+	// if the list was never synced.
+	GetZKillCursor(path string) (*models.ZKillCursor, error)
+	SaveZKillCursor(cursor *models.ZKillCursor) error
+}
+
+// CharacterRepository stores the tracked characters and their affiliation
+// history.
+type CharacterRepository interface {
+	// GetCharacterByID returns the character, or nil when it is not tracked.
+	GetCharacterByID(id int64) (*models.Character, error)
+	GetAllCharacters() ([]models.Character, error)
+	// UpsertCharacter stores a tracked character and credits them for the
+	// stored kills they were on.
+	UpsertCharacter(character *models.Character) error
+	// DeleteCharacter stops tracking a character and drops their credit for
+	// kills.
+	DeleteCharacter(characterID int64) error
+
+	GetCharacterAffiliations(characterID int64) ([]models.CharacterAffiliation, error)
+	HasAffiliationHistory(characterID int64) (bool, error)
+	// SeedAffiliationHistory stores the full corporation history of a
+	// character that has no history yet.
+	SeedAffiliationHistory(characterID int64, history []models.CharacterAffiliation) error
+	// RecordAffiliation opens a new affiliation at time at unless it did not
+	// change, and reports whether it changed.
+	RecordAffiliation(characterID, corporationID, allianceID int64, at time.Time) (bool, error)
+}
+
+// UniverseRepository stores regions, constellations, solar systems and
+// stargates, who controls the systems, and the tracked regions.
+type UniverseRepository interface {
+	GetAllRegions() ([]models.Region, error)
+	// GetRegionByID returns the region, or nil when it is not stored.
+	GetRegionByID(regionID int) (*models.Region, error)
+	UpsertRegion(region *models.Region) error
+
+	GetAllConstellations() ([]models.Constellation, error)
+	GetConstellationByID(id int) (*models.Constellation, error)
+	GetConstellationsByRegionID(regionID int) ([]models.Constellation, error)
+	BatchUpsertConstellations(constellations []*models.Constellation) error
+
+	GetAllSystems() ([]models.System, error)
+	GetSystemByID(systemID int) (*models.System, error)
+	GetSystemsByRegionID(regionID int) ([]models.System, error)
+	GetSolarSystemIDsByRegion(regionID int) ([]int, error)
+	// GetSystemsForGraph returns every system without its celestials.
+	GetSystemsForGraph() ([]models.System, error)
+	BatchUpsertSystems(systems []*models.System) error
+
+	GetAllStargates() ([]models.Stargate, error)
+	// GetSystemStargateIDs returns the IDs of the stargates listed on stored
+	// systems.
+	GetSystemStargateIDs() ([]int, error)
+	BatchUpsertStargates(stargates []*models.Stargate) error
+
+	// GetStoredIDs returns the values of the primary key column of one of
+	// the universe tables.
+	GetStoredIDs(table, column string) (map[int]bool, error)
+
+	// GetCurrentSystemControl returns the open control record of every
+	// system, keyed by system ID.
+	GetCurrentSystemControl() (map[int]models.SystemControl, error)
+	// GetSystemControlHistory returns the control records of a system,
+	// oldest first.
+	GetSystemControlHistory(systemID int) ([]models.SystemControl, error)
+	// RecordSystemControl closes the open records of the systems in changes
+	// and opens the new ones at time at.
+	RecordSystemControl(changes []models.SystemControl, at time.Time) error
+
+	GetTrackedRegions() ([]models.TrackedRegion, error)
+	// GetTrackedRegion returns the tracked region, or nil if it is not
+	// tracked.
+	GetTrackedRegion(regionID int) (*models.TrackedRegion, error)
+	UpsertTrackedRegion(region *models.TrackedRegion) error
+	DeleteTrackedRegion(regionID int) error
+}
+
+// ItemRepository stores types and their categories, groups and market
+// groups.
+type ItemRepository interface {
+	GetAllESIItems() ([]models.ESIItem, error)
+	// GetESIItemByTypeID returns the type, or nil when it is not stored.
+	GetESIItemByTypeID(typeID int) (*models.ESIItem, error)
+	GetItemsByGroupID(groupID int) ([]models.ESIItem, error)
+	UpsertESIItem(item *models.ESIItem) error
+	BatchUpsertESIItems(items []*models.ESIItem) error
+
+	GetAllCategories() ([]models.ItemCategory, error)
+	// GetCategoryByID returns the category, or nil when it is not stored.
+	GetCategoryByID(categoryID int) (*models.ItemCategory, error)
+	// GetGroups returns all groups, or only those of categoryID when it is
+	// set.
+	GetGroups(categoryID int) ([]models.ItemGroup, error)
+	// GetGroupByID returns the group, or nil when it is not stored.
+	GetGroupByID(groupID int) (*models.ItemGroup, error)
+	GetAllMarketGroups() ([]models.MarketGroup, error)
+	BatchUpsertCategories(categories []*models.ItemCategory) error
+	BatchUpsertGroups(groups []*models.ItemGroup) error
+	BatchUpsertMarketGroups(marketGroups []*models.MarketGroup) error
+
+	// GetStoredIDs returns the values of the primary key column of one of
+	// the type or taxonomy tables.
+	GetStoredIDs(table, column string) (map[int]bool, error)
+}
+
+// EntityRepository stores corporations, alliances, the tracked
+// corporations and alliances, and resolved names.
+type EntityRepository interface {
+	// GetCorporationByID returns the corporation, or nil when it is not
+	// stored.
+	GetCorporationByID(id int64) (*models.Corporation, error)
+	// GetAllianceByID returns the alliance, or nil when it is not stored.
+	GetAllianceByID(id int64) (*models.Alliance, error)
+	UpsertCorporations(corporations []models.Corporation) error
+	UpsertAlliance(alliance *models.Alliance) error
+	// SumCorporationMembers returns the total member count of the stored
+	// corporations in ids.
+	SumCorporationMembers(ids []int64) (int, error)
+	// GetReferencedEntityIDs returns every ID stored in the given victim
+	// column or attacker field ("corporation_id" or "alliance_id") across
+	// all kills.
+	GetReferencedEntityIDs(field string) ([]int64, error)
+	// GetFreshEntityIDs returns the IDs in table updated after since.
+	GetFreshEntityIDs(table string, since time.Time) (map[int64]bool, error)
+
+	GetTrackedEntities() ([]models.TrackedEntity, error)
+	// GetTrackedEntity returns the tracked entity, or nil if it is not
+	// tracked.
+	GetTrackedEntity(entityType string, entityID int64) (*models.TrackedEntity, error)
+	UpsertTrackedEntity(entity *models.TrackedEntity) error
+	DeleteTrackedEntity(entityType string, entityID int64) error
+
+	GetNamesByIDs(ids []int64) ([]models.Name, error)
+	UpsertNames(names []models.Name) error
+}
+
+// PriceRepository stores current market prices and the daily price
+// history.
+type PriceRepository interface {
+	UpsertMarketPrices(prices []models.MarketPrice) error
+	// GetPriceMap returns the price of every type keyed by type ID, using
+	// the average price and falling back to the adjusted price.
+	GetPriceMap() (map[int]float64, error)
+
+	UpsertPriceHistory(history []models.PriceHistory) error
+	// GetKillMarketTypeIDs returns every type that appears on a stored kill,
+	// as the victim's ship or among its items, including those inside
+	// containers, and is sold on the market. ESI has no market history for
+	// the other types.
+	GetKillMarketTypeIDs() ([]int, error)
+	// GetLatestHistoryDates returns the newest stored history day per type
+	// in a region.
+	GetLatestHistoryDates(regionID int) (map[int]time.Time, error)
+	// GetPriceHistory returns the history of typeIDs in a region between
+	// from and to, ordered by type and date.
+	GetPriceHistory(regionID int, typeIDs []int, from, to time.Time) ([]models.PriceHistory, error)
+}
+
+// EnrichmentRepository stores the queue of killmails to fetch from ESI.
+type EnrichmentRepository interface {
+	// EnqueueEnrichment queues the killmails for enrichment. Killmails
+	// already queued keep their job.
+	EnqueueEnrichment(killmailIDs []int64) error
+	// EnqueueUnenrichedZKills queues every Zkill without a stored kill and
+	// returns how many jobs were added.
+	EnqueueUnenrichedZKills() (int64, error)
+	// EnqueueKillsWithoutItems queues kills stored before their item tree
+	// was kept, i.e. kills with items but no kill_items rows, for another
+	// round of enrichment. Jobs already done are reset to pending. It
+	// returns how many jobs were queued.
+	EnqueueKillsWithoutItems() (int64, error)
+	// ClaimEnrichmentJobs moves up to limit due pending or failed jobs in
+	// flight and returns them. Concurrent workers never claim the same job.
+	ClaimEnrichmentJobs(limit int) ([]models.EnrichmentJob, error)
+	// ReleaseStaleEnrichmentJobs returns jobs claimed before claimedBefore,
+	// whose worker presumably died, to the failed state so they are retried.
+	ReleaseStaleEnrichmentJobs(claimedBefore time.Time) (int64, error)
+	MarkEnrichmentDone(killmailID int64) error
+	// MarkEnrichmentFailed records a failed attempt. state is
+	// models.EnrichmentFailed to retry at nextAttempt or
+	// models.EnrichmentPermanent to give up.
+	MarkEnrichmentFailed(killmailID int64, state string, nextAttempt time.Time, lastError string) error
+
+	// GetEnrichmentJobs returns a page of the jobs in state, or of all jobs
+	// if state is empty, most recently updated first.
+	GetEnrichmentJobs(state string, page, pageSize int) ([]models.EnrichmentJob, int64, error)
+	// GetEnrichmentStateCounts counts the jobs in every state.
+	GetEnrichmentStateCounts() ([]models.EnrichmentStateCount, error)
+	// RequeueEnrichmentJobs resets the given jobs, or every job in state
+	// when no IDs are given, to pending with no attempts. Jobs in flight or
+	// done are left alone.
+	RequeueEnrichmentJobs(killmailIDs []int64, state string) (int64, error)
+}
+
+// ESICacheRepository stores ESI responses by path. It implements
+// services.ESICache.
+type ESICacheRepository interface {
+	// Get returns the cached response, or nil when there is none.
+	Get(path string) (*models.ESICacheEntry, error)
+	Put(entry *models.ESICacheEntry) error
+}
+
+// Repositories bundles the repositories handed to routes and jobs.
+type Repositories struct {
+	Kills      KillRepository
+	ZKills     ZKillRepository
+	Characters CharacterRepository
+	Universe   UniverseRepository
+	Items      ItemRepository
+	Entities   EntityRepository
+	Prices     PriceRepository
+	Enrichment EnrichmentRepository
+	ESICache   ESICacheRepository
+}
+
+// New returns the repositories for the database behind conn.
+func New(conn *gorm.DB) *Repositories {
+	if db.IsSQLite(conn) {
+		return NewSQLite(conn)
+	}
+	return NewPostgres(conn)
+}
+
+// NewPostgres returns repositories running on Postgres.
+func NewPostgres(conn *gorm.DB) *Repositories {
+	return &Repositories{
+		Kills:      &postgresKills{db: conn},
+		ZKills:     &postgresZKills{db: conn},
+		Characters: &postgresCharacters{db: conn},
+		Universe:   &postgresUniverse{db: conn},
+		Items:      &postgresItems{db: conn},
+		Entities:   &postgresEntities{db: conn},
+		Prices:     &postgresPrices{db: conn},
+		Enrichment: &postgresEnrichment{db: conn},
+		ESICache:   &postgresESICache{db: conn},
+	}
+}
+
+// storedIDs returns the values of the primary key column of table.
+func storedIDs(conn *gorm.DB, table, column string) (map[int]bool, error) {
+	var ids []int
+	err := conn.Table(table).Pluck(column, &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		stored[id] = true
+	}
+	return stored, nil
+}
@@ -0,0 +1,376 @@
+package repository
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db"
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// Tracked characters of the test kills
+const (
+	pilotA int64 = 90000001
+	pilotB int64 = 90000002
+)
+
+// setupTestRepositories migrates a fresh SQLite database in the test's
+// temporary directory and returns the repositories on it.
+func setupTestRepositories(t *testing.T) *Repositories {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "eve-ran.db"))
+
+	db.InitDB()
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	repos := New(db.DB)
+	for _, id := range []int64{pilotA, pilotB} {
+		if err := repos.Characters.UpsertCharacter(&models.Character{ID: id}); err != nil {
+			t.Fatalf("UpsertCharacter: %v", err)
+		}
+	}
+	return repos
+}
+
+// testKill returns a kill of an untracked victim taking 1000 damage.
+func testKill(t *testing.T, killmailID int64, attackers ...models.Attacker) *models.Kill {
+	t.Helper()
+	data, err := json.Marshal(attackers)
+	if err != nil {
+		t.Fatalf("encoding attackers: %v", err)
+	}
+	return &models.Kill{
+		KillmailID:    killmailID,
+		KillmailTime:  time.Date(2025, 6, 1, 18, 0, 0, 0, time.UTC),
+		SolarSystemID: 30003830,
+		Victim: models.Victim{
+			CharacterID:   100,
+			CorporationID: 200,
+			DamageTaken:   1000,
+			ShipTypeID:    587,
+		},
+		Attackers: data,
+	}
+}
+
+// storeKill stores the kill with a Zkill worth value carrying labels.
+func storeKill(t *testing.T, repos *Repositories, kill *models.Kill, value float64, labels ...string) {
+	t.Helper()
+	err := repos.ZKills.UpsertZKills([]models.Zkill{{
+		KillmailID:  kill.KillmailID,
+		CharacterID: kill.CharacterID,
+		Role:        kill.Role,
+		TotalValue:  value,
+		Labels:      labels,
+	}})
+	if err != nil {
+		t.Fatalf("UpsertZKills: %v", err)
+	}
+	if err := repos.Kills.UpsertKill(kill); err != nil {
+		t.Fatalf("UpsertKill: %v", err)
+	}
+}
+
+func killmailIDs(kills []models.Kill) map[int64]bool {
+	ids := make(map[int64]bool, len(kills))
+	for _, kill := range kills {
+		ids[kill.KillmailID] = true
+	}
+	return ids
+}
+
+func TestUpsertKill(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	quantity := int64(2)
+	kill := testKill(t, 1,
+		models.Attacker{CharacterID: pilotA, DamageDone: 300},
+		models.Attacker{CharacterID: 555, DamageDone: 700, FinalBlow: true},
+	)
+	kill.CharacterID, kill.Role = pilotA, models.RoleAttacker
+	kill.Victim.Items = models.ItemArray{
+		{Flag: 5, ItemTypeID: 34, QuantityDropped: &quantity},
+		{Flag: 27, ItemTypeID: 3170, Singleton: 0, Items: []models.ZKillboardItem{{Flag: 27, ItemTypeID: 215, QuantityDestroyed: &quantity}}},
+	}
+	storeKill(t, repos, kill, 1000)
+
+	items, err := repos.Kills.GetKillItems(1)
+	if err != nil {
+		t.Fatalf("GetKillItems: %v", err)
+	}
+	if len(items) != 3 || items[2].ParentIndex == nil || *items[2].ParentIndex != 1 {
+		t.Errorf("stored items %+v, want 3 with the charge inside the launcher", items)
+	}
+	attackers, err := repos.Kills.GetKillAttackers(1)
+	if err != nil {
+		t.Fatalf("GetKillAttackers: %v", err)
+	}
+	if len(attackers) != 2 {
+		t.Errorf("stored %d attackers, want 2", len(attackers))
+	}
+	participants, err := repos.Kills.GetKillParticipants(1)
+	if err != nil {
+		t.Fatalf("GetKillParticipants: %v", err)
+	}
+	if len(participants) != 1 || participants[0].CharacterID != pilotA || participants[0].DamageDone != 300 {
+		t.Errorf("participants %+v, want only the tracked attacker", participants)
+	}
+
+	// Storing it again for another character replaces its rows but keeps
+	// the character it was first stored for
+	kill = testKill(t, 1, models.Attacker{CharacterID: pilotB, DamageDone: 1000, FinalBlow: true})
+	kill.CharacterID, kill.Role = pilotB, models.RoleAttacker
+	storeKill(t, repos, kill, 1000)
+
+	stored, err := repos.Kills.GetKillmail(1)
+	if err != nil || stored == nil {
+		t.Fatalf("GetKillmail = %v, %v", stored, err)
+	}
+	if stored.CharacterID != pilotA {
+		t.Errorf("kill credited to %d after the second upsert, want %d", stored.CharacterID, pilotA)
+	}
+	if stored.ZkillData.TotalValue != 1000 {
+		t.Errorf("kill loaded with zkill value %v, want 1000", stored.ZkillData.TotalValue)
+	}
+	if items, _ := repos.Kills.GetKillItems(1); len(items) != 0 {
+		t.Errorf("%d items left after storing the kill without items", len(items))
+	}
+	participants, err = repos.Kills.GetKillParticipants(1)
+	if err != nil {
+		t.Fatalf("GetKillParticipants: %v", err)
+	}
+	if len(participants) != 1 || participants[0].CharacterID != pilotB || !participants[0].FinalBlow {
+		t.Errorf("participants %+v, want only the new attacker", participants)
+	}
+}
+
+func TestKillFilterLabels(t *testing.T) {
+	repos := setupTestRepositories(t)
+	storeKill(t, repos, testKill(t, 1), 100, "solo", "pvp")
+	storeKill(t, repos, testKill(t, 2), 100, "pvp", "loc:nullsec")
+	storeKill(t, repos, testKill(t, 3), 100)
+
+	tests := []struct {
+		labels []string
+		want   []int64
+	}{
+		{[]string{"pvp"}, []int64{1, 2}},
+		{[]string{"solo"}, []int64{1}},
+		{[]string{"loc:nullsec"}, []int64{2}},
+		{[]string{"pvp", "loc:nullsec"}, []int64{2}},
+		{[]string{"solo", "loc:nullsec"}, nil},
+		// A label is only matched whole, not as part of another one
+		{[]string{"null"}, nil},
+	}
+	for _, tt := range tests {
+		kills, err := repos.Kills.GetKills(KillFilter{Labels: tt.labels})
+		if err != nil {
+			t.Fatalf("GetKills(%v): %v", tt.labels, err)
+		}
+		got := killmailIDs(kills)
+		if len(got) != len(tt.want) {
+			t.Errorf("labels %v matched %v, want %v", tt.labels, got, tt.want)
+			continue
+		}
+		for _, id := range tt.want {
+			if !got[id] {
+				t.Errorf("labels %v matched %v, want %v", tt.labels, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestGetCharacterStatsCountingModes(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	kill := testKill(t, 1,
+		models.Attacker{CharacterID: pilotA, DamageDone: 300},
+		models.Attacker{CharacterID: pilotB, DamageDone: 700, FinalBlow: true},
+	)
+	kill.CharacterID, kill.Role = pilotA, models.RoleAttacker
+	storeKill(t, repos, kill, 1000)
+
+	loss := testKill(t, 2, models.Attacker{CharacterID: 555, DamageDone: 1000, FinalBlow: true})
+	loss.Victim.CharacterID = pilotA
+	loss.CharacterID, loss.Role = pilotA, models.RoleVictim
+	storeKill(t, repos, loss, 500)
+
+	tests := []struct {
+		mode CountingMode
+		want map[int64]models.CharacterStats
+	}{
+		{CountParticipant, map[int64]models.CharacterStats{
+			pilotA: {KillCount: 1, TotalISK: 1000, LossCount: 1, LossISK: 500},
+			pilotB: {KillCount: 1, TotalISK: 1000},
+		}},
+		{CountFinalBlow, map[int64]models.CharacterStats{
+			pilotA: {KillCount: 0, TotalISK: 0, LossCount: 1, LossISK: 500},
+			pilotB: {KillCount: 1, TotalISK: 1000},
+		}},
+		{CountDamageShare, map[int64]models.CharacterStats{
+			pilotA: {KillCount: 1, TotalISK: 300, LossCount: 1, LossISK: 500},
+			pilotB: {KillCount: 1, TotalISK: 700},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			stats, err := repos.Kills.GetCharacterStats(KillFilter{}, ValueSourceZKill, tt.mode)
+			if err != nil {
+				t.Fatalf("GetCharacterStats: %v", err)
+			}
+			if len(stats) != len(tt.want) {
+				t.Fatalf("stats for %d characters, want %d", len(stats), len(tt.want))
+			}
+			for _, got := range stats {
+				want := tt.want[got.CharacterID]
+				if got.KillCount != want.KillCount || got.TotalISK != want.TotalISK ||
+					got.LossCount != want.LossCount || got.LossISK != want.LossISK {
+					t.Errorf("stats of %d = %+v, want %+v", got.CharacterID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetKillsRoleThroughParticipants(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	// Stored for pilotA's loss, but also a kill of pilotB
+	kill := testKill(t, 1, models.Attacker{CharacterID: pilotB, DamageDone: 1000, FinalBlow: true})
+	kill.Victim.CharacterID = pilotA
+	kill.CharacterID, kill.Role = pilotA, models.RoleVictim
+	storeKill(t, repos, kill, 1000)
+
+	for _, role := range []string{models.RoleAttacker, models.RoleVictim} {
+		kills, err := repos.Kills.GetKills(KillFilter{Role: role})
+		if err != nil {
+			t.Fatalf("GetKills(%s): %v", role, err)
+		}
+		if len(kills) != 1 {
+			t.Errorf("role %s matched %d kills, want 1", role, len(kills))
+		}
+	}
+}
+
+func TestGetCharacterKillmails(t *testing.T) {
+	repos := setupTestRepositories(t)
+	err := repos.Universe.BatchUpsertSystems([]*models.System{{SystemID: 30003830, RegionID: 10000048}})
+	if err != nil {
+		t.Fatalf("BatchUpsertSystems: %v", err)
+	}
+
+	// Stored for pilotA's loss, but also a kill of pilotB
+	kill := testKill(t, 1, models.Attacker{CharacterID: pilotB, DamageDone: 1000, FinalBlow: true})
+	kill.Victim.CharacterID = pilotA
+	kill.CharacterID, kill.Role = pilotA, models.RoleVictim
+	storeKill(t, repos, kill, 1000)
+
+	tests := []struct {
+		name        string
+		characterID int64
+		role        string
+		regionID    int64
+		want        int
+	}{
+		{"kill of the other participant", pilotB, models.RoleAttacker, 0, 1},
+		{"loss", pilotA, models.RoleVictim, 0, 1},
+		{"no kill of the victim", pilotA, models.RoleAttacker, 0, 0},
+		{"in the region", pilotB, models.RoleAttacker, 10000048, 1},
+		{"in another region", pilotB, models.RoleAttacker, 10000002, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kills, err := repos.Kills.GetCharacterKillmails(tt.characterID, tt.role, time.Time{}, time.Time{}, 0, tt.regionID)
+			if err != nil {
+				t.Fatalf("GetCharacterKillmails: %v", err)
+			}
+			if len(kills) != tt.want {
+				t.Errorf("found %d kills, want %d", len(kills), tt.want)
+			}
+		})
+	}
+}
+
+func TestUpsertRegionKeepsDescription(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	err := repos.Universe.UpsertRegion(&models.Region{RegionID: 10000048, Name: "Placid", Description: "Placid is quiet."})
+	if err != nil {
+		t.Fatalf("UpsertRegion: %v", err)
+	}
+	// The static data export has no descriptions
+	if err := repos.Universe.UpsertRegion(&models.Region{RegionID: 10000048, Name: "Placid"}); err != nil {
+		t.Fatalf("UpsertRegion: %v", err)
+	}
+
+	region, err := repos.Universe.GetRegionByID(10000048)
+	if err != nil || region == nil {
+		t.Fatalf("GetRegionByID = %v, %v", region, err)
+	}
+	if region.Description != "Placid is quiet." {
+		t.Errorf("region description %q after storing it without one", region.Description)
+	}
+}
+
+func TestUpsertKillResetsValueOfReplacedItems(t *testing.T) {
+	repos := setupTestRepositories(t)
+	kill := testKill(t, 1)
+	storeKill(t, repos, kill, 1000)
+
+	valued := func() bool {
+		t.Helper()
+		stored, err := repos.Kills.GetKillmail(1)
+		if err != nil || stored == nil {
+			t.Fatalf("GetKillmail = %v, %v", stored, err)
+		}
+		return stored.Value.ComputedAt != nil
+	}
+	setValue := func() {
+		t.Helper()
+		now := time.Now()
+		err := db.DB.Model(&models.Kill{}).Where("killmail_id = ?", 1).Updates(map[string]interface{}{
+			"value_total":       500,
+			"value_computed_at": &now,
+		}).Error
+		if err != nil {
+			t.Fatalf("valuing the kill: %v", err)
+		}
+	}
+
+	// Storing the same items again keeps the valuation
+	setValue()
+	storeKill(t, repos, testKill(t, 1), 1000)
+	if !valued() {
+		t.Error("valuation reset although the items did not change")
+	}
+
+	// Items decoded later void it
+	quantity := int64(1)
+	kill = testKill(t, 1)
+	kill.Victim.Items = models.ItemArray{{Flag: 5, ItemTypeID: 34, QuantityDropped: &quantity}}
+	storeKill(t, repos, kill, 1000)
+	if valued() {
+		t.Error("valuation kept after the items were replaced")
+	}
+
+	// As does a kill stored before items were decoded at all
+	setValue()
+	if err := db.DB.Exec("UPDATE kills SET victim_items = 'null' WHERE killmail_id = 1").Error; err != nil {
+		t.Fatalf("clearing items: %v", err)
+	}
+	storeKill(t, repos, kill, 1000)
+	if valued() {
+		t.Error("valuation kept after items were decoded for a kill stored without them")
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+)
+
+// NewSQLite returns repositories running on a SQLite file. They share the
+// Postgres queries except where those use jsonb or row locks; JSON columns
+// are stored as blobs there and read with the json1 functions.
+func NewSQLite(conn *gorm.DB) *Repositories {
+	return &Repositories{
+		Kills:      &postgresKills{db: conn},
+		ZKills:     &postgresZKills{db: conn},
+		Characters: &postgresCharacters{db: conn},
+		Universe:   &sqliteUniverse{postgresUniverse{db: conn}},
+		Items:      &postgresItems{db: conn},
+		Entities:   &postgresEntities{db: conn},
+		Prices:     &postgresPrices{db: conn},
+		Enrichment: &sqliteEnrichment{postgresEnrichment{db: conn}},
+		ESICache:   &postgresESICache{db: conn},
+	}
+}
+
+type sqliteEnrichment struct {
+	postgresEnrichment
+}
+
+// ClaimEnrichmentJobs locks no rows, as SQLite has no row locks but runs one
+// write at a time, which gives the same guarantee.
+func (r *sqliteEnrichment) ClaimEnrichmentJobs(limit int) ([]models.EnrichmentJob, error) {
+	return r.claim(limit, "")
+}
+
+type sqliteUniverse struct {
+	postgresUniverse
+}
+
+func (r *sqliteUniverse) GetSystemStargateIDs() ([]int, error) {
+	var ids []int
+	err := r.db.Raw(`
+		SELECT DISTINCT CAST(stargate.value AS integer)
+		FROM systems, json_each(CASE
+			WHEN json_valid(systems.stargates) AND json_type(CAST(systems.stargates AS TEXT)) = 'array'
+			THEN CAST(systems.stargates AS TEXT) ELSE '[]' END) AS stargate
+	`).Scan(&ids).Error
+	return ids, err
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresUniverse struct {
+	db *gorm.DB
+}
+
+func (r *postgresUniverse) GetAllRegions() ([]models.Region, error) {
+	var regions []models.Region
+	err := r.db.Find(&regions).Error
+	if err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+func (r *postgresUniverse) GetRegionByID(regionID int) (*models.Region, error) {
+	var region models.Region
+	err := r.db.First(&region, regionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+// UpsertRegion keeps the stored description when region has none, as is
+// the case for regions imported from the static data export.
+func (r *postgresUniverse) UpsertRegion(region *models.Region) error {
+	constellationsJSON, err := json.Marshal(region.Constellations)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Exec(`
+        INSERT INTO regions (region_id, name, description, constellations)
+        VALUES (?, ?, ?, ?)
+        ON CONFLICT (region_id) DO UPDATE
+        SET name = EXCLUDED.name,
+            description = COALESCE(NULLIF(EXCLUDED.description, ''), regions.description),
+            constellations = EXCLUDED.constellations
+    `, region.RegionID, region.Name, region.Description, constellationsJSON).Error
+}
+
+func (r *postgresUniverse) GetAllConstellations() ([]models.Constellation, error) {
+	var constellations []models.Constellation
+	err := r.db.Find(&constellations).Error
+	return constellations, err
+}
+
+func (r *postgresUniverse) GetConstellationByID(id int) (*models.Constellation, error) {
+	var constellation models.Constellation
+	result := r.db.First(&constellation, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &constellation, nil
+}
+
+func (r *postgresUniverse) GetConstellationsByRegionID(regionID int) ([]models.Constellation, error) {
+	var constellations []models.Constellation
+	result := r.db.Where("region_id = ?", regionID).Find(&constellations)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return constellations, nil
+}
+
+func (r *postgresUniverse) BatchUpsertConstellations(constellations []*models.Constellation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, constellation := range constellations {
+			systemsJSON, err := json.Marshal(constellation.Systems)
+			if err != nil {
+				return err
+			}
+
+			err = tx.Exec(`
+				INSERT INTO constellations (constellation_id, name, region_id, systems, position)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (constellation_id) DO UPDATE
+				SET name = EXCLUDED.name,
+					region_id = EXCLUDED.region_id,
+					systems = EXCLUDED.systems,
+					position = EXCLUDED.position
+			`, constellation.ConstellationID, constellation.Name, constellation.RegionID, systemsJSON, constellation.Position).Error
+
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *postgresUniverse) GetAllSystems() ([]models.System, error) {
+	var systems []models.System
+	err := r.db.Find(&systems).Error
+	return systems, err
+}
+
+func (r *postgresUniverse) GetSystemByID(systemID int) (*models.System, error) {
+	var system models.System
+	err := r.db.First(&system, systemID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &system, nil
+}
+
+func (r *postgresUniverse) GetSystemsByRegionID(regionID int) ([]models.System, error) {
+	var systems []models.System
+	err := r.db.Where("region_id = ?", regionID).Find(&systems).Error
+	return systems, err
+}
+
+func (r *postgresUniverse) GetSolarSystemIDsByRegion(regionID int) ([]int, error) {
+	var systems []models.System
+	result := r.db.Where("region_id = ?", regionID).Select("system_id").Find(&systems)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var systemIDs []int
+	for _, system := range systems {
+		systemIDs = append(systemIDs, system.SystemID)
+	}
+
+	return systemIDs, nil
+}
+
+func (r *postgresUniverse) GetSystemsForGraph() ([]models.System, error) {
+	var systems []models.System
+	err := r.db.Select("system_id", "constellation_id", "region_id", "name", "security_status").Find(&systems).Error
+	return systems, err
+}
+
+func (r *postgresUniverse) BatchUpsertSystems(systems []*models.System) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, system := range systems {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "system_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"constellation_id", "region_id", "name", "security_class", "security_status", "star_id", "planets", "stargates", "stations", "position"}),
+			}).Create(system).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *postgresUniverse) GetAllStargates() ([]models.Stargate, error) {
+	var stargates []models.Stargate
+	err := r.db.Find(&stargates).Error
+	return stargates, err
+}
+
+func (r *postgresUniverse) GetSystemStargateIDs() ([]int, error) {
+	var ids []int
+	err := r.db.Raw(`
+		SELECT DISTINCT CAST(stargate AS integer)
+		FROM systems, jsonb_array_elements_text(systems.stargates) AS stargate
+		WHERE jsonb_typeof(systems.stargates) = 'array'
+	`).Scan(&ids).Error
+	return ids, err
+}
+
+func (r *postgresUniverse) BatchUpsertStargates(stargates []*models.Stargate) error {
+	if len(stargates) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "stargate_id"}},
+		UpdateAll: true,
+	}).CreateInBatches(stargates, 1000).Error
+}
+
+func (r *postgresUniverse) GetStoredIDs(table, column string) (map[int]bool, error) {
+	return storedIDs(r.db, table, column)
+}
+
+func (r *postgresUniverse) GetCurrentSystemControl() (map[int]models.SystemControl, error) {
+	var rows []models.SystemControl
+	if err := r.db.Where("end_date IS NULL").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	current := make(map[int]models.SystemControl, len(rows))
+	for _, row := range rows {
+		current[row.SystemID] = row
+	}
+	return current, nil
+}
+
+func (r *postgresUniverse) GetSystemControlHistory(systemID int) ([]models.SystemControl, error) {
+	var history []models.SystemControl
+	err := r.db.Where("system_id = ?", systemID).Order("start_date").Find(&history).Error
+	return history, err
+}
+
+func (r *postgresUniverse) RecordSystemControl(changes []models.SystemControl, at time.Time) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	systemIDs := make([]int, len(changes))
+	for i := range changes {
+		systemIDs[i] = changes[i].SystemID
+		changes[i].ID = 0
+		changes[i].StartDate = at
+		changes[i].EndDate = nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.SystemControl{}).
+			Where("system_id IN ? AND end_date IS NULL", systemIDs).
+			Update("end_date", at).Error
+		if err != nil {
+			return err
+		}
+		return tx.CreateInBatches(changes, 1000).Error
+	})
+}
+
+func (r *postgresUniverse) GetTrackedRegions() ([]models.TrackedRegion, error) {
+	var regions []models.TrackedRegion
+	err := r.db.Order("region_id").Find(&regions).Error
+	return regions, err
+}
+
+func (r *postgresUniverse) GetTrackedRegion(regionID int) (*models.TrackedRegion, error) {
+	var region models.TrackedRegion
+	err := r.db.First(&region, regionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+func (r *postgresUniverse) UpsertTrackedRegion(region *models.TrackedRegion) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "region_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(region).Error
+}
+
+func (r *postgresUniverse) DeleteTrackedRegion(regionID int) error {
+	return r.db.Delete(&models.TrackedRegion{}, regionID).Error
+}
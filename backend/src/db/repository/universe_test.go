@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// storeUniverse stores Placid with the constellation of Gallusiaux and
+// Ravarin, which a stargate pair links, and Jita in The Forge.
+func storeUniverse(t *testing.T, repos *Repositories) {
+	t.Helper()
+	regions := []*models.Region{
+		{RegionID: 10000048, Name: "Placid", Constellations: json.RawMessage("[20000561]")},
+		{RegionID: 10000002, Name: "The Forge", Constellations: json.RawMessage("[20000020]")},
+	}
+	for _, region := range regions {
+		if err := repos.Universe.UpsertRegion(region); err != nil {
+			t.Fatalf("UpsertRegion: %v", err)
+		}
+	}
+	err := repos.Universe.BatchUpsertConstellations([]*models.Constellation{
+		{ConstellationID: 20000561, Name: "Ancbeu", RegionID: 10000048, Systems: json.RawMessage("[30003830,30003831]")},
+		{ConstellationID: 20000020, Name: "Kimotoro", RegionID: 10000002, Systems: json.RawMessage("[30000142]")},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertConstellations: %v", err)
+	}
+	err = repos.Universe.BatchUpsertSystems([]*models.System{
+		{SystemID: 30003830, ConstellationID: 20000561, RegionID: 10000048, Name: "Gallusiaux", SecurityStatus: 0.28,
+			Planets: json.RawMessage(`[{"planet_id":40242251}]`), Stargates: json.RawMessage("[50000001]")},
+		{SystemID: 30003831, ConstellationID: 20000561, RegionID: 10000048, Name: "Ravarin", SecurityStatus: 0.3,
+			Stargates: json.RawMessage("[50000002]")},
+		{SystemID: 30000142, ConstellationID: 20000020, RegionID: 10000002, Name: "Jita", SecurityStatus: 0.95,
+			Stargates: json.RawMessage("null")},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertSystems: %v", err)
+	}
+	err = repos.Universe.BatchUpsertStargates([]*models.Stargate{
+		{StargateID: 50000001, Name: "Stargate (Ravarin)", SystemID: 30003830, DestinationStargateID: 50000002, DestinationSystemID: 30003831},
+		{StargateID: 50000002, Name: "Stargate (Gallusiaux)", SystemID: 30003831, DestinationStargateID: 50000001, DestinationSystemID: 30003830},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertStargates: %v", err)
+	}
+}
+
+func TestUniverseRegions(t *testing.T) {
+	repos := setupTestRepositories(t)
+	storeUniverse(t, repos)
+
+	regions, err := repos.Universe.GetAllRegions()
+	if err != nil || len(regions) != 2 {
+		t.Fatalf("GetAllRegions = %d regions, %v, want 2", len(regions), err)
+	}
+	region, err := repos.Universe.GetRegionByID(10000048)
+	if err != nil || region == nil || region.Name != "Placid" || string(region.Constellations) != "[20000561]" {
+		t.Errorf("GetRegionByID = %+v, %v, want Placid", region, err)
+	}
+	if region, err := repos.Universe.GetRegionByID(1); region != nil || err != nil {
+		t.Errorf("GetRegionByID of an unknown region = %+v, %v, want nil", region, err)
+	}
+}
+
+func TestUniverseConstellations(t *testing.T) {
+	repos := setupTestRepositories(t)
+	storeUniverse(t, repos)
+
+	constellations, err := repos.Universe.GetAllConstellations()
+	if err != nil || len(constellations) != 2 {
+		t.Fatalf("GetAllConstellations = %d constellations, %v, want 2", len(constellations), err)
+	}
+	constellation, err := repos.Universe.GetConstellationByID(20000561)
+	if err != nil || constellation.Name != "Ancbeu" || string(constellation.Systems) != "[30003830,30003831]" {
+		t.Errorf("GetConstellationByID = %+v, %v, want Ancbeu", constellation, err)
+	}
+	inRegion, err := repos.Universe.GetConstellationsByRegionID(10000002)
+	if err != nil || len(inRegion) != 1 || inRegion[0].ConstellationID != 20000020 {
+		t.Errorf("GetConstellationsByRegionID = %+v, %v, want Kimotoro", inRegion, err)
+	}
+
+	// Upserting renames it
+	err = repos.Universe.BatchUpsertConstellations([]*models.Constellation{
+		{ConstellationID: 20000561, Name: "Renamed", RegionID: 10000048, Systems: json.RawMessage("[30003830]")},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertConstellations: %v", err)
+	}
+	if constellation, _ := repos.Universe.GetConstellationByID(20000561); constellation.Name != "Renamed" {
+		t.Errorf("constellation named %q after the upsert, want Renamed", constellation.Name)
+	}
+}
+
+func TestUniverseSystems(t *testing.T) {
+	repos := setupTestRepositories(t)
+	storeUniverse(t, repos)
+
+	systems, err := repos.Universe.GetAllSystems()
+	if err != nil || len(systems) != 3 {
+		t.Fatalf("GetAllSystems = %d systems, %v, want 3", len(systems), err)
+	}
+	system, err := repos.Universe.GetSystemByID(30003830)
+	if err != nil || system.Name != "Gallusiaux" || string(system.Planets) != `[{"planet_id":40242251}]` {
+		t.Errorf("GetSystemByID = %+v, %v, want Gallusiaux with its planet", system, err)
+	}
+	inRegion, err := repos.Universe.GetSystemsByRegionID(10000048)
+	if err != nil || len(inRegion) != 2 {
+		t.Errorf("GetSystemsByRegionID = %d systems, %v, want 2", len(inRegion), err)
+	}
+	ids, err := repos.Universe.GetSolarSystemIDsByRegion(10000048)
+	sort.Ints(ids)
+	if err != nil || !reflect.DeepEqual(ids, []int{30003830, 30003831}) {
+		t.Errorf("GetSolarSystemIDsByRegion = %v, %v, want both Placid systems", ids, err)
+	}
+
+	graph, err := repos.Universe.GetSystemsForGraph()
+	if err != nil || len(graph) != 3 {
+		t.Fatalf("GetSystemsForGraph = %d systems, %v, want 3", len(graph), err)
+	}
+	for _, system := range graph {
+		if system.Name == "" || system.RegionID == 0 || system.SecurityStatus == 0 || system.Planets != nil {
+			t.Errorf("graph system %+v, want its name, region and security without celestials", system)
+		}
+	}
+}
+
+func TestUniverseStargates(t *testing.T) {
+	repos := setupTestRepositories(t)
+	storeUniverse(t, repos)
+
+	stargates, err := repos.Universe.GetAllStargates()
+	if err != nil || len(stargates) != 2 {
+		t.Fatalf("GetAllStargates = %d stargates, %v, want 2", len(stargates), err)
+	}
+
+	// Jita lists no stargates as JSON null, which is skipped
+	ids, err := repos.Universe.GetSystemStargateIDs()
+	sort.Ints(ids)
+	if err != nil || !reflect.DeepEqual(ids, []int{50000001, 50000002}) {
+		t.Errorf("GetSystemStargateIDs = %v, %v, want both stargates", ids, err)
+	}
+}
+
+func TestUniverseGetStoredIDs(t *testing.T) {
+	repos := setupTestRepositories(t)
+	storeUniverse(t, repos)
+
+	stored, err := repos.Universe.GetStoredIDs("systems", "system_id")
+	if err != nil {
+		t.Fatalf("GetStoredIDs: %v", err)
+	}
+	if len(stored) != 3 || !stored[30000142] || stored[1] {
+		t.Errorf("GetStoredIDs = %v, want the 3 systems", stored)
+	}
+}
+
+func TestSystemControl(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	handover := start.Add(24 * time.Hour)
+	err := repos.Universe.RecordSystemControl([]models.SystemControl{
+		{SystemID: 1, SovAllianceID: 10},
+		{SystemID: 2, FWOwnerFactionID: 500001, FWOccupierFactionID: 500001},
+	}, start)
+	if err != nil {
+		t.Fatalf("RecordSystemControl: %v", err)
+	}
+	if err := repos.Universe.RecordSystemControl([]models.SystemControl{{SystemID: 1, SovAllianceID: 20}}, handover); err != nil {
+		t.Fatalf("RecordSystemControl: %v", err)
+	}
+
+	current, err := repos.Universe.GetCurrentSystemControl()
+	if err != nil {
+		t.Fatalf("GetCurrentSystemControl: %v", err)
+	}
+	if len(current) != 2 || current[1].SovAllianceID != 20 || current[2].FWOccupierFactionID != 500001 {
+		t.Errorf("GetCurrentSystemControl = %+v, want the new holder of 1 and the occupier of 2", current)
+	}
+
+	history, err := repos.Universe.GetSystemControlHistory(1)
+	if err != nil {
+		t.Fatalf("GetSystemControlHistory: %v", err)
+	}
+	if len(history) != 2 || history[0].SovAllianceID != 10 || history[1].SovAllianceID != 20 {
+		t.Fatalf("GetSystemControlHistory = %+v, want both holders oldest first", history)
+	}
+	if history[0].EndDate == nil || !history[0].EndDate.Equal(handover) || history[1].EndDate != nil {
+		t.Errorf("holders end at %v and %v, want the handover and open", history[0].EndDate, history[1].EndDate)
+	}
+}
+
+func TestTrackedRegions(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	for _, region := range []models.TrackedRegion{{RegionID: 10000048, Name: "Placid"}, {RegionID: 10000002, Name: "The Forge"}} {
+		if err := repos.Universe.UpsertTrackedRegion(&region); err != nil {
+			t.Fatalf("UpsertTrackedRegion: %v", err)
+		}
+	}
+	if err := repos.Universe.UpsertTrackedRegion(&models.TrackedRegion{RegionID: 10000048, Name: "Renamed"}); err != nil {
+		t.Fatalf("UpsertTrackedRegion: %v", err)
+	}
+
+	regions, err := repos.Universe.GetTrackedRegions()
+	if err != nil || len(regions) != 2 || regions[0].RegionID != 10000002 {
+		t.Fatalf("GetTrackedRegions = %+v, %v, want both ordered by ID", regions, err)
+	}
+	region, err := repos.Universe.GetTrackedRegion(10000048)
+	if err != nil || region == nil || region.Name != "Renamed" {
+		t.Errorf("GetTrackedRegion = %+v, %v, want the renamed region", region, err)
+	}
+
+	if err := repos.Universe.DeleteTrackedRegion(10000048); err != nil {
+		t.Fatalf("DeleteTrackedRegion: %v", err)
+	}
+	if region, err := repos.Universe.GetTrackedRegion(10000048); region != nil || err != nil {
+		t.Errorf("GetTrackedRegion after deleting = %+v, %v, want nil", region, err)
+	}
+}
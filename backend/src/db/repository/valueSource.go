@@ -0,0 +1,20 @@
+package repository
+
+// ValueSource selects which kill valuation ISK aggregates are summed from.
+type ValueSource string
+
+const (
+	// ValueSourceZKill uses zKillboard's total value.
+	ValueSourceZKill ValueSource = "zkill"
+	// ValueSourceOwn uses our own valuation from market prices.
+	ValueSourceOwn ValueSource = "own"
+)
+
+// totalColumn is the column holding the total value of a kill. Queries
+// using it must LEFT JOIN zkills on the killmail ID.
+func (s ValueSource) totalColumn() string {
+	if s == ValueSourceOwn {
+		return "kills.value_total"
+	}
+	return "zkills.total_value"
+}
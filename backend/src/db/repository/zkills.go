@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresZKills struct {
+	db *gorm.DB
+}
+
+func (r *postgresZKills) ZKillExists(killmailID int64) (bool, error) {
+	var count int64
+	result := r.db.Model(&models.Zkill{}).Where("killmail_id = ?", killmailID).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (r *postgresZKills) GetZKillByID(killmailID int64) (*models.Zkill, error) {
+	var zkill models.Zkill
+	result := r.db.Where("killmail_id = ?", killmailID).First(&zkill)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &zkill, nil
+}
+
+// UpsertZKills keeps the first character and role of a killmail, so the
+// order feeds are synced in does not matter; kill_participants credits every
+// tracked character.
+func (r *postgresZKills) UpsertZKills(zkills []models.Zkill) error {
+	updates := clause.AssignmentColumns([]string{"location_id", "hash", "fitted_value", "dropped_value", "destroyed_value", "total_value", "points", "npc", "solo", "awox", "labels"})
+	updates = append(updates,
+		clause.Assignment{Column: clause.Column{Name: "character_id"}, Value: gorm.Expr("CASE WHEN zkills.character_id = 0 THEN EXCLUDED.character_id ELSE zkills.character_id END")},
+		clause.Assignment{Column: clause.Column{Name: "role"}, Value: gorm.Expr("CASE WHEN zkills.character_id = 0 THEN EXCLUDED.role ELSE zkills.role END")},
+	)
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "killmail_id"}},
+		DoUpdates: updates,
+	}).Create(&zkills).Error
+}
+
+func (r *postgresZKills) GetZKillsWithoutLabels(limit int) ([]int64, error) {
+	var ids []int64
+	err := r.db.Model(&models.Zkill{}).Where("labels IS NULL").Order("killmail_id DESC").Limit(limit).Pluck("killmail_id", &ids).Error
+	return ids, err
+}
+
+func (r *postgresZKills) UpdateZKillLabels(killmailID int64, solo, awox bool, labels models.StringArray) error {
+	return r.db.Model(&models.Zkill{}).Where("killmail_id = ?", killmailID).
+		Updates(map[string]interface{}{"solo": solo, "awox": awox, "labels": labels}).Error
+}
+
+func (r *postgresZKills) GetZKillCursor(path string) (*models.ZKillCursor, error) {
+	var cursor models.ZKillCursor
+	err := r.db.Where("path = ?", path).First(&cursor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.ZKillCursor{Path: path}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func (r *postgresZKills) SaveZKillCursor(cursor *models.ZKillCursor) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_killmail_id", "last_page", "backfill_done", "updated_at"}),
+	}).Create(cursor).Error
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tadeasf/eve-ran/src/db"
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func TestZKills(t *testing.T) {
+	repos := setupTestRepositories(t)
+
+	err := repos.ZKills.UpsertZKills([]models.Zkill{
+		{KillmailID: 1, CharacterID: pilotA, Role: models.RoleAttacker, Hash: "a", TotalValue: 100},
+		{KillmailID: 2, Hash: "b", TotalValue: 200},
+		{KillmailID: 3, Hash: "c", TotalValue: 300, Labels: models.StringArray{"pvp"}},
+	})
+	if err != nil {
+		t.Fatalf("UpsertZKills: %v", err)
+	}
+	// Synced again from pilotB's feed it keeps pilotA but takes the new
+	// values
+	err = repos.ZKills.UpsertZKills([]models.Zkill{{KillmailID: 1, CharacterID: pilotB, Role: models.RoleVictim, Hash: "a", TotalValue: 150}})
+	if err != nil {
+		t.Fatalf("UpsertZKills: %v", err)
+	}
+
+	if exists, err := repos.ZKills.ZKillExists(1); !exists || err != nil {
+		t.Errorf("ZKillExists(1) = %v, %v, want true", exists, err)
+	}
+	if exists, err := repos.ZKills.ZKillExists(4); exists || err != nil {
+		t.Errorf("ZKillExists(4) = %v, %v, want false", exists, err)
+	}
+	zkill, err := repos.ZKills.GetZKillByID(1)
+	if err != nil || zkill.CharacterID != pilotA || zkill.Role != models.RoleAttacker || zkill.TotalValue != 150 {
+		t.Errorf("GetZKillByID = %+v, %v, want pilotA's kill at the new value", zkill, err)
+	}
+
+	// 1 and 2 were stored before zkb labels were parsed
+	if err := db.DB.Exec("UPDATE zkills SET labels = NULL WHERE killmail_id IN (1, 2)").Error; err != nil {
+		t.Fatalf("clearing labels: %v", err)
+	}
+	without, err := repos.ZKills.GetZKillsWithoutLabels(10)
+	if err != nil || !reflect.DeepEqual(without, []int64{2, 1}) {
+		t.Fatalf("GetZKillsWithoutLabels = %v, %v, want 2 and 1, newest first", without, err)
+	}
+	if limited, _ := repos.ZKills.GetZKillsWithoutLabels(1); len(limited) != 1 {
+		t.Errorf("GetZKillsWithoutLabels(1) = %v, want one ID", limited)
+	}
+
+	if err := repos.ZKills.UpdateZKillLabels(2, true, false, models.StringArray{"solo", "pvp"}); err != nil {
+		t.Fatalf("UpdateZKillLabels: %v", err)
+	}
+	zkill, err = repos.ZKills.GetZKillByID(2)
+	if err != nil || !zkill.Solo || zkill.Awox || !reflect.DeepEqual(zkill.Labels, models.StringArray{"solo", "pvp"}) {
+		t.Errorf("GetZKillByID after UpdateZKillLabels = %+v, %v, want solo with both labels", zkill, err)
+	}
+	if without, _ := repos.ZKills.GetZKillsWithoutLabels(10); !reflect.DeepEqual(without, []int64{1}) {
+		t.Errorf("GetZKillsWithoutLabels after labelling = %v, want only 1", without)
+	}
+}
+
+func TestZKillCursors(t *testing.T) {
+	repos := setupTestRepositories(t)
+	const path = "kills/characterID/90000001"
+
+	cursor, err := repos.ZKills.GetZKillCursor(path)
+	if err != nil || cursor.Path != path || cursor.LastKillmailID != 0 || cursor.BackfillDone {
+		t.Fatalf("GetZKillCursor of a new list = %+v, %v, want a fresh cursor", cursor, err)
+	}
+
+	cursor.LastKillmailID, cursor.LastPage = 100, 3
+	if err := repos.ZKills.SaveZKillCursor(cursor); err != nil {
+		t.Fatalf("SaveZKillCursor: %v", err)
+	}
+	cursor.LastKillmailID, cursor.BackfillDone = 200, true
+	if err := repos.ZKills.SaveZKillCursor(cursor); err != nil {
+		t.Fatalf("SaveZKillCursor: %v", err)
+	}
+
+	stored, err := repos.ZKills.GetZKillCursor(path)
+	if err != nil || stored.LastKillmailID != 200 || stored.LastPage != 3 || !stored.BackfillDone {
+		t.Errorf("GetZKillCursor = %+v, %v, want the second save", stored, err)
+	}
+}
@@ -0,0 +1,90 @@
+package fakeupstream
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// redisQPath is where the fake RedisQ listen.php endpoint is served.
+const redisQPath = "/redisq/listen.php"
+
+// maxRedisQWait caps the ttw a client may ask for, as RedisQ does.
+const maxRedisQWait = 10 * time.Second
+
+// Queue is an in-memory RedisQ. Every listener shares one queue regardless
+// of queueID, which is enough for a single consumer.
+type Queue struct {
+	mu       sync.Mutex
+	packages []json.RawMessage
+	notify   chan struct{}
+}
+
+// newQueue returns a queue preloaded with the packages in redisq/*.json,
+// in file name order.
+func newQueue(fixtures fs.FS) *Queue {
+	q := &Queue{notify: make(chan struct{})}
+	names, _ := fs.Glob(fixtures, "redisq/*.json")
+	sort.Strings(names)
+	for _, name := range names {
+		if data, err := fs.ReadFile(fixtures, name); err == nil {
+			q.packages = append(q.packages, json.RawMessage(data))
+		}
+	}
+	return q
+}
+
+// Push appends a package, as found under "package" in a RedisQ response,
+// and wakes up a waiting listener.
+func (q *Queue) Push(pkg json.RawMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.packages = append(q.packages, pkg)
+	close(q.notify)
+	q.notify = make(chan struct{})
+}
+
+// pop removes the oldest package, or returns a channel closed on the next
+// push when the queue is empty.
+func (q *Queue) pop() (json.RawMessage, <-chan struct{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.packages) == 0 {
+		return nil, q.notify
+	}
+	pkg := q.packages[0]
+	q.packages = q.packages[1:]
+	return pkg, nil
+}
+
+// ServeHTTP answers like listen.php: with the next package, or with a null
+// package once ttw seconds passed without one.
+func (q *Queue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wait := maxRedisQWait
+	if ttw, err := strconv.Atoi(r.URL.Query().Get("ttw")); err == nil && ttw >= 1 && time.Duration(ttw)*time.Second < wait {
+		wait = time.Duration(ttw) * time.Second
+	}
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	w.Header().Set("Content-Type", "application/json")
+	for {
+		pkg, pushed := q.pop()
+		if pkg != nil {
+			json.NewEncoder(w).Encode(map[string]json.RawMessage{"package": pkg})
+			return
+		}
+		select {
+		case <-pushed:
+		case <-timeout.C:
+			w.Write([]byte(`{"package":null}`))
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
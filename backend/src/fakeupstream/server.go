@@ -0,0 +1,130 @@
+// Package fakeupstream serves ESI and zKillboard fixtures from an in-process
+// HTTP server, so ingestion can run end to end without network access.
+//
+// A request path maps directly onto a fixture file: ESI requests are served
+// from esi/<path>.json and zKillboard requests from zkill/<path>.json, with
+// query strings ignored. For example GET /esi/killmails/1/abc/ is answered
+// with esi/killmails/1/abc.json.
+//
+// /redisq/listen.php behaves like zKillboard's RedisQ, handing out the
+// packages in redisq/*.json one per request and then whatever is pushed onto
+// the Queue.
+package fakeupstream
+
+import (
+	"crypto/sha1"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+//go:embed fixtures
+var embedded embed.FS
+
+// Fixtures returns the fixtures bundled with the package.
+func Fixtures() fs.FS {
+	fixtures, _ := fs.Sub(embedded, "fixtures")
+	return fixtures
+}
+
+// Handler serves fixtures and records every requested path.
+type Handler struct {
+	fixtures fs.FS
+	queue    *Queue
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func NewHandler(fixtures fs.FS) *Handler {
+	return &Handler{fixtures: fixtures, queue: newQueue(fixtures)}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.requests = append(h.requests, r.URL.Path)
+	h.mu.Unlock()
+
+	if r.URL.Path == redisQPath {
+		h.queue.ServeHTTP(w, r)
+		return
+	}
+
+	name := strings.Trim(r.URL.Path, "/")
+	data, err := fs.ReadFile(h.fixtures, name+".json")
+	if err != nil {
+		if strings.HasPrefix(name, "zkill/") {
+			// zKillboard answers past the last page with an empty list
+			data = []byte("[]")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error":"fixture %s not found"}`, name)
+			return
+		}
+	}
+
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(data))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	w.Header().Set("X-ESI-Error-Limit-Remain", "100")
+	w.Header().Set("X-ESI-Error-Limit-Reset", "60")
+	w.Header().Set("X-Pages", "1")
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(data)
+}
+
+// Queue returns the fake RedisQ queue.
+func (h *Handler) Queue() *Queue {
+	return h.queue
+}
+
+// Requests returns the paths requested so far, in order.
+func (h *Handler) Requests() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.requests...)
+}
+
+// Server is a running fake upstream.
+type Server struct {
+	*httptest.Server
+	*Handler
+}
+
+// New starts a server backed by the bundled fixtures.
+func New() *Server {
+	return NewWithFixtures(Fixtures())
+}
+
+// NewWithFixtures starts a server backed by the given fixture tree.
+func NewWithFixtures(fixtures fs.FS) *Server {
+	handler := NewHandler(fixtures)
+	return &Server{Server: httptest.NewServer(handler), Handler: handler}
+}
+
+// Upstreams returns base URLs that point the backend at this server.
+func (s *Server) Upstreams() services.Upstreams {
+	return UpstreamsFor(s.URL)
+}
+
+// UpstreamsFor returns base URLs for a fake upstream listening on baseURL.
+func UpstreamsFor(baseURL string) services.Upstreams {
+	return services.Upstreams{
+		ESIBaseURL:   baseURL + "/esi",
+		ZKillBaseURL: baseURL + "/zkill",
+		RedisQURL:    baseURL + redisQPath,
+	}
+}
@@ -0,0 +1,148 @@
+// Package graph holds the stargate network of New Eden in memory and answers
+// jump distance questions on it.
+package graph
+
+import "github.com/tadeasf/eve-ran/src/db/models"
+
+// SecurityBand is the coarse security classification shown in game.
+type SecurityBand string
+
+const (
+	HighSec SecurityBand = "high"
+	LowSec  SecurityBand = "low"
+	NullSec SecurityBand = "null"
+)
+
+// Band returns the security band of a raw security status. The game rounds
+// the status to one decimal, so 0.45 already counts as high security.
+func Band(securityStatus float64) SecurityBand {
+	switch {
+	case securityStatus >= 0.45:
+		return HighSec
+	case securityStatus > 0:
+		return LowSec
+	default:
+		return NullSec
+	}
+}
+
+// Graph is an undirected stargate graph.
+type Graph struct {
+	systems map[int]models.System
+	gates   map[int][]int
+}
+
+// New builds a graph from systems and the stargates between them. Gates
+// leading to unknown systems are ignored.
+func New(systems []models.System, stargates []models.Stargate) *Graph {
+	g := &Graph{
+		systems: make(map[int]models.System, len(systems)),
+		gates:   make(map[int][]int, len(systems)),
+	}
+	for _, system := range systems {
+		g.systems[system.SystemID] = system
+	}
+
+	linked := make(map[[2]int]bool, len(stargates))
+	for _, gate := range stargates {
+		from, to := gate.SystemID, gate.DestinationSystemID
+		if _, ok := g.systems[from]; !ok {
+			continue
+		}
+		if _, ok := g.systems[to]; !ok {
+			continue
+		}
+		if from > to {
+			from, to = to, from
+		}
+		if linked[[2]int{from, to}] {
+			continue
+		}
+		linked[[2]int{from, to}] = true
+		g.gates[from] = append(g.gates[from], to)
+		g.gates[to] = append(g.gates[to], from)
+	}
+	return g
+}
+
+// System returns the system with the given ID.
+func (g *Graph) System(systemID int) (models.System, bool) {
+	system, ok := g.systems[systemID]
+	return system, ok
+}
+
+// Avoid reports systems a search must not pass through.
+type Avoid func(system models.System) bool
+
+// Route returns the systems on a shortest path from from to to, both
+// included, or nil if there is none. The end points are never avoided.
+func (g *Graph) Route(from, to int, avoid Avoid) []int {
+	if _, ok := g.systems[from]; !ok {
+		return nil
+	}
+	if _, ok := g.systems[to]; !ok {
+		return nil
+	}
+
+	previous := map[int]int{from: from}
+	queue := []int{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			break
+		}
+		for _, next := range g.gates[current] {
+			if _, seen := previous[next]; seen {
+				continue
+			}
+			if next != to && avoid != nil && avoid(g.systems[next]) {
+				continue
+			}
+			previous[next] = current
+			queue = append(queue, next)
+		}
+	}
+
+	if _, ok := previous[to]; !ok {
+		return nil
+	}
+	var path []int
+	for current := to; current != from; current = previous[current] {
+		path = append(path, current)
+	}
+	path = append(path, from)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Within returns every system reachable from from in at most jumps jumps,
+// keyed by system ID with the jump distance as value. The origin is
+// included at distance zero and never avoided.
+func (g *Graph) Within(from, jumps int, avoid Avoid) map[int]int {
+	if _, ok := g.systems[from]; !ok {
+		return nil
+	}
+
+	distances := map[int]int{from: 0}
+	frontier := []int{from}
+	for distance := 1; distance <= jumps && len(frontier) > 0; distance++ {
+		var next []int
+		for _, current := range frontier {
+			for _, neighbour := range g.gates[current] {
+				if _, seen := distances[neighbour]; seen {
+					continue
+				}
+				if avoid != nil && avoid(g.systems[neighbour]) {
+					continue
+				}
+				distances[neighbour] = distance
+				next = append(next, neighbour)
+			}
+		}
+		frontier = next
+	}
+	return distances
+}
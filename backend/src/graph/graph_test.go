@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// testGraph links 1-2-3-4 through high security space and 1-5-4 through
+// null security system 5. System 6 has no gates.
+func testGraph() *Graph {
+	systems := []models.System{
+		{SystemID: 1, SecurityStatus: 0.9},
+		{SystemID: 2, SecurityStatus: 0.8},
+		{SystemID: 3, SecurityStatus: 0.7},
+		{SystemID: 4, SecurityStatus: 0.2},
+		{SystemID: 5, SecurityStatus: -0.3},
+		{SystemID: 6, SecurityStatus: 0.5},
+	}
+	gates := [][2]int{{1, 2}, {2, 1}, {2, 3}, {3, 4}, {1, 5}, {5, 4}, {4, 99}}
+	stargates := make([]models.Stargate, len(gates))
+	for i, gate := range gates {
+		stargates[i] = models.Stargate{StargateID: i, SystemID: gate[0], DestinationSystemID: gate[1]}
+	}
+	return New(systems, stargates)
+}
+
+func avoidBand(band SecurityBand) Avoid {
+	return func(system models.System) bool { return Band(system.SecurityStatus) == band }
+}
+
+func TestBand(t *testing.T) {
+	tests := []struct {
+		status float64
+		want   SecurityBand
+	}{
+		{1.0, HighSec},
+		{0.45, HighSec},
+		{0.44, LowSec},
+		{0.1, LowSec},
+		{0.0, NullSec},
+		{-1.0, NullSec},
+	}
+	for _, tt := range tests {
+		if got := Band(tt.status); got != tt.want {
+			t.Errorf("Band(%v) = %s, want %s", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	g := testGraph()
+	tests := []struct {
+		name     string
+		from, to int
+		avoid    Avoid
+		want     []int
+	}{
+		{"shortest", 1, 4, nil, []int{1, 5, 4}},
+		{"reverse", 4, 1, nil, []int{4, 5, 1}},
+		{"avoiding null security", 1, 4, avoidBand(NullSec), []int{1, 2, 3, 4}},
+		{"avoided destination", 1, 5, avoidBand(NullSec), []int{1, 5}},
+		{"avoided origin", 5, 2, avoidBand(NullSec), []int{5, 1, 2}},
+		{"zero jumps", 3, 3, nil, []int{3}},
+		{"unreachable", 1, 6, nil, nil},
+		{"unreachable around avoided systems", 2, 5, avoidBand(HighSec), nil},
+		{"unknown origin", 42, 1, nil, nil},
+		{"unknown destination", 1, 99, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.Route(tt.from, tt.to, tt.avoid); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Route(%d, %d) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithin(t *testing.T) {
+	g := testGraph()
+	tests := []struct {
+		name  string
+		from  int
+		jumps int
+		avoid Avoid
+		want  map[int]int
+	}{
+		{"zero jumps", 1, 0, nil, map[int]int{1: 0}},
+		{"one jump", 1, 1, nil, map[int]int{1: 0, 2: 1, 5: 1}},
+		{"shortest distances", 1, 5, nil, map[int]int{1: 0, 2: 1, 5: 1, 3: 2, 4: 2}},
+		{"avoiding null security", 1, 2, avoidBand(NullSec), map[int]int{1: 0, 2: 1, 3: 2}},
+		{"avoided origin", 5, 1, avoidBand(NullSec), map[int]int{5: 0, 1: 1, 4: 1}},
+		{"isolated", 6, 3, nil, map[int]int{6: 0}},
+		{"unknown origin", 42, 3, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.Within(tt.from, tt.jumps, tt.avoid); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Within(%d, %d) = %v, want %v", tt.from, tt.jumps, got, tt.want)
+			}
+		})
+	}
+}
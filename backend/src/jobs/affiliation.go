@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+func StartAffiliationCron() {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		SyncAffiliations()
+		<-ticker.C
+	}
+}
+
+// SyncAffiliations fetches the current corporation and alliance of every
+// tracked character and records changes in the affiliation history.
+// Characters without history are seeded from their ESI corporation history.
+func SyncAffiliations() {
+	ctx := context.Background()
+	utils.LogToConsole("Starting affiliation sync")
+
+	characters, err := repos.Characters.GetAllCharacters()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error getting characters: %v", err))
+		return
+	}
+
+	ids := make([]int64, len(characters))
+	for i, character := range characters {
+		ids[i] = character.ID
+	}
+
+	affiliations, err := services.FetchAffiliations(ctx, ids)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error fetching affiliations: %v", err))
+		return
+	}
+
+	changed := 0
+	now := time.Now().UTC()
+	for _, affiliation := range affiliations {
+		seeded, err := seedAffiliationHistory(ctx, affiliation)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("Error seeding affiliation history for character %d: %v", affiliation.CharacterID, err))
+			continue
+		}
+		if seeded {
+			continue
+		}
+
+		recorded, err := repos.Characters.RecordAffiliation(affiliation.CharacterID, affiliation.CorporationID, affiliation.AllianceID, now)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("Error recording affiliation for character %d: %v", affiliation.CharacterID, err))
+			continue
+		}
+		if recorded {
+			changed++
+		}
+	}
+
+	utils.LogToConsole(fmt.Sprintf("Finished affiliation sync: %d characters, %d changed", len(affiliations), changed))
+}
+
+// seedAffiliationHistory stores the ESI corporation history of a character
+// that has no affiliation history yet. ESI does not report past alliances,
+// so only the current record carries an alliance.
+func seedAffiliationHistory(ctx context.Context, affiliation services.Affiliation) (bool, error) {
+	exists, err := repos.Characters.HasAffiliationHistory(affiliation.CharacterID)
+	if err != nil || exists {
+		return false, err
+	}
+
+	records, err := services.FetchCorporationHistory(ctx, affiliation.CharacterID)
+	if err != nil {
+		return false, err
+	}
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	// ESI lists the newest record first
+	history := make([]models.CharacterAffiliation, len(records))
+	for i, record := range records {
+		entry := models.CharacterAffiliation{
+			CharacterID:   affiliation.CharacterID,
+			CorporationID: record.CorporationID,
+			StartDate:     record.StartDate,
+		}
+		if i == 0 {
+			entry.AllianceID = affiliation.AllianceID
+		} else {
+			endDate := records[i-1].StartDate
+			entry.EndDate = &endDate
+		}
+		history[len(records)-1-i] = entry
+	}
+
+	if err := repos.Characters.SeedAffiliationHistory(affiliation.CharacterID, history); err != nil {
+		return false, err
+	}
+	return true, nil
+}
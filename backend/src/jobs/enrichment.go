@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	enrichmentWorkers   = 4
+	enrichmentBatchSize = 10
+	// enrichmentIdleDelay is how long a worker sleeps when nothing is due.
+	enrichmentIdleDelay = 10 * time.Second
+	// enrichmentMaxAttempts is how often a job is tried before it is
+	// dead-lettered as permanent.
+	enrichmentMaxAttempts = 8
+	enrichmentMinBackoff  = time.Minute
+	enrichmentMaxBackoff  = 6 * time.Hour
+	// enrichmentClaimTimeout is how long a job may stay in flight before it
+	// is considered abandoned by a crashed worker.
+	enrichmentClaimTimeout = 10 * time.Minute
+)
+
+// StartEnrichmentWorkers queues every Zkill without a stored kill, and every
+// kill stored without its item tree, and runs the workers fetching their
+// killmails from ESI. Jobs abandoned in flight
+// are released periodically.
+func StartEnrichmentWorkers() {
+	if count, err := repos.Enrichment.EnqueueUnenrichedZKills(); err != nil {
+		utils.LogError(fmt.Sprintf("Error queueing unenriched kills: %v", err))
+	} else if count > 0 {
+		utils.LogToConsole(fmt.Sprintf("Queued %d unenriched kills", count))
+	}
+	if count, err := repos.Enrichment.EnqueueKillsWithoutItems(); err != nil {
+		utils.LogError(fmt.Sprintf("Error queueing kills without items: %v", err))
+	} else if count > 0 {
+		utils.LogToConsole(fmt.Sprintf("Queued %d kills to backfill their items", count))
+	}
+
+	for i := 0; i < enrichmentWorkers; i++ {
+		go runEnrichmentWorker()
+	}
+
+	for {
+		if count, err := repos.Enrichment.ReleaseStaleEnrichmentJobs(time.Now().Add(-enrichmentClaimTimeout)); err != nil {
+			utils.LogError(fmt.Sprintf("Error releasing stale enrichment jobs: %v", err))
+		} else if count > 0 {
+			utils.LogToConsole(fmt.Sprintf("Released %d stale enrichment jobs", count))
+		}
+		time.Sleep(enrichmentClaimTimeout)
+	}
+}
+
+func runEnrichmentWorker() {
+	for {
+		jobs, err := repos.Enrichment.ClaimEnrichmentJobs(enrichmentBatchSize)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("Error claiming enrichment jobs: %v", err))
+		}
+		if len(jobs) == 0 {
+			time.Sleep(enrichmentIdleDelay)
+			continue
+		}
+
+		for _, job := range jobs {
+			runEnrichmentJob(job)
+		}
+	}
+}
+
+// runEnrichmentJob stores the full killmail of job and records the outcome.
+// Failures are retried with exponential backoff until enrichmentMaxAttempts,
+// unless ESI rejects the killmail for good.
+func runEnrichmentJob(job models.EnrichmentJob) {
+	kill, err := EnhanceKill(job.KillmailID)
+	if err == nil {
+		err = repos.Kills.UpsertKill(kill)
+	}
+
+	if err == nil {
+		if err := repos.Enrichment.MarkEnrichmentDone(job.KillmailID); err != nil {
+			utils.LogError(fmt.Sprintf("Error completing enrichment of kill %d: %v", job.KillmailID, err))
+		}
+		return
+	}
+
+	state := models.EnrichmentFailed
+	if job.Attempts >= enrichmentMaxAttempts || isPermanentEnrichmentError(err) {
+		state = models.EnrichmentPermanent
+	}
+	backoff := enrichmentMinBackoff << min(job.Attempts-1, 16)
+	nextAttempt := time.Now().Add(min(backoff, enrichmentMaxBackoff))
+
+	utils.LogError(fmt.Sprintf("Error enriching kill %d (attempt %d, %s): %v", job.KillmailID, job.Attempts, state, err))
+	if err := repos.Enrichment.MarkEnrichmentFailed(job.KillmailID, state, nextAttempt, err.Error()); err != nil {
+		utils.LogError(fmt.Sprintf("Error recording failed enrichment of kill %d: %v", job.KillmailID, err))
+	}
+}
+
+// isPermanentEnrichmentError reports whether retrying cannot help: the
+// Zkill is gone or ESI does not know the killmail or its hash.
+func isPermanentEnrichmentError(err error) bool {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+	var esiErr *services.ESIError
+	if errors.As(err, &esiErr) {
+		switch esiErr.StatusCode {
+		case http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+// entityFeeds returns the zKillboard lists of the kills and losses of a
+// tracked corporation or alliance, whose members are added to roster.
+func entityFeeds(entity models.TrackedEntity, roster map[int64]bool) []zkillFeed {
+	var feeds []zkillFeed
+	for _, list := range []struct{ name, role string }{
+		{"kills", models.RoleAttacker},
+		{"losses", models.RoleVictim},
+	} {
+		feeds = append(feeds, zkillFeed{
+			path: fmt.Sprintf("%s/%s/%d", list.name, entity.ZKillField(), entity.EntityID),
+			store: func(entry services.ZKillEntry) error {
+				return storeEntityKill(entity, entry, list.role, roster)
+			},
+		})
+	}
+	return feeds
+}
+
+// InitializeEntityKills stores every kill and loss of a tracked corporation
+// or alliance, resuming an interrupted backfill.
+func InitializeEntityKills(entity models.TrackedEntity) error {
+	roster, err := loadRoster()
+	if err != nil {
+		return err
+	}
+	for _, feed := range entityFeeds(entity, roster) {
+		if err := syncZKillFeed(context.Background(), feed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNewEntityKills syncs the kills and losses of every tracked entity.
+func checkNewEntityKills() {
+	entities, err := repos.Entities.GetTrackedEntities()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error fetching tracked entities: %v", err))
+		return
+	}
+	if len(entities) == 0 {
+		return
+	}
+
+	roster, err := loadRoster()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error fetching characters: %v", err))
+		return
+	}
+	for _, entity := range entities {
+		for _, feed := range entityFeeds(entity, roster) {
+			if err := syncZKillFeed(context.Background(), feed); err != nil {
+				utils.LogError(fmt.Sprintf("Error fetching %s: %v", feed.path, err))
+			}
+		}
+	}
+}
+
+// storeEntityKill fetches the killmail of entry and stores it attributed to
+// the entity member in role, adding the member to the tracked characters.
+// Kills without a member character, e.g. structure losses, are skipped.
+func storeEntityKill(entity models.TrackedEntity, entry services.ZKillEntry, role string, roster map[int64]bool) error {
+	kill, err := services.FetchKillmailFromESI(entry.KillmailID, entry.ZKB.Hash)
+	if err != nil {
+		return err
+	}
+
+	characterID := entity.Member(kill, role)
+	if characterID == 0 {
+		return nil
+	}
+	if err := trackCharacter(characterID, roster); err != nil {
+		return err
+	}
+
+	zkill := entry.ZKB.Zkill(kill.KillmailID, characterID, role)
+	if err := repos.ZKills.UpsertZKills([]models.Zkill{zkill}); err != nil {
+		return err
+	}
+
+	kill.CharacterID = characterID
+	kill.Role = role
+	kill.ZkillData = zkill
+	return repos.Kills.UpsertKill(kill)
+}
+
+// loadRoster returns the IDs of the tracked characters.
+func loadRoster() (map[int64]bool, error) {
+	characters, err := repos.Characters.GetAllCharacters()
+	if err != nil {
+		return nil, err
+	}
+	roster := make(map[int64]bool, len(characters))
+	for _, character := range characters {
+		roster[character.ID] = true
+	}
+	return roster, nil
+}
+
+// trackCharacter adds the character to the tracked characters unless roster
+// already holds it.
+func trackCharacter(characterID int64, roster map[int64]bool) error {
+	if roster[characterID] {
+		return nil
+	}
+
+	character, err := services.FetchCharacterInfo(characterID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch character %d: %w", characterID, err)
+	}
+	if err := repos.Characters.UpsertCharacter(character); err != nil {
+		return err
+	}
+
+	roster[characterID] = true
+	utils.LogToConsole(fmt.Sprintf("Added character: %s (ID: %d)", character.Name, character.ID))
+	return nil
+}
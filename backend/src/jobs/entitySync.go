@@ -0,0 +1,211 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+// entityMaxAge is how long corporation and alliance data is kept before it
+// is fetched from ESI again.
+const entityMaxAge = 24 * time.Hour
+
+var entitySyncOptions = services.BulkOptions{
+	Concurrency: 10,
+	Retries:     2,
+	RetryDelay:  5 * time.Second,
+}
+
+func StartEntitySyncCron() {
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		SyncCorporationsAndAlliances()
+		<-ticker.C
+	}
+}
+
+// SyncCorporationsAndAlliances refreshes every corporation and alliance that
+// appears on a stored kill and is missing or older than entityMaxAge.
+func SyncCorporationsAndAlliances() {
+	ctx := context.Background()
+	utils.LogToConsole("Starting corporation and alliance sync")
+
+	allianceIDs, err := staleEntityIDs("alliance_id", "alliances")
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error collecting alliance IDs: %v", err))
+		return
+	}
+	corporationIDs, err := staleEntityIDs("corporation_id", "corporations")
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error collecting corporation IDs: %v", err))
+		return
+	}
+
+	if err := refreshCorporations(ctx, corporationIDs); err != nil {
+		utils.LogError(fmt.Sprintf("Error refreshing corporations: %v", err))
+	}
+
+	refreshed := 0
+	for _, allianceID := range allianceIDs {
+		if _, err := RefreshAlliance(ctx, allianceID); err != nil {
+			utils.LogError(fmt.Sprintf("Error refreshing alliance %d: %v", allianceID, err))
+			continue
+		}
+		refreshed++
+	}
+
+	utils.LogToConsole(fmt.Sprintf("Finished corporation and alliance sync: %d corporations, %d alliances", len(corporationIDs), refreshed))
+}
+
+// staleEntityIDs returns the IDs referenced by kills through field that are
+// missing from table or older than entityMaxAge.
+func staleEntityIDs(field, table string) ([]int64, error) {
+	ids, err := repos.Entities.GetReferencedEntityIDs(field)
+	if err != nil {
+		return nil, err
+	}
+	fresh, err := repos.Entities.GetFreshEntityIDs(table, time.Now().Add(-entityMaxAge))
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []int64
+	for _, id := range ids {
+		if !fresh[id] {
+			stale = append(stale, id)
+		}
+	}
+	return stale, nil
+}
+
+// refreshCorporations fetches the given corporations from ESI and stores them.
+func refreshCorporations(ctx context.Context, corporationIDs []int64) error {
+	ids := make([]int, len(corporationIDs))
+	for i, id := range corporationIDs {
+		ids[i] = int(id)
+	}
+
+	results, fetchErr := services.FetchBulk(ctx, ids, entitySyncOptions, func(ctx context.Context, id int) (*models.Corporation, error) {
+		return services.FetchCorporationInfo(ctx, int64(id))
+	})
+
+	corporations := make([]models.Corporation, 0, len(results))
+	for _, corporation := range results {
+		corporations = append(corporations, *corporation)
+	}
+	if err := repos.Entities.UpsertCorporations(corporations); err != nil {
+		return err
+	}
+	return fetchErr
+}
+
+// RefreshCorporation fetches a single corporation from ESI and stores it.
+func RefreshCorporation(ctx context.Context, corporationID int64) (*models.Corporation, error) {
+	corporation, err := services.FetchCorporationInfo(ctx, corporationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := repos.Entities.UpsertCorporations([]models.Corporation{*corporation}); err != nil {
+		return nil, err
+	}
+	return corporation, nil
+}
+
+// RefreshAlliance fetches an alliance and its member corporations from ESI.
+// Member corporations older than entityMaxAge are refreshed so the alliance
+// member count can be summed from them.
+func RefreshAlliance(ctx context.Context, allianceID int64) (*models.Alliance, error) {
+	alliance, corporationIDs, err := storeAlliance(ctx, allianceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := refreshAllianceMembers(ctx, alliance, corporationIDs); err != nil {
+		return nil, err
+	}
+	return alliance, nil
+}
+
+// StoreAlliance fetches an alliance from ESI and stores it with the members
+// of its corporations stored so far. The stale member corporations are
+// refreshed in the background, so a request does not wait on every one of
+// them.
+func StoreAlliance(ctx context.Context, allianceID int64) (*models.Alliance, error) {
+	alliance, corporationIDs, err := storeAlliance(ctx, allianceID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := *alliance
+	go func() {
+		if err := refreshAllianceMembers(context.Background(), &members, corporationIDs); err != nil {
+			utils.LogError(fmt.Sprintf("Error refreshing members of alliance %d: %v", allianceID, err))
+		}
+	}()
+	return alliance, nil
+}
+
+// storeAlliance fetches an alliance and the IDs of its member corporations
+// from ESI and stores the alliance with the members of the corporations
+// already stored.
+func storeAlliance(ctx context.Context, allianceID int64) (*models.Alliance, []int64, error) {
+	alliance, err := services.FetchAllianceInfo(ctx, allianceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	corporationIDs, err := services.FetchAllianceCorporationIDs(ctx, allianceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	alliance.CorporationCount = len(corporationIDs)
+	alliance.MemberCount, err = repos.Entities.SumCorporationMembers(corporationIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := repos.Entities.UpsertAlliance(alliance); err != nil {
+		return nil, nil, err
+	}
+	return alliance, corporationIDs, nil
+}
+
+// refreshAllianceMembers refreshes the member corporations of alliance older
+// than entityMaxAge and stores the alliance with their summed members.
+// Corporations that fail are logged and counted as last stored.
+func refreshAllianceMembers(ctx context.Context, alliance *models.Alliance, corporationIDs []int64) error {
+	fresh, err := repos.Entities.GetFreshEntityIDs("corporations", time.Now().Add(-entityMaxAge))
+	if err != nil {
+		return err
+	}
+	var stale []int64
+	for _, id := range corporationIDs {
+		if !fresh[id] {
+			stale = append(stale, id)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	if err := refreshCorporations(ctx, stale); err != nil {
+		var bulkErr *services.BulkError
+		if !errors.As(err, &bulkErr) {
+			return err
+		}
+		utils.LogError(fmt.Sprintf("Error refreshing corporations of alliance %d: %v", alliance.ID, err))
+	}
+
+	alliance.MemberCount, err = repos.Entities.SumCorporationMembers(corporationIDs)
+	if err != nil {
+		return err
+	}
+	return repos.Entities.UpsertAlliance(alliance)
+}
@@ -1,14 +1,21 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 	"time"
-
-	"github.com/tadeasf/eve-ran/src/db/models"
-	"github.com/tadeasf/eve-ran/src/db/queries"
 )
 
 func StartKillCron() {
+	if count, err := repos.Kills.BackfillKillRoles(); err != nil {
+		fmt.Printf("Error backfilling kill roles: %v\n", err)
+	} else if count > 0 {
+		fmt.Printf("Backfilled the role of %d kills\n", count)
+	}
+	if err := repos.Kills.BackfillKillParticipants(); err != nil {
+		fmt.Printf("Error backfilling kill participants: %v\n", err)
+	}
+
 	ticker := time.NewTicker(15 * time.Minute)
 	defer ticker.Stop()
 
@@ -18,59 +25,20 @@ func StartKillCron() {
 }
 
 func checkNewKills() {
-	characters, err := queries.GetAllCharacters()
+	characters, err := repos.Characters.GetAllCharacters()
 	if err != nil {
 		fmt.Printf("Error fetching characters: %v\n", err)
 		return
 	}
 
 	for _, character := range characters {
-		page := 1
-		for {
-			zkills, err := FetchKillsFromZKillboard(character.ID, page)
-			if err != nil {
-				fmt.Printf("Error fetching kills for character %d: %v\n", character.ID, err)
-				break
+		for _, feed := range characterFeeds(character.ID) {
+			if err := syncZKillFeed(context.Background(), feed); err != nil {
+				fmt.Printf("Error fetching %s: %v\n", feed.path, err)
 			}
-
-			if len(zkills) == 0 {
-				break
-			}
-
-			newZKills := filterNewZKills(zkills)
-			if len(newZKills) == 0 {
-				break
-			}
-
-			err = StoreZKills(newZKills)
-			if err != nil {
-				fmt.Printf("Error storing new zkills for character %d: %v\n", character.ID, err)
-				break
-			}
-
-			for _, zkill := range newZKills {
-				err = EnhanceAndStoreKill(zkill)
-				if err != nil {
-					fmt.Printf("Error enhancing and storing kill %d: %v\n", zkill.KillmailID, err)
-				}
-			}
-
-			page++
 		}
 	}
-}
 
-func filterNewZKills(zkills []models.Zkill) []models.Zkill {
-	var newZKills []models.Zkill
-	for _, zkill := range zkills {
-		exists, err := queries.ZKillExists(zkill.KillmailID)
-		if err != nil {
-			fmt.Printf("Error checking if zkill %d exists: %v\n", zkill.KillmailID, err)
-			continue
-		}
-		if !exists {
-			newZKills = append(newZKills, zkill)
-		}
-	}
-	return newZKills
+	checkNewEntityKills()
+	checkNewRegionKills()
 }
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+const (
+	fixtureCharacterID = 2117608621
+	fixtureKillmailID  = 128415478
+)
+
+// TestCharacterKillIngestion runs a character's kills from the zKillboard
+// list through enrichment into the kill tables.
+func TestCharacterKillIngestion(t *testing.T) {
+	setupTestDB(t)
+	setupFakeUpstream(t)
+
+	if err := InitializeCharacterKills(fixtureCharacterID); err != nil {
+		t.Fatalf("InitializeCharacterKills: %v", err)
+	}
+
+	zkill, err := repos.ZKills.GetZKillByID(fixtureKillmailID)
+	if err != nil {
+		t.Fatalf("GetZKillByID: %v", err)
+	}
+	if zkill.CharacterID != fixtureCharacterID || zkill.Role != models.RoleAttacker {
+		t.Errorf("zkill credited to %d as %q, want %d as %q", zkill.CharacterID, zkill.Role, fixtureCharacterID, models.RoleAttacker)
+	}
+
+	jobs, err := repos.Enrichment.ClaimEnrichmentJobs(enrichmentBatchSize)
+	if err != nil {
+		t.Fatalf("ClaimEnrichmentJobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].KillmailID != fixtureKillmailID {
+		t.Fatalf("claimed %+v, want the job of kill %d", jobs, fixtureKillmailID)
+	}
+	runEnrichmentJob(jobs[0])
+
+	kill, err := repos.Kills.GetKillmail(fixtureKillmailID)
+	if err != nil {
+		t.Fatalf("GetKillmail: %v", err)
+	}
+	if kill == nil {
+		t.Fatal("kill was not stored")
+	}
+	if kill.SolarSystemID != 30003830 || kill.CharacterID != fixtureCharacterID || kill.Role != models.RoleAttacker {
+		t.Errorf("stored kill in system %d for %d as %q", kill.SolarSystemID, kill.CharacterID, kill.Role)
+	}
+	if kill.ZkillData.Hash != zkill.Hash {
+		t.Errorf("stored kill has zkill hash %q, want %q", kill.ZkillData.Hash, zkill.Hash)
+	}
+
+	items, err := repos.Kills.GetKillItems(fixtureKillmailID)
+	if err != nil {
+		t.Fatalf("GetKillItems: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("stored %d items, want 2", len(items))
+	}
+	attackers, err := repos.Kills.GetKillAttackers(fixtureKillmailID)
+	if err != nil {
+		t.Fatalf("GetKillAttackers: %v", err)
+	}
+	if len(attackers) != 1 || attackers[0].CharacterID != fixtureCharacterID || !attackers[0].FinalBlow {
+		t.Errorf("stored attackers %+v, want the fixture character with the final blow", attackers)
+	}
+
+	done, _, err := repos.Enrichment.GetEnrichmentJobs(models.EnrichmentDone, 1, 10)
+	if err != nil {
+		t.Fatalf("GetEnrichmentJobs: %v", err)
+	}
+	if len(done) != 1 {
+		t.Errorf("%d enrichment jobs done, want 1", len(done))
+	}
+}
+
+// TestCharacterKillFetch follows a character's kills from the zKillboard
+// list to the full killmail on ESI.
+func TestCharacterKillFetch(t *testing.T) {
+	server := setupFakeUpstream(t)
+
+	feed := characterFeed("kills", models.RoleAttacker, fixtureCharacterID)
+	entries, err := services.ZKill.Entries(context.Background(), feed.path, 1)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].KillmailID != fixtureKillmailID {
+		t.Fatalf("entries %+v, want kill %d", entries, fixtureKillmailID)
+	}
+	if entries[0].ZKB.TotalValue != 10354567.8 {
+		t.Errorf("zkill value %v, want 10354567.8", entries[0].ZKB.TotalValue)
+	}
+
+	// zKillboard answers past the last page with an empty list
+	entries, err = services.ZKill.Entries(context.Background(), feed.path, 2)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("page 2 has %d entries, want none", len(entries))
+	}
+
+	kill, err := services.FetchKillmailFromESI(fixtureKillmailID, "3f1d6a0c2b9e4d7a8c5b0e1f2a3b4c5d6e7f8091")
+	if err != nil {
+		t.Fatalf("FetchKillmailFromESI: %v", err)
+	}
+	if kill.SolarSystemID != 30003830 || kill.Victim.ShipTypeID != 587 {
+		t.Errorf("killmail in system %d with victim ship %d", kill.SolarSystemID, kill.Victim.ShipTypeID)
+	}
+	var attackers []models.Attacker
+	if err := json.Unmarshal(kill.Attackers, &attackers); err != nil {
+		t.Fatalf("decoding attackers: %v", err)
+	}
+	if len(attackers) != 1 || attackers[0].CharacterID != fixtureCharacterID || !attackers[0].FinalBlow {
+		t.Errorf("attackers %+v, want the fixture character with the final blow", attackers)
+	}
+
+	if requests := server.Requests(); len(requests) != 3 {
+		t.Errorf("fake upstream saw %v, want 3 requests", requests)
+	}
+}
@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// killMatcher decides which kills concern tracked characters, corporations,
+// alliances or regions.
+type killMatcher struct {
+	characters   map[int64]bool
+	corporations map[int64]bool
+	alliances    map[int64]bool
+	regions      map[int]bool
+	loadedAt     time.Time
+}
+
+// loadKillMatcher loads the tracked characters, corporations, alliances and
+// regions.
+func loadKillMatcher() (*killMatcher, error) {
+	characters, err := loadRoster()
+	if err != nil {
+		return nil, err
+	}
+	entities, err := repos.Entities.GetTrackedEntities()
+	if err != nil {
+		return nil, err
+	}
+	regions, err := repos.Universe.GetTrackedRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := &killMatcher{
+		characters:   characters,
+		corporations: make(map[int64]bool),
+		alliances:    make(map[int64]bool),
+		regions:      make(map[int]bool, len(regions)),
+		loadedAt:     time.Now(),
+	}
+	for _, entity := range entities {
+		switch entity.EntityType {
+		case models.EntityCorporation:
+			matcher.corporations[entity.EntityID] = true
+		case models.EntityAlliance:
+			matcher.alliances[entity.EntityID] = true
+		}
+	}
+	for _, region := range regions {
+		matcher.regions[region.RegionID] = true
+	}
+	return matcher, nil
+}
+
+// tracksEntity reports whether the corporation or alliance is tracked.
+func (m *killMatcher) tracksEntity(corporationID, allianceID int64) bool {
+	return m.corporations[corporationID] || m.alliances[allianceID]
+}
+
+// attribute returns the tracked character on kill and their role, looking
+// at the victim and the attackers, first for tracked characters and then
+// for members of tracked corporations and alliances.
+func (m *killMatcher) attribute(kill *models.Kill) (int64, string, bool) {
+	attackers, err := kill.GetAttackers()
+	if err != nil {
+		return 0, "", false
+	}
+
+	if m.characters[kill.Victim.CharacterID] {
+		return kill.Victim.CharacterID, models.RoleVictim, true
+	}
+	for _, attacker := range attackers {
+		if m.characters[attacker.CharacterID] {
+			return attacker.CharacterID, models.RoleAttacker, true
+		}
+	}
+	if kill.Victim.CharacterID != 0 && m.tracksEntity(kill.Victim.CorporationID, kill.Victim.AllianceID) {
+		return kill.Victim.CharacterID, models.RoleVictim, true
+	}
+	for _, attacker := range attackers {
+		if attacker.CharacterID != 0 && m.tracksEntity(attacker.CorporationID, attacker.AllianceID) {
+			return attacker.CharacterID, models.RoleAttacker, true
+		}
+	}
+	return 0, "", false
+}
+
+// inTrackedRegion reports whether kill happened in a tracked region.
+func (m *killMatcher) inTrackedRegion(kill *models.Kill) bool {
+	if len(m.regions) == 0 {
+		return false
+	}
+	universe, err := services.UniverseGraph()
+	if err != nil {
+		return false
+	}
+	system, ok := universe.System(kill.SolarSystemID)
+	return ok && m.regions[system.RegionID]
+}
+
+// storeMatchedKill stores kill and its zKillboard metadata if it concerns a
+// tracked character, corporation or alliance, attributed to the character,
+// or happened in a tracked region, unattributed. inRegion skips the region
+// check for kills known to be in a tracked region. Members of tracked
+// entities are added to the tracked characters.
+func storeMatchedKill(kill *models.Kill, zkb services.ZKB, matcher *killMatcher, inRegion bool) (bool, error) {
+	characterID, role, ok := matcher.attribute(kill)
+	if !ok && !inRegion && !matcher.inTrackedRegion(kill) {
+		return false, nil
+	}
+	if characterID != 0 {
+		if err := trackCharacter(characterID, matcher.characters); err != nil {
+			return false, err
+		}
+	}
+
+	zkill := zkb.Zkill(kill.KillmailID, characterID, role)
+	if err := repos.ZKills.UpsertZKills([]models.Zkill{zkill}); err != nil {
+		return false, err
+	}
+
+	kill.CharacterID = characterID
+	kill.Role = role
+	kill.ZkillData = zkill
+	if err := repos.Kills.UpsertKill(kill); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,109 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// matcherKill is a kill in The Bleak Lands with one victim and two attackers.
+const matcherKill = `{
+	"killmail_id": 1,
+	"killmail_time": "2025-06-01T18:42:10Z",
+	"solar_system_id": 30003830,
+	"victim": {"character_id": 100, "corporation_id": 200, "alliance_id": 300, "ship_type_id": 587},
+	"attackers": [
+		{"corporation_id": 1000000001, "ship_type_id": 11},
+		{"character_id": 101, "corporation_id": 201, "alliance_id": 301, "ship_type_id": 587, "final_blow": true}
+	]
+}`
+
+func newMatcher() *killMatcher {
+	return &killMatcher{
+		characters:   make(map[int64]bool),
+		corporations: make(map[int64]bool),
+		alliances:    make(map[int64]bool),
+		regions:      make(map[int]bool),
+	}
+}
+
+func TestKillMatcherAttribute(t *testing.T) {
+	kill, err := services.ParseKillmail([]byte(matcherKill))
+	if err != nil {
+		t.Fatalf("ParseKillmail: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		track         func(m *killMatcher)
+		wantCharacter int64
+		wantRole      string
+		wantOK        bool
+	}{
+		{"untracked", func(m *killMatcher) {}, 0, "", false},
+		{"tracked victim", func(m *killMatcher) { m.characters[100] = true }, 100, models.RoleVictim, true},
+		{"tracked attacker", func(m *killMatcher) { m.characters[101] = true }, 101, models.RoleAttacker, true},
+		{"victim before attacker", func(m *killMatcher) {
+			m.characters[100] = true
+			m.characters[101] = true
+		}, 100, models.RoleVictim, true},
+		{"victim corporation", func(m *killMatcher) { m.corporations[200] = true }, 100, models.RoleVictim, true},
+		{"attacker alliance", func(m *killMatcher) { m.alliances[301] = true }, 101, models.RoleAttacker, true},
+		{"tracked character before entity", func(m *killMatcher) {
+			m.corporations[200] = true
+			m.characters[101] = true
+		}, 101, models.RoleAttacker, true},
+		{"NPC corporation member without character", func(m *killMatcher) { m.corporations[1000000001] = true }, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := newMatcher()
+			tt.track(matcher)
+			characterID, role, ok := matcher.attribute(kill)
+			if characterID != tt.wantCharacter || role != tt.wantRole || ok != tt.wantOK {
+				t.Errorf("attribute() = %d, %q, %v, want %d, %q, %v", characterID, role, ok, tt.wantCharacter, tt.wantRole, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestKillMatcherInTrackedRegion(t *testing.T) {
+	setupTestDB(t)
+	err := repos.Universe.BatchUpsertSystems([]*models.System{{SystemID: 30003830, ConstellationID: 20000561, RegionID: 10000048}})
+	if err != nil {
+		t.Fatalf("BatchUpsertSystems: %v", err)
+	}
+	services.ResetUniverseGraph()
+	t.Cleanup(services.ResetUniverseGraph)
+
+	kill, err := services.ParseKillmail([]byte(matcherKill))
+	if err != nil {
+		t.Fatalf("ParseKillmail: %v", err)
+	}
+
+	matcher := newMatcher()
+	if matcher.inTrackedRegion(kill) {
+		t.Error("kill matched without tracked regions")
+	}
+	matcher.regions[10000002] = true
+	if matcher.inTrackedRegion(kill) {
+		t.Error("kill matched a region it did not happen in")
+	}
+	matcher.regions[10000048] = true
+	if !matcher.inTrackedRegion(kill) {
+		t.Error("kill did not match its tracked region")
+	}
+
+	stored, err := storeMatchedKill(kill, services.ZKB{Hash: "abc"}, matcher, false)
+	if err != nil || !stored {
+		t.Fatalf("storeMatchedKill = %v, %v, want stored", stored, err)
+	}
+	got, err := repos.Kills.GetKillmail(kill.KillmailID)
+	if err != nil || got == nil {
+		t.Fatalf("GetKillmail = %v, %v", got, err)
+	}
+	if got.CharacterID != 0 || got.Role != "" {
+		t.Errorf("region kill attributed to %d as %q, want unattributed", got.CharacterID, got.Role)
+	}
+}
@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tadeasf/eve-ran/src/db"
+	"github.com/tadeasf/eve-ran/src/db/repository"
+	"github.com/tadeasf/eve-ran/src/fakeupstream"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+// TestMain logs to stderr instead of the log file main sets up.
+func TestMain(m *testing.M) {
+	utils.InfoLogger = log.New(os.Stderr, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
+	utils.ErrorLogger = log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
+	os.Exit(m.Run())
+}
+
+// setupTestDB migrates a fresh SQLite database in the test's temporary
+// directory and points db.DB and the repositories at it.
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "eve-ran.db"))
+
+	db.InitDB()
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	repositories := repository.New(db.DB)
+	ConfigureRepositories(repositories)
+	services.ConfigureRepositories(repositories)
+}
+
+// setupFakeUpstream serves the bundled fixtures and points the shared ESI,
+// zKillboard and RedisQ clients at them.
+func setupFakeUpstream(t *testing.T) *fakeupstream.Server {
+	t.Helper()
+	server := fakeupstream.New()
+	t.Cleanup(server.Close)
+	services.ConfigureUpstreams(server.Upstreams())
+	t.Cleanup(func() { services.ConfigureUpstreams(services.UpstreamsFromEnv()) })
+	return server
+}
@@ -0,0 +1,215 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+const (
+	// PriceHistoryRegionID is the region whose market history kills are
+	// valued with: The Forge, home of Jita.
+	PriceHistoryRegionID = 10000002
+	// priceHistoryMaxAge is how far back a kill may fall back to the last
+	// traded day of a type before today's price is used instead.
+	priceHistoryMaxAge = 30 * 24 * time.Hour
+)
+
+var priceHistoryOptions = services.BulkOptions{
+	Concurrency: 5,
+	Retries:     2,
+	RetryDelay:  10 * time.Second,
+}
+
+func StartPriceHistoryCron() {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		SyncPriceHistory()
+		RepriceKills()
+		<-ticker.C
+	}
+}
+
+// SyncPriceHistory stores the market history of every market type that
+// appears on a stored kill. Types whose history is already up to date are
+// skipped.
+func SyncPriceHistory() {
+	ctx := context.Background()
+	utils.LogToConsole("Starting price history sync")
+
+	typeIDs, err := repos.Prices.GetKillMarketTypeIDs()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error collecting kill type IDs: %v", err))
+		return
+	}
+	latest, err := repos.Prices.GetLatestHistoryDates(PriceHistoryRegionID)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error getting latest price history dates: %v", err))
+		return
+	}
+
+	// ESI publishes a day's history on the following day
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	opts := priceHistoryOptions
+	opts.Skip = func(typeID int) bool {
+		return !latest[typeID].Before(yesterday)
+	}
+
+	results, err := services.FetchBulk(ctx, typeIDs, opts, func(ctx context.Context, typeID int) (int, error) {
+		history, err := services.FetchPriceHistory(ctx, PriceHistoryRegionID, typeID)
+		if err != nil {
+			return 0, err
+		}
+
+		var fresh []models.PriceHistory
+		for _, day := range history {
+			if day.Date.After(latest[typeID]) {
+				fresh = append(fresh, day)
+			}
+		}
+		return len(fresh), repos.Prices.UpsertPriceHistory(fresh)
+	})
+
+	var bulkErr *services.BulkError
+	if errors.As(err, &bulkErr) {
+		utils.LogToFile(fmt.Sprintf("No price history for %d types: %v", len(bulkErr.Failed), err))
+	} else if err != nil {
+		utils.LogError(fmt.Sprintf("Error syncing price history: %v", err))
+	}
+
+	days := 0
+	for _, count := range results {
+		days += count
+	}
+	utils.LogToConsole(fmt.Sprintf("Finished price history sync: %d types, %d new days", len(results), days))
+}
+
+// RepriceKills values every kill from before today at the prices of its own
+// day. A type without history on that day uses its last traded day within
+// priceHistoryMaxAge, or today's price if there is none. A kill is marked
+// historical, and not repriced again, unless it needed today's price for a
+// type whose history has not been synced up to the kill's day yet. Types
+// without any history, or whose history starts after the kill, will not
+// gain a price for that day and do not hold the kill back.
+func RepriceKills() {
+	current, err := repos.Prices.GetPriceMap()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error loading market prices: %v", err))
+		return
+	}
+	latest, err := repos.Prices.GetLatestHistoryDates(PriceHistoryRegionID)
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error getting latest price history dates: %v", err))
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	repriced := 0
+	var lastID int64
+	for {
+		kills, err := repos.Kills.GetKillsToReprice(today, lastID, valuationBatchSize)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("Error fetching kills to reprice: %v", err))
+			break
+		}
+		if len(kills) == 0 {
+			break
+		}
+
+		history, err := loadKillPriceHistory(kills)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("Error loading price history: %v", err))
+			break
+		}
+
+		for _, kill := range kills {
+			lastID = kill.KillmailID
+			day := kill.KillmailTime.UTC().Truncate(24 * time.Hour)
+			historical := true
+			value := services.ValueKill(kill.Victim, func(typeID int) float64 {
+				if price, ok := historicalPrice(history[typeID], day); ok {
+					return price
+				}
+				if price := current[typeID]; price > 0 {
+					if last, ok := latest[typeID]; ok && last.Before(day) {
+						historical = false
+					}
+					return price
+				}
+				return 0
+			})
+			value.Historical = historical
+
+			if err := repos.Kills.UpdateKillValue(kill.KillmailID, value); err != nil {
+				utils.LogError(fmt.Sprintf("Error storing value of kill %d: %v", kill.KillmailID, err))
+				return
+			}
+			if historical {
+				repriced++
+			}
+		}
+	}
+
+	if repriced > 0 {
+		utils.LogToConsole(fmt.Sprintf("Repriced %d kills at historical prices", repriced))
+	}
+}
+
+// loadKillPriceHistory returns the price history needed to value kills,
+// keyed by type ID and ordered by date.
+func loadKillPriceHistory(kills []models.Kill) (map[int][]models.PriceHistory, error) {
+	typeSet := make(map[int]bool)
+	from, to := kills[0].KillmailTime, kills[0].KillmailTime
+	for _, kill := range kills {
+		typeSet[kill.Victim.ShipTypeID] = true
+		kill.Victim.Items.Walk(func(_, _ int, item models.ZKillboardItem) {
+			typeSet[item.ItemTypeID] = true
+		})
+		if kill.KillmailTime.Before(from) {
+			from = kill.KillmailTime
+		}
+		if kill.KillmailTime.After(to) {
+			to = kill.KillmailTime
+		}
+	}
+
+	typeIDs := make([]int, 0, len(typeSet))
+	for typeID := range typeSet {
+		typeIDs = append(typeIDs, typeID)
+	}
+
+	rows, err := repos.Prices.GetPriceHistory(PriceHistoryRegionID, typeIDs, from.Add(-priceHistoryMaxAge), to)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[int][]models.PriceHistory, len(typeIDs))
+	for _, row := range rows {
+		history[row.TypeID] = append(history[row.TypeID], row)
+	}
+	return history, nil
+}
+
+// historicalPrice returns the average price of the last day in history on
+// or before day, if it is no older than priceHistoryMaxAge.
+func historicalPrice(history []models.PriceHistory, day time.Time) (float64, bool) {
+	i := sort.Search(len(history), func(i int) bool {
+		return history[i].Date.After(day)
+	})
+	if i == 0 {
+		return 0, false
+	}
+	last := history[i-1]
+	if day.Sub(last.Date) > priceHistoryMaxAge {
+		return 0, false
+	}
+	return last.Average, true
+}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func TestRepriceKills(t *testing.T) {
+	setupTestDB(t)
+
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	one := int64(1)
+	// Tritanium (34) has no history at all, Pyerite (35) stopped trading a
+	// month before the kills and Rifters (587) traded on their day
+	err := repos.Prices.UpsertMarketPrices([]models.MarketPrice{
+		{TypeID: 34, AveragePrice: 5},
+		{TypeID: 35, AveragePrice: 7},
+		{TypeID: 587, AveragePrice: 500},
+	})
+	if err != nil {
+		t.Fatalf("UpsertMarketPrices: %v", err)
+	}
+	err = repos.Prices.UpsertPriceHistory([]models.PriceHistory{
+		{RegionID: PriceHistoryRegionID, TypeID: 35, Date: day.AddDate(0, -2, 0), Average: 6},
+		{RegionID: PriceHistoryRegionID, TypeID: 587, Date: day, Average: 400},
+	})
+	if err != nil {
+		t.Fatalf("UpsertPriceHistory: %v", err)
+	}
+
+	for id, typeID := range map[int64]int{1: 34, 2: 35} {
+		kill := &models.Kill{
+			KillmailID:   id,
+			KillmailTime: day.Add(18 * time.Hour),
+			Victim: models.Victim{
+				ShipTypeID: 587,
+				Items:      models.ItemArray{{ItemTypeID: typeID, QuantityDestroyed: &one}},
+			},
+		}
+		if err := repos.Kills.UpsertKill(kill); err != nil {
+			t.Fatalf("UpsertKill: %v", err)
+		}
+	}
+
+	RepriceKills()
+
+	tests := []struct {
+		killmailID     int64
+		wantTotal      float64
+		wantHistorical bool
+	}{
+		// A type without history will not gain a price for the day
+		{1, 405, true},
+		// Pyerite's history may still be synced up to the kill's day
+		{2, 407, false},
+	}
+	for _, tt := range tests {
+		kill, err := repos.Kills.GetKillmail(tt.killmailID)
+		if err != nil || kill == nil {
+			t.Fatalf("GetKillmail = %v, %v", kill, err)
+		}
+		if kill.Value.Total != tt.wantTotal || kill.Value.Historical != tt.wantHistorical {
+			t.Errorf("kill %d valued at %v, historical %v, want %v, %v",
+				tt.killmailID, kill.Value.Total, kill.Value.Historical, tt.wantTotal, tt.wantHistorical)
+		}
+	}
+
+	// Once Pyerite's history reaches the kill the price is final, even
+	// without a trade on the day itself
+	err = repos.Prices.UpsertPriceHistory([]models.PriceHistory{
+		{RegionID: PriceHistoryRegionID, TypeID: 35, Date: day.AddDate(0, 0, 3), Average: 8},
+	})
+	if err != nil {
+		t.Fatalf("UpsertPriceHistory: %v", err)
+	}
+	RepriceKills()
+
+	kills, err := repos.Kills.GetKillsToReprice(day.AddDate(0, 1, 0), 0, 10)
+	if err != nil {
+		t.Fatalf("GetKillsToReprice: %v", err)
+	}
+	if len(kills) != 0 {
+		t.Errorf("%d kills left to reprice, want none", len(kills))
+	}
+}
@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+const (
+	// redisQWait is how long RedisQ holds a request open for a package.
+	redisQWait = 10 * time.Second
+	// redisQMatcherMaxAge is how often the tracked entities are reloaded.
+	redisQMatcherMaxAge = 5 * time.Minute
+	redisQMinBackoff    = 1 * time.Second
+	redisQMaxBackoff    = 2 * time.Minute
+)
+
+// StartRedisQConsumer consumes zKillboard's RedisQ until ctx is cancelled,
+// storing every kill that concerns a tracked character, corporation,
+// alliance or region. Failed requests are
+// retried with exponential backoff.
+func StartRedisQConsumer(ctx context.Context) {
+	queueID := os.Getenv("REDISQ_QUEUE_ID")
+	if queueID == "" {
+		hostname, _ := os.Hostname()
+		queueID = "eve-ran-" + hostname
+	}
+	client := services.NewRedisQClient(services.RedisQURL(), queueID, redisQWait)
+	utils.LogToConsole(fmt.Sprintf("Listening to RedisQ at %s as %s", services.RedisQURL(), queueID))
+
+	var matcher *killMatcher
+	backoff := redisQMinBackoff
+	for ctx.Err() == nil {
+		if matcher == nil || time.Since(matcher.loadedAt) > redisQMatcherMaxAge {
+			loaded, err := loadKillMatcher()
+			if err != nil {
+				utils.LogError(fmt.Sprintf("Error loading tracked entities for RedisQ: %v", err))
+			} else {
+				matcher = loaded
+			}
+		}
+
+		pkg, err := client.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			utils.LogError(fmt.Sprintf("Error reading RedisQ, retrying in %s: %v", backoff, err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, redisQMaxBackoff)
+			continue
+		}
+		backoff = redisQMinBackoff
+
+		if pkg == nil || matcher == nil {
+			continue
+		}
+		if err := storeRedisQPackage(pkg, matcher); err != nil {
+			utils.LogError(fmt.Sprintf("Error storing RedisQ kill %d: %v", pkg.KillID, err))
+		}
+	}
+}
+
+// storeRedisQPackage stores the Zkill and killmail of pkg if it matches.
+// Packages without an embedded killmail are completed from ESI. Members of
+// tracked entities are added to the tracked characters.
+func storeRedisQPackage(pkg *services.RedisQPackage, matcher *killMatcher) error {
+	var kill *models.Kill
+	var err error
+	if len(pkg.Killmail) > 0 && string(pkg.Killmail) != "null" {
+		kill, err = services.ParseKillmail(pkg.Killmail)
+	} else {
+		kill, err = services.FetchKillmailFromESI(pkg.KillID, pkg.ZKB.Hash)
+	}
+	if err != nil {
+		return err
+	}
+
+	stored, err := storeMatchedKill(kill, pkg.ZKB, matcher, false)
+	if err != nil || !stored {
+		return err
+	}
+
+	utils.LogToConsole(fmt.Sprintf("Stored kill %d from RedisQ", kill.KillmailID))
+	return nil
+}
@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/fakeupstream"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// fixturePackage returns the RedisQ package bundled with the fake upstream.
+func fixturePackage(t *testing.T) *services.RedisQPackage {
+	t.Helper()
+	data, err := fs.ReadFile(fakeupstream.Fixtures(), "redisq/0001.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	var pkg services.RedisQPackage
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		t.Fatalf("decoding fixture: %v", err)
+	}
+	return &pkg
+}
+
+func TestStoreRedisQPackageSkipsUntrackedKills(t *testing.T) {
+	setupTestDB(t)
+
+	if err := storeRedisQPackage(fixturePackage(t), newMatcher()); err != nil {
+		t.Fatalf("storeRedisQPackage: %v", err)
+	}
+	exists, err := repos.Kills.KillExists(fixtureKillmailID)
+	if err != nil {
+		t.Fatalf("KillExists: %v", err)
+	}
+	if exists {
+		t.Error("stored a kill that concerns nothing tracked")
+	}
+}
+
+func TestStoreRedisQPackageTracksEntityMembers(t *testing.T) {
+	setupTestDB(t)
+	setupFakeUpstream(t)
+
+	// The attacker is a member of a tracked alliance but not tracked yet
+	matcher := newMatcher()
+	matcher.alliances[99010001] = true
+	if err := storeRedisQPackage(fixturePackage(t), matcher); err != nil {
+		t.Fatalf("storeRedisQPackage: %v", err)
+	}
+
+	kill, err := repos.Kills.GetKillmail(fixtureKillmailID)
+	if err != nil || kill == nil {
+		t.Fatalf("GetKillmail = %v, %v", kill, err)
+	}
+	if kill.CharacterID != fixtureCharacterID || kill.Role != models.RoleAttacker {
+		t.Errorf("kill credited to %d as %q, want %d as %q", kill.CharacterID, kill.Role, fixtureCharacterID, models.RoleAttacker)
+	}
+	if kill.ZkillData.TotalValue != 10354567.8 {
+		t.Errorf("stored zkill value %v, want the package's", kill.ZkillData.TotalValue)
+	}
+
+	character, err := repos.Characters.GetCharacterByID(fixtureCharacterID)
+	if err != nil || character == nil {
+		t.Fatalf("GetCharacterByID = %v, %v, want the attacker tracked", character, err)
+	}
+	if !matcher.characters[fixtureCharacterID] {
+		t.Error("matcher does not track the added character")
+	}
+}
+
+func TestStoreRedisQPackageFetchesMissingKillmail(t *testing.T) {
+	setupTestDB(t)
+	setupFakeUpstream(t)
+
+	pkg := fixturePackage(t)
+	pkg.Killmail = nil
+	matcher := newMatcher()
+	matcher.characters[fixtureCharacterID] = true
+	if err := storeRedisQPackage(pkg, matcher); err != nil {
+		t.Fatalf("storeRedisQPackage: %v", err)
+	}
+
+	items, err := repos.Kills.GetKillItems(fixtureKillmailID)
+	if err != nil {
+		t.Fatalf("GetKillItems: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("stored %d items from the ESI killmail, want 2", len(items))
+	}
+}
+
+func TestRedisQConsumerBacksOffOnServerErrors(t *testing.T) {
+	setupTestDB(t)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+	}))
+	defer server.Close()
+	services.ConfigureUpstreams(services.Upstreams{RedisQURL: server.URL})
+	t.Cleanup(func() { services.ConfigureUpstreams(services.UpstreamsFromEnv()) })
+
+	// Requests go out at 0s, 1s and 3s as the backoff doubles
+	ctx, cancel := context.WithTimeout(context.Background(), 2*redisQMinBackoff+redisQMinBackoff/2)
+	defer cancel()
+	StartRedisQConsumer(ctx)
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("consumer sent %d requests in 2.5 backoff intervals, want 2", got)
+	}
+}
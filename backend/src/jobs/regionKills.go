@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+// regionBackfillSeconds limits the first ingestion of a tracked region to
+// the last week, the longest window zKillboard's pastSeconds accepts.
+const regionBackfillSeconds = 7 * 24 * 60 * 60
+
+// regionFeed returns the zKillboard list of every kill in the region. The
+// backfill only walks the last week of it.
+func regionFeed(regionID int, matcher *killMatcher) zkillFeed {
+	path := fmt.Sprintf("kills/regionID/%d", regionID)
+	return zkillFeed{
+		path:         path,
+		backfillPath: fmt.Sprintf("%s/pastSeconds/%d", path, regionBackfillSeconds),
+		store: func(entry services.ZKillEntry) error {
+			kill, err := services.FetchKillmailFromESI(entry.KillmailID, entry.ZKB.Hash)
+			if err != nil {
+				return err
+			}
+			_, err = storeMatchedKill(kill, entry.ZKB, matcher, true)
+			return err
+		},
+	}
+}
+
+// InitializeRegionKills stores every kill of the last week in a tracked
+// region, resuming an interrupted backfill.
+func InitializeRegionKills(regionID int) error {
+	matcher, err := loadKillMatcher()
+	if err != nil {
+		return err
+	}
+	return syncZKillFeed(context.Background(), regionFeed(regionID, matcher))
+}
+
+// checkNewRegionKills syncs the kills in every tracked region.
+func checkNewRegionKills() {
+	regions, err := repos.Universe.GetTrackedRegions()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error fetching tracked regions: %v", err))
+		return
+	}
+	if len(regions) == 0 {
+		return
+	}
+
+	matcher, err := loadKillMatcher()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error loading tracked entities: %v", err))
+		return
+	}
+	for _, region := range regions {
+		feed := regionFeed(region.RegionID, matcher)
+		if err := syncZKillFeed(context.Background(), feed); err != nil {
+			utils.LogError(fmt.Sprintf("Error fetching %s: %v", feed.path, err))
+		}
+	}
+}
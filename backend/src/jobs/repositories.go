@@ -0,0 +1,11 @@
+package jobs
+
+import "github.com/tadeasf/eve-ran/src/db/repository"
+
+var repos *repository.Repositories
+
+// ConfigureRepositories sets the repositories the jobs read and write
+// through. It must be called before any job is started.
+func ConfigureRepositories(r *repository.Repositories) {
+	repos = r
+}
@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/tadeasf/eve-ran/src/sde"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+// ImportSDE loads regions, constellations, systems, types and the type
+// taxonomy from the static data export dump at path into the database.
+func ImportSDE(path string) error {
+	utils.LogToConsole(fmt.Sprintf("Importing static data export from %s", path))
+
+	source, err := sde.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	universe, err := source.LoadUniverse()
+	if err != nil {
+		return fmt.Errorf("loading universe: %w", err)
+	}
+
+	for _, region := range universe.Regions {
+		if err := repos.Universe.UpsertRegion(region); err != nil {
+			return fmt.Errorf("storing region %d: %w", region.RegionID, err)
+		}
+	}
+
+	batchSize := 1000
+	for start := 0; start < len(universe.Constellations); start += batchSize {
+		end := min(start+batchSize, len(universe.Constellations))
+		if err := repos.Universe.BatchUpsertConstellations(universe.Constellations[start:end]); err != nil {
+			return fmt.Errorf("storing constellations: %w", err)
+		}
+	}
+	for start := 0; start < len(universe.Systems); start += batchSize {
+		end := min(start+batchSize, len(universe.Systems))
+		if err := repos.Universe.BatchUpsertSystems(universe.Systems[start:end]); err != nil {
+			return fmt.Errorf("storing systems: %w", err)
+		}
+	}
+
+	if err := repos.Universe.BatchUpsertStargates(universe.Stargates); err != nil {
+		return fmt.Errorf("storing stargates: %w", err)
+	}
+	services.ResetUniverseGraph()
+
+	items, err := source.LoadTypes()
+	if err != nil {
+		return fmt.Errorf("loading types: %w", err)
+	}
+	if err := repos.Items.BatchUpsertESIItems(items); err != nil {
+		return fmt.Errorf("storing types: %w", err)
+	}
+
+	taxonomy, err := source.LoadTaxonomy()
+	if err != nil {
+		return fmt.Errorf("loading type taxonomy: %w", err)
+	}
+	if err := repos.Items.BatchUpsertCategories(taxonomy.Categories); err != nil {
+		return fmt.Errorf("storing categories: %w", err)
+	}
+	if err := repos.Items.BatchUpsertGroups(taxonomy.Groups); err != nil {
+		return fmt.Errorf("storing groups: %w", err)
+	}
+	if err := repos.Items.BatchUpsertMarketGroups(taxonomy.MarketGroups); err != nil {
+		return fmt.Errorf("storing market groups: %w", err)
+	}
+
+	utils.LogToConsole(fmt.Sprintf("Imported %d regions, %d constellations, %d systems, %d stargates, %d types, %d categories, %d groups and %d market groups from the static data export",
+		len(universe.Regions), len(universe.Constellations), len(universe.Systems), len(universe.Stargates), len(items),
+		len(taxonomy.Categories), len(taxonomy.Groups), len(taxonomy.MarketGroups)))
+	return nil
+}
+
+// BootstrapUniverse fills the universe and type tables on startup. When
+// SDE_PATH points at a static data export it is imported first and ESI is
+// only asked for what the export is missing; otherwise everything is synced
+// from ESI.
+func BootstrapUniverse() {
+	path := os.Getenv("SDE_PATH")
+	if path == "" {
+		FetchAndUpdateTypes()
+		return
+	}
+
+	if err := ImportSDE(path); err != nil {
+		log.Printf("Error importing static data export, falling back to ESI: %v", err)
+		FetchAndUpdateTypes()
+		return
+	}
+	FetchMissingTypes()
+}
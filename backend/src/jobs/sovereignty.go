@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+func StartSovereigntyCron() {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		SnapshotSystemControl()
+		TagKillSpace()
+		<-ticker.C
+	}
+}
+
+// SnapshotSystemControl records the systems whose sovereignty or faction
+// warfare holders changed since the previous snapshot.
+func SnapshotSystemControl() {
+	control, err := services.FetchSystemControl(context.Background())
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error fetching sovereignty and faction warfare: %v", err))
+		return
+	}
+
+	current, err := repos.Universe.GetCurrentSystemControl()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error getting current system control: %v", err))
+		return
+	}
+
+	var changes []models.SystemControl
+	for systemID, entry := range control {
+		if previous, ok := current[systemID]; !ok || !previous.SameHolder(entry) {
+			changes = append(changes, entry)
+		}
+	}
+	// Systems that dropped out of both lists lost their holder
+	for systemID := range current {
+		if _, ok := control[systemID]; !ok && !current[systemID].SameHolder(models.SystemControl{}) {
+			changes = append(changes, models.SystemControl{SystemID: systemID})
+		}
+	}
+
+	if err := repos.Universe.RecordSystemControl(changes, time.Now().UTC()); err != nil {
+		utils.LogError(fmt.Sprintf("Error recording system control: %v", err))
+		return
+	}
+	if len(changes) > 0 {
+		utils.LogToConsole(fmt.Sprintf("Recorded control changes in %d systems", len(changes)))
+	}
+}
+
+// TagKillSpace tags stored kills with the holders of their system at the
+// time of the kill. Kills from before the first snapshot stay untagged.
+func TagKillSpace() {
+	tagged, err := repos.Kills.TagKillSpace()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error tagging kills with system control: %v", err))
+		return
+	}
+	if tagged > 0 {
+		utils.LogToConsole(fmt.Sprintf("Tagged %d kills with system control", tagged))
+	}
+}
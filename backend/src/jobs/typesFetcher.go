@@ -1,385 +1,272 @@
 package jobs
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"strconv"
-	"sync"
 	"time"
 
-	"github.com/tadeasf/eve-ran/src/db/models"
-	"github.com/tadeasf/eve-ran/src/db/queries"
 	"github.com/tadeasf/eve-ran/src/services"
 	"github.com/tadeasf/eve-ran/src/utils"
 )
 
-const (
-	baseURL = "https://esi.evetech.net/latest"
-)
+// SyncReport summarises a FetchAndUpdateTypes run per entity type.
+type SyncReport struct {
+	Regions        services.SyncStats `json:"regions"`
+	Constellations services.SyncStats `json:"constellations"`
+	Systems        services.SyncStats `json:"systems"`
+	Stargates      services.SyncStats `json:"stargates"`
+	Items          services.SyncStats `json:"items"`
+	Categories     services.SyncStats `json:"categories"`
+	Groups         services.SyncStats `json:"groups"`
+	MarketGroups   services.SyncStats `json:"market_groups"`
+}
 
-func FetchAndUpdateTypes() {
+// FetchAndUpdateTypes refreshes the regions, constellations, systems,
+// stargates and type taxonomy that changed on ESI and fetches the items the
+// database does not have yet. Stored items are only refreshed by an explicit
+// sync (POST /items/fetch), since checking all of them costs tens of
+// thousands of requests.
+func FetchAndUpdateTypes() SyncReport {
 	utils.LogToConsole("Starting FetchAndUpdateTypes job")
-	fetchAndUpdateRegions()
-	fetchAndUpdateConstellations()
-	fetchAndUpdateSystems()
-	fetchAndUpdateItems()
-	utils.LogToConsole("Finished FetchAndUpdateTypes job")
+	report := syncTypes(false)
+	utils.LogToConsole(fmt.Sprintf("Finished FetchAndUpdateTypes job: %+v", report))
+	return report
 }
 
-func fetchAndUpdateRegions() {
-	log.Println("Fetching and updating regions")
-	regions, err := services.FetchAllRegions(10)
-	if err != nil {
-		log.Printf("Error fetching regions: %v", err)
-		return
-	}
-
-	for _, region := range regions {
-		err := queries.UpsertRegion(region)
-		if err != nil {
-			log.Printf("Error upserting region %d: %v", region.RegionID, err)
-		}
-	}
-	log.Println("Finished fetching and updating regions")
+// FetchMissingTypes fetches only the regions, constellations, systems, items
+// and type taxonomy that ESI lists but the database does not have yet, e.g.
+// after the static data export was imported.
+func FetchMissingTypes() SyncReport {
+	utils.LogToConsole("Starting FetchMissingTypes job")
+	report := syncTypes(true)
+	utils.LogToConsole(fmt.Sprintf("Finished FetchMissingTypes job: %+v", report))
+	return report
 }
 
-func fetchAndUpdateConstellations() {
-	log.Println("Fetching and updating constellations")
-	url := baseURL + "/universe/constellations/"
-	ids := fetchIDs(url)
-
-	existingConstellations, _ := queries.GetAllConstellations()
-	existingMap := make(map[int]bool)
-	for _, constellation := range existingConstellations {
-		existingMap[constellation.ConstellationID] = true
-	}
-
-	constellationsChan := make(chan *models.Constellation, len(ids))
-	var wg sync.WaitGroup
-	rateLimiter := time.Tick(10 * time.Millisecond)
-
-	for _, id := range ids {
-		if !existingMap[id] {
-			wg.Add(1)
-			go func(id int) {
-				defer wg.Done()
-				<-rateLimiter // Wait for rate limiter
-				constellation := fetchConstellation(id)
-				if constellation != nil {
-					constellationsChan <- constellation
-				}
-			}(id)
+func syncTypes(missingOnly bool) SyncReport {
+	ctx := context.Background()
+	type storedIDs func(table, column string) (map[int]bool, error)
+	stored := func(get storedIDs, table, column string) func(int) bool {
+		ids, err := get(table, column)
+		if err != nil {
+			log.Printf("Error getting stored %s, fetching all of them: %v", table, err)
+			return nil
 		}
+		return func(id int) bool { return ids[id] }
 	}
-
-	go func() {
-		wg.Wait()
-		close(constellationsChan)
-	}()
-
-	batchSize := 250
-	var constellationsBatch []*models.Constellation
-
-	for constellation := range constellationsChan {
-		constellationsBatch = append(constellationsBatch, constellation)
-
-		if len(constellationsBatch) >= batchSize {
-			err := queries.BatchUpsertConstellations(constellationsBatch)
-			if err != nil {
-				log.Printf("Error batch upserting constellations: %v", err)
-			}
-			constellationsBatch = []*models.Constellation{}
+	skip := func(get storedIDs, table, column string) func(int) bool {
+		if !missingOnly {
+			return nil
 		}
+		return stored(get, table, column)
 	}
+	universe, items := repos.Universe.GetStoredIDs, repos.Items.GetStoredIDs
 
-	// Upsert any remaining constellations
-	if len(constellationsBatch) > 0 {
-		err := queries.BatchUpsertConstellations(constellationsBatch)
-		if err != nil {
-			log.Printf("Error batch upserting remaining constellations: %v", err)
-		}
+	defer services.ResetUniverseGraph()
+	return SyncReport{
+		Regions:        fetchAndUpdateRegions(ctx, skipRegions(missingOnly)),
+		Constellations: fetchAndUpdateConstellations(ctx, skip(universe, "constellations", "constellation_id")),
+		Systems:        fetchAndUpdateSystems(ctx, skip(universe, "systems", "system_id")),
+		Stargates:      fetchAndUpdateStargates(ctx, skip(universe, "stargates", "stargate_id")),
+		Items:          fetchAndUpdateItems(ctx, stored(items, "esi_items", "type_id")),
+		Categories:     fetchAndUpdateCategories(ctx, skip(items, "item_categories", "category_id")),
+		Groups:         fetchAndUpdateGroups(ctx, skip(items, "item_groups", "group_id")),
+		MarketGroups:   fetchAndUpdateMarketGroups(ctx, skip(items, "market_groups", "market_group_id")),
 	}
-
-	log.Println("Finished fetching and updating constellations")
 }
 
-func fetchConstellation(id int) *models.Constellation {
-	url := baseURL + "/universe/constellations/" + strconv.Itoa(id) + "/"
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	resp, err := client.Get(url)
-	if err != nil {
-		log.Printf("Error fetching constellation %d: %v", id, err)
+// skipRegions reports the stored regions that have a description when only
+// missing entities are synced. The static data export has no descriptions,
+// so regions imported from it are still fetched from ESI.
+func skipRegions(missingOnly bool) func(int) bool {
+	if !missingOnly {
 		return nil
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Error fetching constellation %d: Status code %d", id, resp.StatusCode)
-		return nil
-	}
-
-	body, err := io.ReadAll(resp.Body)
+	regions, err := repos.Universe.GetAllRegions()
 	if err != nil {
-		log.Printf("Error reading response body for constellation %d: %v", id, err)
+		log.Printf("Error getting stored regions, fetching all of them: %v", err)
 		return nil
 	}
-
-	var constellation models.Constellation
-	err = json.Unmarshal(body, &constellation)
-	if err != nil {
-		log.Printf("Error unmarshaling constellation %d: %v", id, err)
-		return nil
+	described := make(map[int]bool, len(regions))
+	for _, region := range regions {
+		described[region.RegionID] = region.Description != ""
 	}
+	return func(id int) bool { return described[id] }
+}
 
-	return &constellation
+// syncOptions returns the bulk fetch options for entity, logging progress
+// every step completed fetches and skipping the IDs skip reports.
+func syncOptions(entity string, concurrency, step int, skip func(int) bool) services.BulkOptions {
+	return services.BulkOptions{
+		Concurrency: concurrency,
+		Retries:     3,
+		RetryDelay:  5 * time.Second,
+		Skip:        skip,
+		Progress: func(done, total int) {
+			if done%step == 0 || done == total {
+				log.Printf("Fetched %d/%d %s", done, total, entity)
+			}
+		},
+	}
 }
 
-func fetchAndUpdateSystems() {
-	log.Println("Fetching and updating systems")
-	url := baseURL + "/universe/systems/"
-	ids := fetchIDs(url)
+// commitFetched writes the ESI cache entries of stored entities. A failed
+// write only costs a full fetch on the next sync.
+func commitFetched[T any](fetched ...services.Fetched[T]) {
+	if err := services.CommitFetched(fetched); err != nil {
+		log.Printf("Error writing ESI cache: %v", err)
+	}
+}
 
-	existingSystems, _ := queries.GetAllSystems()
-	existingMap := make(map[int]bool)
-	for _, system := range existingSystems {
-		existingMap[system.SystemID] = true
+func fetchAndUpdateRegions(ctx context.Context, skip func(int) bool) services.SyncStats {
+	log.Println("Fetching and updating regions")
+	regions, stats, err := services.FetchAllRegions(ctx, syncOptions("regions", 10, 50, skip))
+	if err != nil {
+		log.Printf("Error fetching regions: %v", err)
 	}
 
-	systemsChan := make(chan *models.System, len(ids))
-	var wg sync.WaitGroup
-	rateLimiter := time.Tick(5 * time.Millisecond)
-
-	for _, id := range ids {
-		if !existingMap[id] {
-			wg.Add(1)
-			go func(id int) {
-				defer wg.Done()
-				<-rateLimiter // Wait for rate limiter
-				system := fetchSystem(id)
-				if system != nil {
-					// Fetch constellation to get region_id
-					constellation, err := services.FetchConstellation(system.ConstellationID)
-					if err == nil {
-						system.RegionID = constellation.RegionID
-					}
-					systemsChan <- system
-				}
-			}(id)
+	for _, region := range regions {
+		if err := repos.Universe.UpsertRegion(region.Value); err != nil {
+			log.Printf("Error upserting region %d: %v", region.Value.RegionID, err)
+			continue
 		}
+		commitFetched(region)
 	}
+	log.Printf("Finished fetching and updating regions: %d checked, %d changed", stats.Checked, stats.Changed)
+	return stats
+}
 
-	go func() {
-		wg.Wait()
-		close(systemsChan)
-	}()
-
-	batchSize := 1000
-	var systemsBatch []*models.System
-
-	for system := range systemsChan {
-		systemsBatch = append(systemsBatch, system)
-
-		if len(systemsBatch) >= batchSize {
-			err := queries.BatchUpsertSystems(systemsBatch)
-			if err != nil {
-				log.Printf("Error batch upserting systems: %v", err)
-			}
-			systemsBatch = []*models.System{}
-		}
+func fetchAndUpdateConstellations(ctx context.Context, skip func(int) bool) services.SyncStats {
+	log.Println("Fetching and updating constellations")
+	constellations, stats, err := services.FetchAllConstellations(ctx, syncOptions("constellations", 20, 250, skip))
+	if err != nil {
+		log.Printf("Error fetching constellations: %v", err)
 	}
 
-	// Upsert any remaining systems
-	if len(systemsBatch) > 0 {
-		err := queries.BatchUpsertSystems(systemsBatch)
-		if err != nil {
-			log.Printf("Error batch upserting remaining systems: %v", err)
+	batchSize := 250
+	for start := 0; start < len(constellations); start += batchSize {
+		end := min(start+batchSize, len(constellations))
+		batch := constellations[start:end]
+		if err := repos.Universe.BatchUpsertConstellations(services.FetchedValues(batch)); err != nil {
+			log.Printf("Error batch upserting constellations: %v", err)
+			continue
 		}
+		commitFetched(batch...)
 	}
 
-	log.Println("Finished fetching and updating systems")
+	log.Printf("Finished fetching and updating constellations: %d checked, %d changed", stats.Checked, stats.Changed)
+	return stats
 }
 
-func fetchSystem(id int) *models.System {
-	url := baseURL + "/universe/systems/" + strconv.Itoa(id) + "/"
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	resp, err := client.Get(url)
+func fetchAndUpdateSystems(ctx context.Context, skip func(int) bool) services.SyncStats {
+	log.Println("Fetching and updating systems")
+	systems, stats, err := services.FetchAllSystems(ctx, syncOptions("systems", 20, 1000, skip))
 	if err != nil {
-		log.Printf("Error fetching system %d: %v", id, err)
-		return nil
+		log.Printf("Error fetching systems: %v", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Error fetching system %d: Status code %d", id, resp.StatusCode)
-		return nil
+	batchSize := 1000
+	for start := 0; start < len(systems); start += batchSize {
+		end := min(start+batchSize, len(systems))
+		batch := systems[start:end]
+		if err := repos.Universe.BatchUpsertSystems(services.FetchedValues(batch)); err != nil {
+			log.Printf("Error batch upserting systems: %v", err)
+			continue
+		}
+		commitFetched(batch...)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Error reading response body for system %d: %v", id, err)
-		return nil
-	}
+	log.Printf("Finished fetching and updating systems: %d checked, %d changed", stats.Checked, stats.Changed)
+	return stats
+}
 
-	var system models.System
-	err = json.Unmarshal(body, &system)
+func fetchAndUpdateStargates(ctx context.Context, skip func(int) bool) services.SyncStats {
+	log.Println("Fetching and updating stargates")
+	stargates, stats, err := services.FetchAllStargates(ctx, syncOptions("stargates", 20, 1000, skip))
 	if err != nil {
-		log.Printf("Error unmarshaling system %d: %v", id, err)
-		return nil
+		log.Printf("Error fetching stargates: %v", err)
 	}
 
-	// Fetch constellation to get region_id
-	constellation, err := services.FetchConstellation(system.ConstellationID)
-	if err == nil {
-		system.RegionID = constellation.RegionID
+	if err := repos.Universe.BatchUpsertStargates(services.FetchedValues(stargates)); err != nil {
+		log.Printf("Error batch upserting stargates: %v", err)
 	} else {
-		log.Printf("Error fetching constellation for system %d: %v", id, err)
+		commitFetched(stargates...)
 	}
 
-	return &system
+	log.Printf("Finished fetching and updating stargates: %d checked, %d changed", stats.Checked, stats.Changed)
+	return stats
 }
 
-func fetchAndUpdateItems() {
+func fetchAndUpdateItems(ctx context.Context, skip func(int) bool) services.SyncStats {
 	log.Println("Fetching and updating items")
-	baseURL := baseURL + "/universe/types/"
-
-	existingItems, _ := queries.GetAllESIItems()
-	existingMap := make(map[int]bool)
-	for _, item := range existingItems {
-		existingMap[item.TypeID] = true
-	}
-
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 50)
-	itemIDsChan := make(chan int, 100)
-
-	// Start worker goroutines
-	for i := 0; i < 20; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for id := range itemIDsChan {
-				semaphore <- struct{}{}
-				fetchAndSaveItem(id)
-				<-semaphore
-			}
-		}()
+	items, stats, err := services.FetchAllItems(ctx, syncOptions("items", 50, 5000, skip))
+	if err != nil {
+		log.Printf("Error fetching items: %v", err)
 	}
 
-	page := 1
-	for {
-		itemIDs, err := fetchItemIDsWithPagination(baseURL, page)
-		if err != nil {
-			if err.Error() == "requested page does not exist" {
-				log.Println("Reached the end of item pages")
-				break
-			}
-			log.Printf("Error fetching item IDs for page %d: %v", page, err)
-			break
+	for _, item := range items {
+		if item.Value.TypeID == 0 {
+			continue
 		}
-
-		for _, id := range itemIDs {
-			if !existingMap[id] {
-				itemIDsChan <- id
-			}
+		if err := repos.Items.UpsertESIItem(item.Value); err != nil {
+			log.Printf("Error upserting item %d: %v", item.Value.TypeID, err)
+			continue
 		}
-
-		page++
-		time.Sleep(100 * time.Millisecond) // Small delay to avoid hitting rate limits
+		commitFetched(item)
 	}
 
-	close(itemIDsChan)
-	wg.Wait()
-
-	log.Println("Finished fetching and updating items")
+	log.Printf("Finished fetching and updating items: %d checked, %d changed", stats.Checked, stats.Changed)
+	return stats
 }
 
-func fetchItemIDsWithPagination(baseURL string, page int) ([]int, error) {
-	url := fmt.Sprintf("%s?datasource=tranquility&page=%d", baseURL, page)
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Cache-Control", "no-cache")
-
-	resp, err := client.Do(req)
+func fetchAndUpdateCategories(ctx context.Context, skip func(int) bool) services.SyncStats {
+	log.Println("Fetching and updating categories")
+	categories, stats, err := services.FetchAllCategories(ctx, syncOptions("categories", 10, 50, skip))
 	if err != nil {
-		return nil, err
+		log.Printf("Error fetching categories: %v", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("requested page does not exist")
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var ids []int
-	err = json.Unmarshal(body, &ids)
-	if err != nil {
-		return nil, err
+	if err := repos.Items.BatchUpsertCategories(services.FetchedValues(categories)); err != nil {
+		log.Printf("Error batch upserting categories: %v", err)
+	} else {
+		commitFetched(categories...)
 	}
 
-	return ids, nil
+	log.Printf("Finished fetching and updating categories: %d checked, %d changed", stats.Checked, stats.Changed)
+	return stats
 }
 
-func fetchAndSaveItem(id int) {
-	if id == 0 {
-		log.Printf("Skipping item with ID 0")
-		return
-	}
-	url := fmt.Sprintf("%s/universe/types/%d/?datasource=tranquility&language=en", baseURL, id)
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		log.Printf("Error creating request for item %d: %v", id, err)
-		return
-	}
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-Language", "en")
-	req.Header.Set("Cache-Control", "no-cache")
-
-	resp, err := client.Do(req)
+func fetchAndUpdateGroups(ctx context.Context, skip func(int) bool) services.SyncStats {
+	log.Println("Fetching and updating groups")
+	groups, stats, err := services.FetchAllGroups(ctx, syncOptions("groups", 20, 250, skip))
 	if err != nil {
-		log.Printf("Error fetching item %d: %v", id, err)
-		return
+		log.Printf("Error fetching groups: %v", err)
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-	var item models.ESIItem
-	err = json.Unmarshal(body, &item)
-	if err != nil {
-		log.Printf("Error unmarshaling item %d: %v", id, err)
-		return
+	if err := repos.Items.BatchUpsertGroups(services.FetchedValues(groups)); err != nil {
+		log.Printf("Error batch upserting groups: %v", err)
+	} else {
+		commitFetched(groups...)
 	}
 
-	err = queries.UpsertESIItem(&item)
-	if err != nil {
-		log.Printf("Error upserting item %d: %v", id, err)
-	}
+	log.Printf("Finished fetching and updating groups: %d checked, %d changed", stats.Checked, stats.Changed)
+	return stats
 }
 
-func fetchIDs(url string) []int {
-	resp, err := http.Get(url)
+func fetchAndUpdateMarketGroups(ctx context.Context, skip func(int) bool) services.SyncStats {
+	log.Println("Fetching and updating market groups")
+	marketGroups, stats, err := services.FetchAllMarketGroups(ctx, syncOptions("market groups", 20, 500, skip))
 	if err != nil {
-		log.Printf("Error fetching IDs from %s: %v", url, err)
-		return nil
+		log.Printf("Error fetching market groups: %v", err)
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-	var ids []int
-	json.Unmarshal(body, &ids)
+	if err := repos.Items.BatchUpsertMarketGroups(services.FetchedValues(marketGroups)); err != nil {
+		log.Printf("Error batch upserting market groups: %v", err)
+	} else {
+		commitFetched(marketGroups...)
+	}
 
-	return ids
+	log.Printf("Finished fetching and updating market groups: %d checked, %d changed", stats.Checked, stats.Changed)
+	return stats
 }
@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+// valuationBatchSize is the number of kills valued per query.
+const valuationBatchSize = 1000
+
+func StartValuationCron() {
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		SyncMarketPrices()
+		ValueKills()
+		<-ticker.C
+	}
+}
+
+// SyncMarketPrices stores the ESI market prices when they changed.
+func SyncMarketPrices() {
+	prices, changed, commit, err := services.FetchMarketPrices(context.Background())
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error fetching market prices: %v", err))
+		return
+	}
+	if changed {
+		if err := repos.Prices.UpsertMarketPrices(prices); err != nil {
+			utils.LogError(fmt.Sprintf("Error storing market prices: %v", err))
+			return
+		}
+		utils.LogToConsole(fmt.Sprintf("Stored %d market prices", len(prices)))
+	}
+	if err := commit(); err != nil {
+		utils.LogError(err.Error())
+	}
+}
+
+// ValueKills computes our own valuation for every kill that has none yet.
+func ValueKills() {
+	prices, err := repos.Prices.GetPriceMap()
+	if err != nil {
+		utils.LogError(fmt.Sprintf("Error loading market prices: %v", err))
+		return
+	}
+	if len(prices) == 0 {
+		return
+	}
+
+	valued := 0
+	for {
+		kills, err := repos.Kills.GetUnvaluedKills(valuationBatchSize)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("Error fetching kills to value: %v", err))
+			break
+		}
+		if len(kills) == 0 {
+			break
+		}
+
+		for _, kill := range kills {
+			value := services.ValueKill(kill.Victim, func(typeID int) float64 { return prices[typeID] })
+			if err := repos.Kills.UpdateKillValue(kill.KillmailID, value); err != nil {
+				utils.LogError(fmt.Sprintf("Error storing value of kill %d: %v", kill.KillmailID, err))
+				return
+			}
+			valued++
+		}
+	}
+
+	if valued > 0 {
+		utils.LogToConsole(fmt.Sprintf("Valued %d kills", valued))
+	}
+}
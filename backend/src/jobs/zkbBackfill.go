@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+const zkbBackfillBatchSize = 100
+
+// BackfillZKillLabels fetches the solo and awox flags and labels of
+// killmails stored before they were parsed. Killmails zKillboard no longer
+// knows are stored without labels so they are not asked for again.
+func BackfillZKillLabels() {
+	ctx := context.Background()
+	backfilled := 0
+	for {
+		ids, err := repos.ZKills.GetZKillsWithoutLabels(zkbBackfillBatchSize)
+		if err != nil {
+			utils.LogError(fmt.Sprintf("Error fetching killmails without labels: %v", err))
+			return
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			entry, err := services.ZKill.Killmail(ctx, id)
+			if err != nil {
+				utils.LogError(fmt.Sprintf("Error fetching zkb of killmail %d, stopping label backfill: %v", id, err))
+				return
+			}
+
+			var solo, awox bool
+			labels := models.StringArray{}
+			if entry != nil {
+				solo, awox = entry.ZKB.Solo, entry.ZKB.Awox
+				labels = append(labels, entry.ZKB.Labels...)
+			}
+			if err := repos.ZKills.UpdateZKillLabels(id, solo, awox, labels); err != nil {
+				utils.LogError(fmt.Sprintf("Error storing labels of killmail %d: %v", id, err))
+				return
+			}
+			backfilled++
+		}
+		utils.LogToConsole(fmt.Sprintf("Backfilled zkb labels of %d killmails", backfilled))
+	}
+}
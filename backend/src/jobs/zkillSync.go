@@ -0,0 +1,109 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+// zkillFeed is a zKillboard API list kept in sync through a cursor.
+type zkillFeed struct {
+	// path is the list, e.g. kills/characterID/123, and the cursor key
+	path string
+	// backfillPath is walked instead of path by the backfill when set
+	backfillPath string
+	// store stores a killmail of the list that is not stored yet
+	store func(entry services.ZKillEntry) error
+}
+
+// syncZKillFeed stores the killmails of feed newer than its cursor and then
+// continues the backfill where it stopped. The cursor is saved after every
+// page, so a restart resumes instead of starting over from page 1.
+func syncZKillFeed(ctx context.Context, feed zkillFeed) error {
+	cursor, err := repos.ZKills.GetZKillCursor(feed.path)
+	if err != nil {
+		return err
+	}
+
+	// zKillboard lists are newest first, so new killmails end at the
+	// first one already seen. A list that was still empty when its
+	// backfill finished is walked until its first empty page.
+	if cursor.LastKillmailID > 0 || cursor.BackfillDone {
+		newest := cursor.LastKillmailID
+		for page := 1; ; page++ {
+			entries, err := services.ZKill.Entries(ctx, feed.path, page)
+			if err != nil {
+				return err
+			}
+
+			var fresh []services.ZKillEntry
+			reached := len(entries) == 0
+			for _, entry := range entries {
+				if entry.KillmailID <= cursor.LastKillmailID {
+					reached = true
+					continue
+				}
+				fresh = append(fresh, entry)
+				newest = max(newest, entry.KillmailID)
+			}
+			if err := storeZKillEntries(feed, fresh); err != nil {
+				return err
+			}
+			if reached {
+				break
+			}
+		}
+
+		cursor.LastKillmailID = newest
+		if err := repos.ZKills.SaveZKillCursor(cursor); err != nil {
+			return err
+		}
+	}
+
+	path := feed.path
+	if feed.backfillPath != "" {
+		path = feed.backfillPath
+	}
+	for page := cursor.LastPage + 1; !cursor.BackfillDone; page++ {
+		entries, err := services.ZKill.Entries(ctx, path, page)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			cursor.BackfillDone = true
+		} else {
+			if err := storeZKillEntries(feed, entries); err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				cursor.LastKillmailID = max(cursor.LastKillmailID, entry.KillmailID)
+			}
+			cursor.LastPage = page
+		}
+		if err := repos.ZKills.SaveZKillCursor(cursor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeZKillEntries stores the entries that are not stored yet. Entries
+// that fail are logged and skipped.
+func storeZKillEntries(feed zkillFeed, entries []services.ZKillEntry) error {
+	for _, entry := range entries {
+		exists, err := repos.ZKills.ZKillExists(entry.KillmailID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if err := feed.store(entry); err != nil {
+			utils.LogError(fmt.Sprintf("Error storing kill %d of %s: %v", entry.KillmailID, feed.path, err))
+		}
+	}
+	return nil
+}
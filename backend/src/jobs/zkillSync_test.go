@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/tadeasf/eve-ran/src/fakeupstream"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// TestSyncZKillFeedPollsListEmptyAtBackfill covers a list that was still
+// empty when its backfill finished: its first killmail must be picked up.
+func TestSyncZKillFeedPollsListEmptyAtBackfill(t *testing.T) {
+	setupTestDB(t)
+
+	fixtures := fstest.MapFS{}
+	server := httptest.NewServer(fakeupstream.NewHandler(fixtures))
+	defer server.Close()
+	services.ConfigureUpstreams(fakeupstream.UpstreamsFor(server.URL))
+	t.Cleanup(func() { services.ConfigureUpstreams(services.UpstreamsFromEnv()) })
+
+	var stored []int64
+	feed := zkillFeed{
+		path: "kills/characterID/1",
+		store: func(entry services.ZKillEntry) error {
+			stored = append(stored, entry.KillmailID)
+			return nil
+		},
+	}
+
+	if err := syncZKillFeed(context.Background(), feed); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	cursor, err := repos.ZKills.GetZKillCursor(feed.path)
+	if err != nil {
+		t.Fatalf("GetZKillCursor: %v", err)
+	}
+	if !cursor.BackfillDone || cursor.LastKillmailID != 0 {
+		t.Fatalf("cursor after the empty backfill = %+v, want done without a killmail", cursor)
+	}
+
+	fixtures["zkill/api/kills/characterID/1/page/1.json"] = &fstest.MapFile{
+		Data: []byte(`[{"killmail_id":7,"zkb":{"hash":"abc"}}]`),
+	}
+	if err := syncZKillFeed(context.Background(), feed); err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	if len(stored) != 1 || stored[0] != 7 {
+		t.Fatalf("stored %v, want the new killmail 7", stored)
+	}
+	cursor, err = repos.ZKills.GetZKillCursor(feed.path)
+	if err != nil {
+		t.Fatalf("GetZKillCursor: %v", err)
+	}
+	if cursor.LastKillmailID != 7 {
+		t.Errorf("cursor points at %d, want 7", cursor.LastKillmailID)
+	}
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
-	"time"
+	"context"
+	"log"
+	"os"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "github.com/tadeasf/eve-ran/docs"
 	"github.com/tadeasf/eve-ran/src/db"
+	"github.com/tadeasf/eve-ran/src/db/repository"
 	"github.com/tadeasf/eve-ran/src/jobs"
 	"github.com/tadeasf/eve-ran/src/routes"
+	"github.com/tadeasf/eve-ran/src/services"
 	"github.com/tadeasf/eve-ran/src/utils"
 )
 
@@ -26,22 +30,62 @@ func main() {
 
 	db.InitDB()
 
-	// Add a delay to allow initial data to be stored
-	time.Sleep(1 * time.Minute)
+	// migrate [up | down [steps] | to <version> | status] manages the schema
+	// and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	if err := db.Migrate(); err != nil {
+		log.Fatalf("Failed to migrate schema: %v", err)
+	}
+
+	repos := repository.New(db.DB)
+	routes.ConfigureRepositories(repos)
+	jobs.ConfigureRepositories(repos)
+	services.ConfigureRepositories(repos)
+
+	services.ConfigureUpstreams(services.UpstreamsFromEnv())
+
+	// Cache ESI responses so restarts only re-download what changed
+	services.ESI.SetCache(repos.ESICache)
+
+	// sync-sde <path> imports a static data export and exits
+	if len(os.Args) > 1 && os.Args[1] == "sync-sde" {
+		if len(os.Args) != 3 {
+			log.Fatal("Usage: sync-sde <path to SDE dump directory or zip>")
+		}
+		if err := jobs.ImportSDE(os.Args[2]); err != nil {
+			log.Fatalf("Failed to import static data export: %v", err)
+		}
+		return
+	}
 
-	// Run the type fetcher job
-	jobs.FetchAndUpdateTypes()
+	// Fill the universe and type tables
+	go jobs.BootstrapUniverse()
 
 	// Start the kill cron job
 	go jobs.StartKillCron()
+	go jobs.BackfillZKillLabels()
 
-	// Start the kill enhancement job
-	go func() {
-		for {
-			jobs.EnhanceKills()
-			time.Sleep(1 * time.Minute) // Run every 15 minutes
-		}
-	}()
+	// Start the corporation and alliance sync job
+	go jobs.StartEntitySyncCron()
+	go jobs.StartAffiliationCron()
+
+	// Start the market price, price history and kill valuation jobs
+	go jobs.StartValuationCron()
+	go jobs.StartPriceHistoryCron()
+
+	// Start the sovereignty and faction warfare snapshot job
+	go jobs.StartSovereigntyCron()
+
+	// Consume RedisQ for kills as they happen
+	go jobs.StartRedisQConsumer(context.Background())
+
+	// Start the kill enrichment workers
+	go jobs.StartEnrichmentWorkers()
 
 	r := gin.Default()
 
@@ -49,6 +93,7 @@ func main() {
 	r.POST("/characters", routes.AddCharacter)
 	r.DELETE("/characters/:id", routes.RemoveCharacter)
 	r.GET("/characters/:id/kills/db", routes.GetCharacterKillsFromDB)
+	r.GET("/characters/:id/losses/db", routes.GetCharacterLossesFromDB)
 
 	// Region routes
 	r.POST("/regions/fetch", routes.FetchAndStoreRegions)
@@ -59,6 +104,12 @@ func main() {
 	r.GET("/systems", routes.GetAllSystems)
 	r.GET("/systems/:id", routes.GetSystemByID)
 	r.GET("/systems/region/:regionID", routes.GetSystemsByRegion)
+	r.GET("/systems/:id/within/:jumps", routes.GetSystemsWithinJumps)
+	r.GET("/systems/:id/control", routes.GetSystemControlHistory)
+
+	// Stargate and routing routes
+	r.POST("/stargates/fetch", routes.FetchAndStoreStargates)
+	r.GET("/route/:from/:to", routes.GetRoute)
 
 	// Constellation routes
 	r.POST("/constellations/fetch", routes.FetchAndStoreConstellations)
@@ -70,17 +121,61 @@ func main() {
 	r.POST("/items/fetch", routes.FetchAndStoreItems)
 	r.GET("/items", routes.GetAllItems)
 	r.GET("/items/:typeID", routes.GetItemByTypeID)
+	r.GET("/items/:typeID/history", routes.GetItemPriceHistory)
+
+	// Type taxonomy routes
+	r.POST("/categories/fetch", routes.FetchAndStoreCategories)
+	r.GET("/categories", routes.GetAllCategories)
+	r.GET("/categories/:id", routes.GetCategoryByID)
+	r.POST("/groups/fetch", routes.FetchAndStoreGroups)
+	r.GET("/groups", routes.GetAllGroups)
+	r.GET("/groups/:id", routes.GetGroupByID)
+	r.POST("/market-groups/fetch", routes.FetchAndStoreMarketGroups)
+	r.GET("/market-groups", routes.GetAllMarketGroups)
 
 	// New routes
 	r.GET("/characters/:id/killmails", routes.GetCharacterKillmails)
 	r.GET("/characters/stats", routes.GetAllCharacterStats)
+	r.GET("/characters/:id/affiliations", routes.GetCharacterAffiliations)
 
 	// New data routes
 	r.GET("/characters", routes.GetAllCharacters)
 	r.GET("/kills", routes.GetAllKills)
+	r.GET("/losses", routes.GetAllLosses)
 
 	// Add this line to register the GetKillsByRegion route
 	r.GET("/kills/region/:regionID", routes.GetKillsByRegion)
+	r.GET("/kills/space", routes.GetKillSpaceBreakdown)
+	r.GET("/kills/:killmailID", routes.GetKillmail)
+	r.GET("/kills/:killmailID/items", routes.GetKillmailItems)
+	r.GET("/kills/:killmailID/attackers", routes.GetKillmailAttackers)
+	r.GET("/kills/:killmailID/participants", routes.GetKillmailParticipants)
+
+	// Attacker stats routes
+	r.GET("/attackers/stats", routes.GetAttackerStats)
+	r.GET("/attackers/ships", routes.GetShipUsage)
+
+	// Tracked corporation and alliance routes
+	r.GET("/tracked-entities", routes.GetTrackedEntities)
+	r.POST("/tracked-entities", routes.AddTrackedEntity)
+	r.DELETE("/tracked-entities/:type/:id", routes.RemoveTrackedEntity)
+
+	// Tracked region routes
+	r.GET("/tracked-regions", routes.GetTrackedRegions)
+	r.POST("/tracked-regions", routes.AddTrackedRegion)
+	r.DELETE("/tracked-regions/:id", routes.RemoveTrackedRegion)
+
+	// Enrichment queue routes
+	r.GET("/enrichment/jobs", routes.GetEnrichmentJobs)
+	r.GET("/enrichment/summary", routes.GetEnrichmentSummary)
+	r.POST("/enrichment/requeue", routes.RequeueEnrichmentJobs)
+
+	// Name resolution routes
+	r.POST("/names", routes.ResolveNames)
+
+	// Corporation and alliance routes
+	r.GET("/corporations/:id", routes.GetCorporation)
+	r.GET("/alliances/:id", routes.GetAlliance)
 
 	// Setup Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
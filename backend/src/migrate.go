@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tadeasf/eve-ran/src/db"
+)
+
+const migrateUsage = "Usage: migrate [up | down [steps] [--force] | to <version> [--force] | status]"
+
+// runMigrateCommand runs the migrate subcommand with its arguments.
+// Without arguments it applies every pending migration. --force allows
+// reverting destructive migrations such as the baseline.
+func runMigrateCommand(args []string) error {
+	force := false
+	var positional []string
+	for _, arg := range args {
+		if arg == "--force" {
+			force = true
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+	args = positional
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch {
+	case command == "up" && len(args) <= 1:
+		return db.Migrate()
+	case command == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		return db.Rollback(steps, force)
+	case command == "to" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return db.MigrateTo(version, force)
+	case command == "status" && len(args) == 1:
+		status, err := db.GetMigrationStatus()
+		if err != nil {
+			return err
+		}
+		for _, migration := range status {
+			applied := "pending"
+			if migration.AppliedAt != nil {
+				applied = "applied " + migration.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d  %-30s %s\n", migration.Version, migration.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s", migrateUsage)
+	}
+}
@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/repository"
+)
+
+// GetAttackerStats ranks characters by what they did as attackers
+// @Summary Get attacker stats
+// @Description Rank characters on the matching kills by final blows, kills on which they dealt the most damage, total damage or kills
+// @Tags kills
+// @Produce json
+// @Param regionID query []int false "Region IDs"
+// @Param startDate query string false "Start date (YYYY-MM-DD)"
+// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Param shipGroup query []string false "Victim ship group IDs or names"
+// @Param shipCategory query []string false "Victim ship category IDs or names"
+// @Param nearSystem query int false "Only kills within jumps of this system"
+// @Param jumps query int false "Jump radius around nearSystem (default 0)"
+// @Param sovAlliance query int false "Only kills in space held by this alliance"
+// @Param fwOccupier query int false "Only kills in faction warfare space occupied by this faction"
+// @Param labels query string false "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)"
+// @Param orderBy query string false "finalBlows (default), topDamage, damage or kills"
+// @Param limit query int false "Number of characters (default 100)"
+// @Success 200 {array} models.AttackerStats
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /attackers/stats [get]
+func GetAttackerStats(c *gin.Context) {
+	filter, err := parseKillFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order := repository.AttackerStatsOrder(c.DefaultQuery("orderBy", string(repository.OrderByFinalBlows)))
+	switch order {
+	case repository.OrderByFinalBlows, repository.OrderByTopDamage, repository.OrderByDamage, repository.OrderByKills:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid order %q, expected finalBlows, topDamage, damage or kills", order)})
+		return
+	}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := repos.Kills.GetAttackerStats(filter, order, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetShipUsage ranks the ships flown by attackers
+// @Summary Get attacker ship usage
+// @Description Count how often each ship type was flown by the attackers of the matching kills, with distinct pilots, final blows and damage
+// @Tags kills
+// @Produce json
+// @Param regionID query []int false "Region IDs"
+// @Param startDate query string false "Start date (YYYY-MM-DD)"
+// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Param shipGroup query []string false "Victim ship group IDs or names"
+// @Param shipCategory query []string false "Victim ship category IDs or names"
+// @Param nearSystem query int false "Only kills within jumps of this system"
+// @Param jumps query int false "Jump radius around nearSystem (default 0)"
+// @Param sovAlliance query int false "Only kills in space held by this alliance"
+// @Param fwOccupier query int false "Only kills in faction warfare space occupied by this faction"
+// @Param labels query string false "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)"
+// @Param limit query int false "Number of ship types (default 100)"
+// @Success 200 {array} models.ShipUsage
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /attackers/ships [get]
+func GetShipUsage(c *gin.Context) {
+	filter, err := parseKillFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage, err := repos.Kills.GetShipUsage(filter, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// GetKillmailAttackers returns the attackers of a kill
+// @Summary Get the attackers of a killmail
+// @Description Fetch the attackers of a stored kill in killmail order
+// @Tags kills
+// @Produce json
+// @Param killmailID path int true "Killmail ID"
+// @Success 200 {array} models.KillAttacker
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /kills/{killmailID}/attackers [get]
+func GetKillmailAttackers(c *gin.Context) {
+	killmailID, err := strconv.ParseInt(c.Param("killmailID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid killmail ID"})
+		return
+	}
+
+	exists, err := repos.Kills.KillExists(killmailID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kill not found"})
+		return
+	}
+
+	attackers, err := repos.Kills.GetKillAttackers(killmailID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attackers)
+}
+
+// parseLimit reads the limit query parameter, defaulting to 100.
+func parseLimit(c *gin.Context) (int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("Invalid limit %q", c.Query("limit"))
+	}
+	return limit, nil
+}
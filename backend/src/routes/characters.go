@@ -3,10 +3,9 @@ package routes
 import (
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/tadeasf/eve-ran/src/db/queries"
+	"github.com/tadeasf/eve-ran/src/db/models"
 )
 
 // GetAllCharacters retrieves all characters from the database
@@ -19,7 +18,7 @@ import (
 // @Failure 500 {object} models.ErrorResponse
 // @Router /characters [get]
 func GetAllCharacters(c *gin.Context) {
-	characters, err := queries.GetAllCharacters()
+	characters, err := repos.Characters.GetAllCharacters()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -29,71 +28,155 @@ func GetAllCharacters(c *gin.Context) {
 
 // GetAllKills retrieves all kills from the database
 // @Summary Get all kills
-// @Description Fetch all kills from the database
+// @Description Fetch all kills made by tracked characters from the database
 // @Tags kills
 // @Accept json
 // @Produce json
+// @Param regionID query []int false "Region IDs"
+// @Param startDate query string false "Start date (YYYY-MM-DD)"
+// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Param corporationID query int false "Only kills made while in this corporation"
+// @Param shipGroup query []string false "Victim ship group IDs or names"
+// @Param shipCategory query []string false "Victim ship category IDs or names"
+// @Param nearSystem query int false "Only kills within jumps of this system"
+// @Param jumps query int false "Jump radius around nearSystem (default 0)"
+// @Param sovAlliance query int false "Only kills in space held by this alliance"
+// @Param fwOccupier query int false "Only kills in faction warfare space occupied by this faction"
+// @Param labels query string false "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)"
+// @Param resolveNames query bool false "Embed resolved names"
 // @Success 200 {array} models.Kill
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /kills [get]
 func GetAllKills(c *gin.Context) {
-	kills, err := queries.GetAllKills()
+	listKills(c, models.RoleAttacker)
+}
+
+// GetAllLosses retrieves all losses from the database
+// @Summary Get all losses
+// @Description Fetch all losses of tracked characters from the database
+// @Tags kills
+// @Accept json
+// @Produce json
+// @Param regionID query []int false "Region IDs"
+// @Param startDate query string false "Start date (YYYY-MM-DD)"
+// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Param corporationID query int false "Only losses suffered while in this corporation"
+// @Param shipGroup query []string false "Victim ship group IDs or names"
+// @Param shipCategory query []string false "Victim ship category IDs or names"
+// @Param nearSystem query int false "Only losses within jumps of this system"
+// @Param jumps query int false "Jump radius around nearSystem (default 0)"
+// @Param sovAlliance query int false "Only losses in space held by this alliance"
+// @Param fwOccupier query int false "Only losses in faction warfare space occupied by this faction"
+// @Param labels query string false "Only losses with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)"
+// @Param resolveNames query bool false "Embed resolved names"
+// @Success 200 {array} models.Kill
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /losses [get]
+func GetAllLosses(c *gin.Context) {
+	listKills(c, models.RoleVictim)
+}
+
+// listKills responds with the kills matching the query filter on which the
+// tracked character had role.
+func listKills(c *gin.Context, role string) {
+	filter, err := parseKillFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.Role = role
+
+	kills, err := repos.Kills.GetKills(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+
+	if wantsNames(c) {
+		if err := embedNames(c.Request.Context(), kills); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+	}
 	c.JSON(http.StatusOK, kills)
 }
 
 // GetAllCharacterStats retrieves stats for all characters with filters
 // @Summary Get all character stats
-// @Description Fetch stats for all characters from the database with optional filters
+// @Description Fetch kill and loss stats with ISK efficiency for all characters from the database with optional filters
 // @Tags characters
 // @Accept json
 // @Produce json
 // @Param regionID query []int false "Region IDs"
 // @Param startDate query string false "Start date (YYYY-MM-DD)"
 // @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Param corporationID query int false "Only count kills and losses while in this corporation"
+// @Param shipGroup query []string false "Victim ship group IDs or names"
+// @Param shipCategory query []string false "Victim ship category IDs or names"
+// @Param nearSystem query int false "Only kills within jumps of this system"
+// @Param jumps query int false "Jump radius around nearSystem (default 0)"
+// @Param sovAlliance query int false "Only kills in space held by this alliance"
+// @Param fwOccupier query int false "Only kills in faction warfare space occupied by this faction"
+// @Param labels query string false "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)"
+// @Param valueSource query string false "ISK values from zkill (default) or own"
+// @Param countBy query string false "Credit shared kills to every participant (default), the finalBlow only, or by damageShare of the ISK"
 // @Success 200 {array} models.CharacterStats
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /characters/stats [get]
 func GetAllCharacterStats(c *gin.Context) {
-	regionIDs := c.QueryArray("regionID")
-	startDate := c.Query("startDate")
-	endDate := c.Query("endDate")
-
-	var regionIDInts []int64
-	for _, id := range regionIDs {
-		intID, err := strconv.ParseInt(id, 10, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region ID"})
-			return
-		}
-		regionIDInts = append(regionIDInts, intID)
+	filter, err := parseKillFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	var startTime, endTime time.Time
-	var err error
-	if startDate != "" {
-		startTime, err = time.Parse("2006-01-02", startDate)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
-			return
-		}
+	source, err := parseValueSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	if endDate != "" {
-		endTime, err = time.Parse("2006-01-02", endDate)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
-			return
-		}
+
+	mode, err := parseCountingMode(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	stats, err := queries.GetCharacterStats(startTime, endTime, 0, regionIDInts...)
+	stats, err := repos.Kills.GetCharacterStats(filter, source, mode)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, stats)
 }
+
+// GetCharacterAffiliations retrieves the affiliation history of a character
+// @Summary Get character affiliation history
+// @Description Fetch the corporations and alliances a character belonged to, oldest first
+// @Tags characters
+// @Accept json
+// @Produce json
+// @Param id path int true "Character ID"
+// @Success 200 {array} models.CharacterAffiliation
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /characters/{id}/affiliations [get]
+func GetCharacterAffiliations(c *gin.Context) {
+	characterID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+		return
+	}
+
+	affiliations, err := repos.Characters.GetCharacterAffiliations(characterID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, affiliations)
+}
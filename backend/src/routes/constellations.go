@@ -5,45 +5,28 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/tadeasf/eve-ran/src/db/queries"
 	"github.com/tadeasf/eve-ran/src/services"
 )
 
 func FetchAndStoreConstellations(c *gin.Context) {
-	batchSize := 250
-	totalConstellations := 0
+	constellations, stats, err := services.FetchAllConstellations(c.Request.Context(), universeSyncOptions)
 
-	for {
-		// Fetch a batch of constellations
-		constellations, err := services.FetchAllConstellations(batchSize)
-		if err != nil {
+	if len(constellations) > 0 {
+		if err := repos.Universe.BatchUpsertConstellations(services.FetchedValues(constellations)); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-
-		if len(constellations) == 0 {
-			break // No more constellations to fetch
-		}
-
-		// Batch upsert constellations
-		err = queries.BatchUpsertConstellations(constellations)
-		if err != nil {
+		if err := services.CommitFetched(constellations); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-
-		totalConstellations += len(constellations)
-
-		if len(constellations) < batchSize {
-			break // Last batch
-		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Constellations fetched and stored successfully", "count": totalConstellations})
+	respondSynced(c, "Constellations fetched and stored successfully", stats, err)
 }
 
 func GetAllConstellations(c *gin.Context) {
-	constellations, err := queries.GetAllConstellations()
+	constellations, err := repos.Universe.GetAllConstellations()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -59,7 +42,7 @@ func GetConstellationByID(c *gin.Context) {
 		return
 	}
 
-	constellation, err := queries.GetConstellationByID(constellationID)
+	constellation, err := repos.Universe.GetConstellationByID(constellationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -80,7 +63,7 @@ func GetConstellationsByRegion(c *gin.Context) {
 		return
 	}
 
-	constellations, err := queries.GetConstellationsByRegionID(regionID)
+	constellations, err := repos.Universe.GetConstellationsByRegionID(regionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
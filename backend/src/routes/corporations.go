@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/jobs"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// GetCorporation retrieves a corporation with its kill and loss aggregates
+// @Summary Get a corporation
+// @Description Fetch a corporation (from ESI if it is not stored yet) together with its kills, losses and most killed victim corporations
+// @Tags corporations
+// @Accept json
+// @Produce json
+// @Param id path int true "Corporation ID"
+// @Param valueSource query string false "ISK values from zkill (default) or own"
+// @Success 200 {object} models.CorporationDetails
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /corporations/{id} [get]
+func GetCorporation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid corporation ID"})
+		return
+	}
+
+	source, err := parseValueSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	corporation, err := repos.Entities.GetCorporationByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if corporation == nil {
+		corporation, err = jobs.RefreshCorporation(c.Request.Context(), id)
+		if services.IsESINotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Corporation not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	stats, err := repos.Kills.GetEntityKillStats("corporation_id", id, source)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CorporationDetails{Corporation: *corporation, Stats: *stats})
+}
+
+// GetAlliance retrieves an alliance with its kill and loss aggregates
+// @Summary Get an alliance
+// @Description Fetch an alliance (from ESI if it is not stored yet) together with its kills, losses and most killed victim corporations
+// @Tags alliances
+// @Accept json
+// @Produce json
+// @Param id path int true "Alliance ID"
+// @Param valueSource query string false "ISK values from zkill (default) or own"
+// @Success 200 {object} models.AllianceDetails
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /alliances/{id} [get]
+func GetAlliance(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alliance ID"})
+		return
+	}
+
+	source, err := parseValueSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alliance, err := repos.Entities.GetAllianceByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if alliance == nil {
+		alliance, err = jobs.StoreAlliance(c.Request.Context(), id)
+		if services.IsESINotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alliance not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	stats, err := repos.Kills.GetEntityKillStats("alliance_id", id, source)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AllianceDetails{Alliance: *alliance, Stats: *stats})
+}
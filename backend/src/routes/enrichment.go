@@ -0,0 +1,123 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// RequeueRequest selects the enrichment jobs to requeue
+type RequeueRequest struct {
+	// KillmailIDs requeues these jobs; all failed jobs when empty
+	KillmailIDs []int64 `json:"killmail_ids"`
+	// State narrows the jobs to failed or permanent ones
+	State string `json:"state"`
+}
+
+// GetEnrichmentJobs lists the jobs of the enrichment queue
+// @Summary Get enrichment jobs
+// @Description Fetch the jobs fetching full killmails from ESI, most recently updated first, optionally only those in one state
+// @Tags enrichment
+// @Accept json
+// @Produce json
+// @Param state query string false "pending, in_flight, done, failed or permanent"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size"
+// @Success 200 {object} models.PaginatedResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /enrichment/jobs [get]
+func GetEnrichmentJobs(c *gin.Context) {
+	state := c.Query("state")
+	if state != "" {
+		if err := validateEnrichmentState(state); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+	if page < 1 || pageSize < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page or page size"})
+		return
+	}
+
+	jobs, total, err := repos.Enrichment.GetEnrichmentJobs(state, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PaginatedResponse{
+		Data:       jobs,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: int(total),
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	})
+}
+
+// GetEnrichmentSummary counts the jobs of the enrichment queue by state
+// @Summary Get enrichment queue summary
+// @Description Count the enrichment jobs in every state
+// @Tags enrichment
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.EnrichmentStateCount
+// @Failure 500 {object} models.ErrorResponse
+// @Router /enrichment/summary [get]
+func GetEnrichmentSummary(c *gin.Context) {
+	counts, err := repos.Enrichment.GetEnrichmentStateCounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, counts)
+}
+
+// RequeueEnrichmentJobs retries failed enrichment jobs
+// @Summary Requeue failed enrichment jobs
+// @Description Reset failed or permanent enrichment jobs to pending with no attempts. Without killmail IDs every job in the given state, or every failed and permanent job, is requeued.
+// @Tags enrichment
+// @Accept json
+// @Produce json
+// @Param request body RequeueRequest false "Jobs to requeue"
+// @Success 200 {object} map[string]int64
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /enrichment/requeue [post]
+func RequeueEnrichmentJobs(c *gin.Context) {
+	var request RequeueRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	switch request.State {
+	case "", models.EnrichmentFailed, models.EnrichmentPermanent:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only failed or permanent jobs can be requeued"})
+		return
+	}
+
+	count, err := repos.Enrichment.RequeueEnrichmentJobs(request.KillmailIDs, request.State)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"requeued": count})
+}
+
+func validateEnrichmentState(state string) error {
+	switch state {
+	case models.EnrichmentPending, models.EnrichmentInFlight, models.EnrichmentDone, models.EnrichmentFailed, models.EnrichmentPermanent:
+		return nil
+	default:
+		return fmt.Errorf("Invalid state %q", state)
+	}
+}
@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/db/repository"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// parseKillFilter reads the kill filter query parameters shared by the stats
+// endpoints. endDate is inclusive.
+func parseKillFilter(c *gin.Context) (repository.KillFilter, error) {
+	var filter repository.KillFilter
+
+	for _, id := range c.QueryArray("regionID") {
+		regionID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid region ID")
+		}
+		filter.RegionIDs = append(filter.RegionIDs, regionID)
+	}
+
+	if startDate := c.Query("startDate"); startDate != "" {
+		startTime, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid start date format")
+		}
+		filter.StartTime = startTime
+	}
+	if endDate := c.Query("endDate"); endDate != "" {
+		endTime, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid end date format")
+		}
+		filter.EndTime = endTime.AddDate(0, 0, 1)
+	}
+
+	if corporationID := c.Query("corporationID"); corporationID != "" {
+		id, err := strconv.ParseInt(corporationID, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid corporation ID")
+		}
+		filter.CorporationID = id
+	}
+
+	if sovAlliance := c.Query("sovAlliance"); sovAlliance != "" {
+		id, err := strconv.ParseInt(sovAlliance, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid sovereignty alliance ID")
+		}
+		filter.SovAllianceID = id
+	}
+	if fwOccupier := c.Query("fwOccupier"); fwOccupier != "" {
+		id, err := strconv.ParseInt(fwOccupier, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid faction ID")
+		}
+		filter.FWOccupierFactionID = id
+	}
+
+	if nearSystem := c.Query("nearSystem"); nearSystem != "" {
+		systemIDs, err := systemsNear(nearSystem, c.DefaultQuery("jumps", "0"))
+		if err != nil {
+			return filter, err
+		}
+		filter.SystemIDs = systemIDs
+	}
+
+	for _, labels := range c.QueryArray("labels") {
+		for _, label := range strings.Split(labels, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				filter.Labels = append(filter.Labels, label)
+			}
+		}
+	}
+
+	filter.ShipGroups = c.QueryArray("shipGroup")
+	filter.ShipCategories = c.QueryArray("shipCategory")
+
+	return filter, nil
+}
+
+// systemsNear returns the systems within jumps stargate jumps of nearSystem.
+func systemsNear(nearSystem, jumps string) ([]int, error) {
+	systemID, err := strconv.Atoi(nearSystem)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid system ID")
+	}
+	maxJumps, err := strconv.Atoi(jumps)
+	if err != nil || maxJumps < 0 {
+		return nil, fmt.Errorf("Invalid jump count")
+	}
+
+	universe, err := services.UniverseGraph()
+	if err != nil {
+		return nil, err
+	}
+	distances := universe.Within(systemID, maxJumps, nil)
+	if distances == nil {
+		return nil, fmt.Errorf("Unknown system ID")
+	}
+
+	systemIDs := make([]int, 0, len(distances))
+	for id := range distances {
+		systemIDs = append(systemIDs, id)
+	}
+	return systemIDs, nil
+}
+
+// intersectIDs returns the IDs in both a and b.
+func intersectIDs(a, b []int) []int {
+	inA := make(map[int]bool, len(a))
+	for _, id := range a {
+		inA[id] = true
+	}
+	both := []int{}
+	for _, id := range b {
+		if inA[id] {
+			both = append(both, id)
+		}
+	}
+	return both
+}
+
+// parseRole reads the role query parameter, defaulting to the character's
+// kills.
+func parseRole(c *gin.Context) (string, error) {
+	switch role := c.DefaultQuery("role", models.RoleAttacker); role {
+	case models.RoleAttacker, models.RoleVictim:
+		return role, nil
+	default:
+		return "", fmt.Errorf("Invalid role %q, expected attacker or victim", role)
+	}
+}
+
+// parseValueSource reads the valueSource query parameter, defaulting to
+// zKillboard values.
+func parseValueSource(c *gin.Context) (repository.ValueSource, error) {
+	switch source := repository.ValueSource(c.DefaultQuery("valueSource", string(repository.ValueSourceZKill))); source {
+	case repository.ValueSourceZKill, repository.ValueSourceOwn:
+		return source, nil
+	default:
+		return "", fmt.Errorf("Invalid value source %q, expected zkill or own", source)
+	}
+}
+
+// parseCountingMode reads the countBy query parameter, defaulting to
+// crediting every participant.
+func parseCountingMode(c *gin.Context) (repository.CountingMode, error) {
+	switch mode := repository.CountingMode(c.DefaultQuery("countBy", string(repository.CountParticipant))); mode {
+	case repository.CountParticipant, repository.CountFinalBlow, repository.CountDamageShare:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("Invalid counting mode %q, expected participant, finalBlow or damageShare", mode)
+	}
+}
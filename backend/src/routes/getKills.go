@@ -6,21 +6,50 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/tadeasf/eve-ran/src/db/queries"
 )
 
+// GetCharacterKillmails retrieves a character's killmails from the database
+// @Summary Get character killmails
+// @Description Fetch the kills or losses of a character from the database with optional filters
+// @Tags characters
+// @Accept json
+// @Produce json
+// @Param id path int true "Character ID"
+// @Param role query string false "Kills as attacker (default) or losses as victim"
+// @Param start_time query string false "Start time (RFC 3339)"
+// @Param end_time query string false "End time (RFC 3339)"
+// @Param system_id query int false "Solar system ID"
+// @Param region_id query int false "Region ID"
+// @Param resolveNames query bool false "Embed resolved names"
+// @Success 200 {array} models.Kill
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /characters/{id}/killmails [get]
 func GetCharacterKillmails(c *gin.Context) {
 	characterID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	role, err := parseRole(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	startTime, _ := time.Parse(time.RFC3339, c.Query("start_time"))
 	endTime, _ := time.Parse(time.RFC3339, c.Query("end_time"))
 	systemID, _ := strconv.ParseInt(c.Query("system_id"), 10, 64)
 	regionID, _ := strconv.ParseInt(c.Query("region_id"), 10, 64)
 
-	kills, err := queries.GetCharacterKillmails(characterID, startTime, endTime, systemID, regionID)
+	kills, err := repos.Kills.GetCharacterKillmails(characterID, role, startTime, endTime, systemID, regionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if wantsNames(c) {
+		if err := embedNames(c.Request.Context(), kills); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, kills)
 }
@@ -3,32 +3,32 @@ package routes
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/tadeasf/eve-ran/src/db/queries"
+	"github.com/tadeasf/eve-ran/src/jobs"
 	"github.com/tadeasf/eve-ran/src/services"
 )
 
 func FetchAndStoreItems(c *gin.Context) {
-	items, err := services.FetchAllItems(50)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
+	items, stats, err := services.FetchAllItems(c.Request.Context(), universeSyncOptions)
 
 	for _, item := range items {
-		err = queries.UpsertESIItem(item)
-		if err != nil {
+		if err := repos.Items.UpsertESIItem(item.Value); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 	}
+	if err := services.CommitFetched(items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Items fetched and stored successfully", "count": len(items)})
+	respondSynced(c, "Items fetched and stored successfully", stats, err)
 }
 
 func GetAllItems(c *gin.Context) {
-	items, err := queries.GetAllESIItems()
+	items, err := repos.Items.GetAllESIItems()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -45,7 +45,7 @@ func GetItemByTypeID(c *gin.Context) {
 		return
 	}
 
-	item, err := queries.GetESIItemByTypeID(itemTypeID)
+	item, err := repos.Items.GetESIItemByTypeID(itemTypeID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -58,3 +58,45 @@ func GetItemByTypeID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, item)
 }
+
+// GetItemPriceHistory retrieves the stored daily price history of a type
+// @Summary Get item price history
+// @Description Fetch the stored daily market history of a type in The Forge
+// @Tags items
+// @Produce json
+// @Param typeID path int true "Type ID"
+// @Param startDate query string false "Start date (YYYY-MM-DD)"
+// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Success 200 {array} models.PriceHistory
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /items/{typeID}/history [get]
+func GetItemPriceHistory(c *gin.Context) {
+	typeID, err := strconv.Atoi(c.Param("typeID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type ID"})
+		return
+	}
+
+	from := time.Time{}
+	to := time.Now()
+	if startDate := c.Query("startDate"); startDate != "" {
+		if from, err = time.Parse("2006-01-02", startDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
+			return
+		}
+	}
+	if endDate := c.Query("endDate"); endDate != "" {
+		if to, err = time.Parse("2006-01-02", endDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+			return
+		}
+	}
+
+	history, err := repos.Prices.GetPriceHistory(jobs.PriceHistoryRegionID, []int{typeID}, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
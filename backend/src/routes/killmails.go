@@ -0,0 +1,123 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// GetKillmail returns a single stored kill
+// @Summary Get a killmail
+// @Description Fetch a stored kill with its zKillboard data and the full victim item tree, items inside containers nested under their container
+// @Tags kills
+// @Produce json
+// @Param killmailID path int true "Killmail ID"
+// @Param resolveNames query bool false "Embed resolved names"
+// @Success 200 {object} models.Kill
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /kills/{killmailID} [get]
+func GetKillmail(c *gin.Context) {
+	killmailID, err := strconv.ParseInt(c.Param("killmailID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid killmail ID"})
+		return
+	}
+
+	kill, err := repos.Kills.GetKillmail(killmailID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if kill == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kill not found"})
+		return
+	}
+
+	if wantsNames(c) {
+		kills := []models.Kill{*kill}
+		if err := embedNames(c.Request.Context(), kills); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		kill = &kills[0]
+	}
+
+	c.JSON(http.StatusOK, kill)
+}
+
+// GetKillmailItems returns the flattened items of a kill
+// @Summary Get the items of a killmail
+// @Description Fetch the victim's items of a stored kill as flat rows in walk order, with the index of the containing item, flag and the quantities dropped and destroyed
+// @Tags kills
+// @Produce json
+// @Param killmailID path int true "Killmail ID"
+// @Success 200 {array} models.KillItem
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /kills/{killmailID}/items [get]
+func GetKillmailItems(c *gin.Context) {
+	killmailID, err := strconv.ParseInt(c.Param("killmailID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid killmail ID"})
+		return
+	}
+
+	exists, err := repos.Kills.KillExists(killmailID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kill not found"})
+		return
+	}
+
+	items, err := repos.Kills.GetKillItems(killmailID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetKillmailParticipants returns the tracked characters credited for a kill
+// @Summary Get the participants of a killmail
+// @Description Fetch the tracked characters on a stored kill, as victim or attackers, with their final blow and damage
+// @Tags kills
+// @Produce json
+// @Param killmailID path int true "Killmail ID"
+// @Success 200 {array} models.KillParticipant
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /kills/{killmailID}/participants [get]
+func GetKillmailParticipants(c *gin.Context) {
+	killmailID, err := strconv.ParseInt(c.Param("killmailID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid killmail ID"})
+		return
+	}
+
+	exists, err := repos.Kills.KillExists(killmailID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kill not found"})
+		return
+	}
+
+	participants, err := repos.Kills.GetKillParticipants(killmailID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, participants)
+}
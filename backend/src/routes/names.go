@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// NamesRequest is the body of a bulk name lookup
+type NamesRequest struct {
+	IDs []int64 `json:"ids" binding:"required"`
+}
+
+// ResolveNames resolves IDs to names in bulk
+// @Summary Resolve IDs to names
+// @Description Resolve character, corporation, alliance, type and location IDs to names, using the names cache and ESI for unknown IDs
+// @Tags names
+// @Accept json
+// @Produce json
+// @Param ids body NamesRequest true "IDs to resolve"
+// @Success 200 {array} models.Name
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /names [post]
+func ResolveNames(c *gin.Context) {
+	var request NamesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	names, err := services.ResolveNames(c.Request.Context(), request.IDs)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := make([]models.Name, 0, len(names))
+	for _, id := range request.IDs {
+		if name, ok := names[id]; ok {
+			result = append(result, name)
+			delete(names, id)
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// wantsNames reports whether the request asked for embedded names.
+func wantsNames(c *gin.Context) bool {
+	return c.Query("resolveNames") == "true"
+}
+
+// embedNames fills the Names map of every kill with the resolved names of
+// the IDs it references.
+func embedNames(ctx context.Context, kills []models.Kill) error {
+	var ids []int64
+	for i := range kills {
+		ids = append(ids, kills[i].EntityIDs()...)
+	}
+
+	names, err := services.ResolveNames(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range kills {
+		kills[i].Names = make(map[int64]string)
+		for _, id := range kills[i].EntityIDs() {
+			if name, ok := names[id]; ok {
+				kills[i].Names[id] = name.Name
+			}
+		}
+	}
+	return nil
+}
@@ -4,26 +4,26 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/tadeasf/eve-ran/src/db/queries"
 	"github.com/tadeasf/eve-ran/src/services"
 )
 
 func FetchAndStoreRegions(c *gin.Context) {
-	regions, err := services.FetchAllRegions(10) // Use 10 concurrent requests
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
+	regions, stats, err := services.FetchAllRegions(c.Request.Context(), universeSyncOptions)
 
+	// Store whatever was fetched, even if the sync was cut short, so the ESI
+	// cache and the database stay in step
 	for _, region := range regions {
-		err = queries.UpsertRegion(region)
-		if err != nil {
+		if err := repos.Universe.UpsertRegion(region.Value); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 	}
+	if err := services.CommitFetched(regions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Regions fetched and stored successfully", "count": len(regions)})
+	respondSynced(c, "Regions fetched and stored successfully", stats, err)
 }
 
 // GetAllRegions retrieves all regions from the database
@@ -36,7 +36,7 @@ func FetchAndStoreRegions(c *gin.Context) {
 // @Failure 500 {object} models.ErrorResponse
 // @Router /regions [get]
 func GetAllRegions(c *gin.Context) {
-	regions, err := queries.GetAllRegions()
+	regions, err := repos.Universe.GetAllRegions()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
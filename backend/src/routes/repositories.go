@@ -0,0 +1,11 @@
+package routes
+
+import "github.com/tadeasf/eve-ran/src/db/repository"
+
+var repos *repository.Repositories
+
+// ConfigureRepositories sets the repositories the handlers read and write
+// through. It must be called before the router serves requests.
+func ConfigureRepositories(r *repository.Repositories) {
+	repos = r
+}
@@ -0,0 +1,220 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/graph"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// defaultKillWindow is how far back avoidKills looks when killWindow is not
+// given.
+const defaultKillWindow = 24 * time.Hour
+
+// parseAvoid builds the systems to avoid from the avoidSecurity, avoidSystem,
+// avoidKills and killWindow query parameters. It returns nil when nothing is
+// avoided.
+func parseAvoid(c *gin.Context) (graph.Avoid, error) {
+	bands := make(map[graph.SecurityBand]bool)
+	for _, band := range c.QueryArray("avoidSecurity") {
+		switch graph.SecurityBand(band) {
+		case graph.HighSec, graph.LowSec, graph.NullSec:
+			bands[graph.SecurityBand(band)] = true
+		default:
+			return nil, fmt.Errorf("Invalid security band %q, expected high, low or null", band)
+		}
+	}
+
+	systems := make(map[int]bool)
+	for _, id := range c.QueryArray("avoidSystem") {
+		systemID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid system ID")
+		}
+		systems[systemID] = true
+	}
+
+	var killCounts map[int]int
+	minKills := 0
+	if avoidKills := c.Query("avoidKills"); avoidKills != "" {
+		var err error
+		minKills, err = strconv.Atoi(avoidKills)
+		if err != nil || minKills < 1 {
+			return nil, fmt.Errorf("Invalid kill count")
+		}
+
+		window := defaultKillWindow
+		if killWindow := c.Query("killWindow"); killWindow != "" {
+			window, err = time.ParseDuration(killWindow)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid kill window")
+			}
+		}
+
+		killCounts, err = repos.Kills.GetSystemKillCounts(time.Now().Add(-window))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(bands) == 0 && len(systems) == 0 && killCounts == nil {
+		return nil, nil
+	}
+	return func(system models.System) bool {
+		return bands[graph.Band(system.SecurityStatus)] ||
+			systems[system.SystemID] ||
+			(killCounts != nil && killCounts[system.SystemID] >= minKills)
+	}, nil
+}
+
+func routeSystem(system models.System, jumps int) models.RouteSystem {
+	return models.RouteSystem{
+		SystemID:       system.SystemID,
+		Name:           system.Name,
+		SecurityStatus: system.SecurityStatus,
+		RegionID:       system.RegionID,
+		Jumps:          jumps,
+	}
+}
+
+// GetRoute finds the shortest stargate route between two systems
+// @Summary Get route between systems
+// @Description Find the shortest stargate route between two systems, optionally avoiding systems
+// @Tags universe
+// @Produce json
+// @Param from path int true "Origin system ID"
+// @Param to path int true "Destination system ID"
+// @Param avoidSecurity query []string false "Security bands to avoid (high, low, null)"
+// @Param avoidSystem query []int false "System IDs to avoid"
+// @Param avoidKills query int false "Avoid systems with at least this many recent kills"
+// @Param killWindow query string false "How far back avoidKills looks, e.g. 1h (default 24h)"
+// @Success 200 {object} models.Route
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /route/{from}/{to} [get]
+func GetRoute(c *gin.Context) {
+	from, err := strconv.Atoi(c.Param("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid origin system ID"})
+		return
+	}
+	to, err := strconv.Atoi(c.Param("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid destination system ID"})
+		return
+	}
+
+	avoid, err := parseAvoid(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	universe, err := services.UniverseGraph()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	path := universe.Route(from, to, avoid)
+	if path == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No route found"})
+		return
+	}
+
+	route := models.Route{From: from, To: to, Jumps: len(path) - 1}
+	for jumps, systemID := range path {
+		system, _ := universe.System(systemID)
+		route.Systems = append(route.Systems, routeSystem(system, jumps))
+	}
+	c.JSON(http.StatusOK, route)
+}
+
+// GetSystemsWithinJumps lists the systems within a number of jumps
+// @Summary Get systems within jumps
+// @Description List the systems reachable from a system within a number of stargate jumps, nearest first
+// @Tags universe
+// @Produce json
+// @Param id path int true "System ID"
+// @Param jumps path int true "Maximum number of jumps"
+// @Param avoidSecurity query []string false "Security bands to avoid (high, low, null)"
+// @Param avoidSystem query []int false "System IDs to avoid"
+// @Param avoidKills query int false "Avoid systems with at least this many recent kills"
+// @Param killWindow query string false "How far back avoidKills looks, e.g. 1h (default 24h)"
+// @Success 200 {array} models.RouteSystem
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /systems/{id}/within/{jumps} [get]
+func GetSystemsWithinJumps(c *gin.Context) {
+	systemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid system ID"})
+		return
+	}
+	jumps, err := strconv.Atoi(c.Param("jumps"))
+	if err != nil || jumps < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid jump count"})
+		return
+	}
+
+	avoid, err := parseAvoid(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	universe, err := services.UniverseGraph()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	distances := universe.Within(systemID, jumps, avoid)
+	if distances == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "System not found"})
+		return
+	}
+
+	systems := make([]models.RouteSystem, 0, len(distances))
+	for id, distance := range distances {
+		system, _ := universe.System(id)
+		systems = append(systems, routeSystem(system, distance))
+	}
+	sort.Slice(systems, func(i, j int) bool {
+		if systems[i].Jumps != systems[j].Jumps {
+			return systems[i].Jumps < systems[j].Jumps
+		}
+		return systems[i].SystemID < systems[j].SystemID
+	})
+	c.JSON(http.StatusOK, systems)
+}
+
+// FetchAndStoreStargates syncs stargates from ESI
+// @Summary Fetch and store stargates
+// @Description Fetch the stargates of all stored systems from ESI and store the ones that changed
+// @Tags universe
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stargates/fetch [post]
+func FetchAndStoreStargates(c *gin.Context) {
+	stargates, stats, err := services.FetchAllStargates(c.Request.Context(), universeSyncOptions)
+	if storeErr := repos.Universe.BatchUpsertStargates(services.FetchedValues(stargates)); storeErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": storeErr.Error()})
+		return
+	}
+	if storeErr := services.CommitFetched(stargates); storeErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": storeErr.Error()})
+		return
+	}
+	services.ResetUniverseGraph()
+	respondSynced(c, "Stargates fetched and stored successfully", stats, err)
+}
@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetKillSpaceBreakdown groups kills by the holder of the space they happened in
+// @Summary Get kills by space holder
+// @Description Aggregate kills by the sovereignty alliance and faction warfare occupier of their system at the time of the kill
+// @Tags kills
+// @Produce json
+// @Param regionID query []int false "Region IDs"
+// @Param startDate query string false "Start date (YYYY-MM-DD)"
+// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Param corporationID query int false "Only kills made while in this corporation"
+// @Param sovAlliance query int false "Only kills in space held by this alliance"
+// @Param fwOccupier query int false "Only kills in faction warfare space occupied by this faction"
+// @Param labels query string false "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)"
+// @Param valueSource query string false "ISK values from zkill (default) or own"
+// @Success 200 {array} models.SpaceBreakdown
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /kills/space [get]
+func GetKillSpaceBreakdown(c *gin.Context) {
+	filter, err := parseKillFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	source, err := parseValueSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	breakdown, err := repos.Kills.GetSpaceBreakdown(filter, source)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, breakdown)
+}
+
+// GetSystemControlHistory retrieves the control history of a system
+// @Summary Get system control history
+// @Description Fetch the recorded sovereignty and faction warfare holders of a system, oldest first
+// @Tags systems
+// @Produce json
+// @Param id path int true "System ID"
+// @Success 200 {array} models.SystemControl
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /systems/{id}/control [get]
+func GetSystemControlHistory(c *gin.Context) {
+	systemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid system ID"})
+		return
+	}
+
+	history, err := repos.Universe.GetSystemControlHistory(systemID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
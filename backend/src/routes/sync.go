@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// universeSyncOptions are the bulk fetch options used by the /fetch routes.
+var universeSyncOptions = services.BulkOptions{
+	Concurrency: 50,
+	Retries:     2,
+	RetryDelay:  2 * time.Second,
+}
+
+// respondSynced writes the result of a universe sync. IDs that failed after
+// all retries are listed in the response; a cancelled request (the client
+// disconnected) gets no response at all.
+func respondSynced(c *gin.Context, message string, stats services.SyncStats, err error) {
+	var bulkErr *services.BulkError
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": message, "count": stats.Checked, "changed": stats.Changed})
+	case errors.As(err, &bulkErr):
+		c.JSON(http.StatusOK, gin.H{"message": message, "count": stats.Checked, "changed": stats.Changed, "failed_ids": bulkErr.FailedIDs()})
+	case errors.Is(err, context.Canceled):
+		c.Abort()
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
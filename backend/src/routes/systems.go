@@ -5,43 +5,29 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/tadeasf/eve-ran/src/db/queries"
 	"github.com/tadeasf/eve-ran/src/services"
 )
 
 func FetchAndStoreSystems(c *gin.Context) {
-	batchSize := 1000
-	totalSystems := 0
+	systems, stats, err := services.FetchAllSystems(c.Request.Context(), universeSyncOptions)
 
-	for {
-		systems, err := services.FetchAllSystems(batchSize)
-		if err != nil {
+	if len(systems) > 0 {
+		if err := repos.Universe.BatchUpsertSystems(services.FetchedValues(systems)); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-
-		if len(systems) == 0 {
-			break
-		}
-
-		err = queries.BatchUpsertSystems(systems)
-		if err != nil {
+		if err := services.CommitFetched(systems); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-
-		totalSystems += len(systems)
-
-		if len(systems) < batchSize {
-			break
-		}
+		services.ResetUniverseGraph()
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Systems fetched and stored successfully", "count": totalSystems})
+	respondSynced(c, "Systems fetched and stored successfully", stats, err)
 }
 
 func GetAllSystems(c *gin.Context) {
-	systems, err := queries.GetAllSystems()
+	systems, err := repos.Universe.GetAllSystems()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -57,7 +43,7 @@ func GetSystemByID(c *gin.Context) {
 		return
 	}
 
-	system, err := queries.GetSystemByID(systemID)
+	system, err := repos.Universe.GetSystemByID(systemID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -78,7 +64,7 @@ func GetSystemsByRegion(c *gin.Context) {
 		return
 	}
 
-	systems, err := queries.GetSystemsByRegionID(regionID)
+	systems, err := repos.Universe.GetSystemsByRegionID(regionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,204 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// FetchAndStoreCategories syncs type categories from ESI
+// @Summary Fetch and store categories
+// @Description Fetch type categories from ESI and store the ones that changed
+// @Tags taxonomy
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} models.ErrorResponse
+// @Router /categories/fetch [post]
+func FetchAndStoreCategories(c *gin.Context) {
+	categories, stats, err := services.FetchAllCategories(c.Request.Context(), universeSyncOptions)
+	if storeErr := repos.Items.BatchUpsertCategories(services.FetchedValues(categories)); storeErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": storeErr.Error()})
+		return
+	}
+	if storeErr := services.CommitFetched(categories); storeErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": storeErr.Error()})
+		return
+	}
+	respondSynced(c, "Categories fetched and stored successfully", stats, err)
+}
+
+// FetchAndStoreGroups syncs type groups from ESI
+// @Summary Fetch and store groups
+// @Description Fetch type groups from ESI and store the ones that changed
+// @Tags taxonomy
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} models.ErrorResponse
+// @Router /groups/fetch [post]
+func FetchAndStoreGroups(c *gin.Context) {
+	groups, stats, err := services.FetchAllGroups(c.Request.Context(), universeSyncOptions)
+	if storeErr := repos.Items.BatchUpsertGroups(services.FetchedValues(groups)); storeErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": storeErr.Error()})
+		return
+	}
+	if storeErr := services.CommitFetched(groups); storeErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": storeErr.Error()})
+		return
+	}
+	respondSynced(c, "Groups fetched and stored successfully", stats, err)
+}
+
+// FetchAndStoreMarketGroups syncs market groups from ESI
+// @Summary Fetch and store market groups
+// @Description Fetch market groups from ESI and store the ones that changed
+// @Tags taxonomy
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} models.ErrorResponse
+// @Router /market-groups/fetch [post]
+func FetchAndStoreMarketGroups(c *gin.Context) {
+	marketGroups, stats, err := services.FetchAllMarketGroups(c.Request.Context(), universeSyncOptions)
+	if storeErr := repos.Items.BatchUpsertMarketGroups(services.FetchedValues(marketGroups)); storeErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": storeErr.Error()})
+		return
+	}
+	if storeErr := services.CommitFetched(marketGroups); storeErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": storeErr.Error()})
+		return
+	}
+	respondSynced(c, "Market groups fetched and stored successfully", stats, err)
+}
+
+// GetAllCategories retrieves all type categories
+// @Summary Get all categories
+// @Description Fetch all type categories from the database
+// @Tags taxonomy
+// @Produce json
+// @Success 200 {array} models.ItemCategory
+// @Failure 500 {object} models.ErrorResponse
+// @Router /categories [get]
+func GetAllCategories(c *gin.Context) {
+	categories, err := repos.Items.GetAllCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// GetCategoryByID retrieves a category and its groups
+// @Summary Get category by ID
+// @Description Fetch a type category and its groups from the database
+// @Tags taxonomy
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} models.CategoryDetails
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /categories/{id} [get]
+func GetCategoryByID(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	category, err := repos.Items.GetCategoryByID(categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if category == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	groups, err := repos.Items.GetGroups(categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, models.CategoryDetails{ItemCategory: *category, Groups: groups})
+}
+
+// GetAllGroups retrieves type groups
+// @Summary Get groups
+// @Description Fetch type groups from the database, optionally of a single category
+// @Tags taxonomy
+// @Produce json
+// @Param categoryID query int false "Category ID"
+// @Success 200 {array} models.ItemGroup
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /groups [get]
+func GetAllGroups(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.DefaultQuery("categoryID", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	groups, err := repos.Items.GetGroups(categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetGroupByID retrieves a group and its types
+// @Summary Get group by ID
+// @Description Fetch a type group and its types from the database
+// @Tags taxonomy
+// @Produce json
+// @Param id path int true "Group ID"
+// @Success 200 {object} models.GroupDetails
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /groups/{id} [get]
+func GetGroupByID(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	group, err := repos.Items.GetGroupByID(groupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if group == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	types, err := repos.Items.GetItemsByGroupID(groupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, models.GroupDetails{ItemGroup: *group, Types: types})
+}
+
+// GetAllMarketGroups retrieves all market groups
+// @Summary Get all market groups
+// @Description Fetch all market groups from the database
+// @Tags taxonomy
+// @Produce json
+// @Success 200 {array} models.MarketGroup
+// @Failure 500 {object} models.ErrorResponse
+// @Router /market-groups [get]
+func GetAllMarketGroups(c *gin.Context) {
+	marketGroups, err := repos.Items.GetAllMarketGroups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, marketGroups)
+}
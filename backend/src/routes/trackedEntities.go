@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/jobs"
+	"github.com/tadeasf/eve-ran/src/services"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+// GetTrackedEntities retrieves the tracked corporations and alliances
+// @Summary Get tracked entities
+// @Description Fetch the corporations and alliances whose kills and losses are ingested
+// @Tags tracked entities
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.TrackedEntity
+// @Failure 500 {object} models.ErrorResponse
+// @Router /tracked-entities [get]
+func GetTrackedEntities(c *gin.Context) {
+	entities, err := repos.Entities.GetTrackedEntities()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entities)
+}
+
+// AddTrackedEntity starts tracking a corporation or alliance
+// @Summary Track a corporation or alliance
+// @Description Register a corporation or alliance and fetch all its kills and losses from zKillboard. Every member appearing on them is added to the tracked characters.
+// @Tags tracked entities
+// @Accept json
+// @Produce json
+// @Param entity body models.TrackedEntity true "Entity type (corporation or alliance) and ID"
+// @Success 201 {object} models.TrackedEntity
+// @Success 200 {object} models.TrackedEntity
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /tracked-entities [post]
+func AddTrackedEntity(c *gin.Context) {
+	var entity models.TrackedEntity
+	if err := c.ShouldBindJSON(&entity); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateEntityType(entity.EntityType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := repos.Entities.GetTrackedEntity(entity.EntityType, entity.EntityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusOK, existing)
+		return
+	}
+
+	// Resolve the name, which also checks that the entity exists
+	switch entity.EntityType {
+	case models.EntityCorporation:
+		var corporation *models.Corporation
+		corporation, err = jobs.RefreshCorporation(c.Request.Context(), entity.EntityID)
+		if err == nil {
+			entity.Name = corporation.Name
+		}
+	case models.EntityAlliance:
+		var alliance *models.Alliance
+		alliance, err = jobs.StoreAlliance(c.Request.Context(), entity.EntityID)
+		if err == nil {
+			entity.Name = alliance.Name
+		}
+	}
+	if services.IsESINotFound(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entity not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := repos.Entities.UpsertTrackedEntity(&entity); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add tracked entity"})
+		return
+	}
+
+	go func() {
+		if err := jobs.InitializeEntityKills(entity); err != nil {
+			utils.LogError(fmt.Sprintf("Error initializing kills for %s %d: %v", entity.EntityType, entity.EntityID, err))
+		}
+	}()
+
+	utils.LogToConsole(fmt.Sprintf("Tracking %s: %s (ID: %d)", entity.EntityType, entity.Name, entity.EntityID))
+	c.JSON(http.StatusCreated, entity)
+}
+
+// RemoveTrackedEntity stops tracking a corporation or alliance
+// @Summary Stop tracking a corporation or alliance
+// @Description Remove a tracked corporation or alliance. Characters added through it stay tracked.
+// @Tags tracked entities
+// @Accept json
+// @Produce json
+// @Param type path string true "Entity type (corporation or alliance)"
+// @Param id path int true "Entity ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /tracked-entities/{type}/{id} [delete]
+func RemoveTrackedEntity(c *gin.Context) {
+	entityType := c.Param("type")
+	if err := validateEntityType(entityType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity ID"})
+		return
+	}
+
+	if err := repos.Entities.DeleteTrackedEntity(entityType, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func validateEntityType(entityType string) error {
+	switch entityType {
+	case models.EntityCorporation, models.EntityAlliance:
+		return nil
+	default:
+		return fmt.Errorf("Invalid entity type %q, expected corporation or alliance", entityType)
+	}
+}
@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/jobs"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+// GetTrackedRegions retrieves the tracked regions
+// @Summary Get tracked regions
+// @Description Fetch the regions in which every kill is ingested
+// @Tags tracked regions
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.TrackedRegion
+// @Failure 500 {object} models.ErrorResponse
+// @Router /tracked-regions [get]
+func GetTrackedRegions(c *gin.Context) {
+	regions, err := repos.Universe.GetTrackedRegions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, regions)
+}
+
+// AddTrackedRegion starts tracking a region
+// @Summary Track a region
+// @Description Register a region and fetch every kill of the last week in it from zKillboard. New kills in it are ingested from then on, whether or not they involve tracked characters.
+// @Tags tracked regions
+// @Accept json
+// @Produce json
+// @Param region body models.TrackedRegion true "Region ID"
+// @Success 201 {object} models.TrackedRegion
+// @Success 200 {object} models.TrackedRegion
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /tracked-regions [post]
+func AddTrackedRegion(c *gin.Context) {
+	var trackedRegion models.TrackedRegion
+	if err := c.ShouldBindJSON(&trackedRegion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := repos.Universe.GetTrackedRegion(trackedRegion.RegionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusOK, existing)
+		return
+	}
+
+	region, err := repos.Universe.GetRegionByID(trackedRegion.RegionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if region == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Region not found"})
+		return
+	}
+	trackedRegion.Name = region.Name
+
+	if err := repos.Universe.UpsertTrackedRegion(&trackedRegion); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add tracked region"})
+		return
+	}
+
+	go func() {
+		if err := jobs.InitializeRegionKills(trackedRegion.RegionID); err != nil {
+			utils.LogError(fmt.Sprintf("Error initializing kills in region %d: %v", trackedRegion.RegionID, err))
+		}
+	}()
+
+	utils.LogToConsole(fmt.Sprintf("Tracking region: %s (ID: %d)", trackedRegion.Name, trackedRegion.RegionID))
+	c.JSON(http.StatusCreated, trackedRegion)
+}
+
+// RemoveTrackedRegion stops tracking a region
+// @Summary Stop tracking a region
+// @Description Remove a tracked region. Kills already stored stay.
+// @Tags tracked regions
+// @Accept json
+// @Produce json
+// @Param id path int true "Region ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /tracked-regions/{id} [delete]
+func RemoveTrackedRegion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid region ID"})
+		return
+	}
+
+	if err := repos.Universe.DeleteTrackedRegion(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -1,17 +1,15 @@
 package routes
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"sync"
 
 	"github.com/gin-gonic/gin"
-	"github.com/tadeasf/eve-ran/src/db"
 	"github.com/tadeasf/eve-ran/src/db/models"
-	"github.com/tadeasf/eve-ran/src/db/queries"
 	"github.com/tadeasf/eve-ran/src/jobs"
+	"github.com/tadeasf/eve-ran/src/services"
 	"github.com/tadeasf/eve-ran/src/utils"
 	"gorm.io/gorm"
 )
@@ -39,7 +37,7 @@ func AddCharacter(c *gin.Context) {
 		return
 	}
 
-	existingCharacter, err := queries.GetCharacterByID(character.ID)
+	existingCharacter, err := repos.Characters.GetCharacterByID(character.ID)
 	if err != nil && err != gorm.ErrRecordNotFound {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing character"})
 		return
@@ -51,35 +49,15 @@ func AddCharacter(c *gin.Context) {
 	}
 
 	// Fetch character data from ESI API
-	esiURL := fmt.Sprintf("https://esi.evetech.net/latest/characters/%d/?datasource=tranquility", character.ID)
-	req, _ := http.NewRequest("GET", esiURL, nil)
-	req.Header.Add("accept", "application/json")
-	req.Header.Add("Cache-Control", "no-cache")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	esiCharacter, err := services.FetchCharacterInfo(character.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch character data from ESI"})
 		return
 	}
-	defer resp.Body.Close()
-
-	var esiData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&esiData); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse ESI response"})
-		return
-	}
-
-	// Update character with ESI data
-	character.Name = esiData["name"].(string)
-	character.SecurityStatus = esiData["security_status"].(float64)
-	if title, ok := esiData["title"].(string); ok {
-		character.Title = title
-	}
-	character.RaceID = int(esiData["race_id"].(float64))
+	character = *esiCharacter
 
 	// Insert the character into the database
-	if err := queries.UpsertCharacter(&character); err != nil {
+	if err := repos.Characters.UpsertCharacter(&character); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add character"})
 		return
 	}
@@ -114,8 +92,7 @@ func RemoveCharacter(c *gin.Context) {
 		return
 	}
 
-	err = db.DB.Delete(&models.Character{}, id).Error
-	if err != nil {
+	if err := repos.Characters.DeleteCharacter(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -132,11 +109,38 @@ func RemoveCharacter(c *gin.Context) {
 // @Param id path int true "Character ID"
 // @Param page query int false "Page number"
 // @Param pageSize query int false "Page size"
+// @Param resolveNames query bool false "Embed resolved names"
 // @Success 200 {object} models.PaginatedResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /characters/{id}/kills/db [get]
 func GetCharacterKillsFromDB(c *gin.Context) {
+	getCharacterKillsPage(c, models.RoleAttacker)
+}
+
+// GetCharacterLossesFromDB retrieves character losses from the database
+// @Summary Get character losses from database
+// @Description Fetch losses of a character from the database, newest first
+// @Tags characters
+// @Accept json
+// @Produce json
+// @Param id path int true "Character ID"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size"
+// @Param resolveNames query bool false "Embed resolved names"
+// @Success 200 {object} models.PaginatedResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /characters/{id}/losses/db [get]
+func GetCharacterLossesFromDB(c *gin.Context) {
+	getCharacterKillsPage(c, models.RoleVictim)
+}
+
+// getCharacterKillsPage responds with a page of the kills on which the
+// character had role.
+func getCharacterKillsPage(c *gin.Context, role string) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
@@ -146,18 +150,25 @@ func GetCharacterKillsFromDB(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
 
-	kills, err := queries.GetKillsForCharacter(id, page, pageSize)
+	kills, err := repos.Kills.GetKillsForCharacter(id, role, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	totalItems, err := queries.GetTotalKillsForCharacter(id)
+	totalItems, err := repos.Kills.GetTotalKillsForCharacter(id, role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if wantsNames(c) {
+		if err := embedNames(c.Request.Context(), kills); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	totalPages := int((totalItems + int64(pageSize) - 1) / int64(pageSize))
 
 	response := models.PaginatedResponse{
@@ -180,9 +191,18 @@ func GetCharacterKillsFromDB(c *gin.Context) {
 // @Param regionID path int true "Region ID"
 // @Param startDate query string false "Start date (YYYY-MM-DD)"
 // @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Param shipGroup query []string false "Victim ship group IDs or names"
+// @Param shipCategory query []string false "Victim ship category IDs or names"
+// @Param nearSystem query int false "Only kills within jumps of this system"
+// @Param jumps query int false "Jump radius around nearSystem (default 0)"
+// @Param sovAlliance query int false "Only kills in space held by this alliance"
+// @Param fwOccupier query int false "Only kills in faction warfare space occupied by this faction"
+// @Param labels query string false "Only kills with all of these zKillboard labels, comma separated (e.g. solo,pvp or loc:nullsec)"
+// @Param resolveNames query bool false "Embed resolved names"
 // @Success 200 {array} models.Kill
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
 // @Router /kills/region/{regionID} [get]
 func GetKillsByRegion(c *gin.Context) {
 	regionID, err := strconv.Atoi(c.Param("regionID"))
@@ -191,33 +211,37 @@ func GetKillsByRegion(c *gin.Context) {
 		return
 	}
 
-	startDate := c.Query("startDate")
-	endDate := c.Query("endDate")
+	filter, err := parseKillFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	systemIDs, err := queries.GetSolarSystemIDsByRegion(regionID)
+	systemIDs, err := repos.Universe.GetSolarSystemIDsByRegion(regionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	var kills []models.Kill
-	query := db.DB.Preload("ZkillData").Where("solar_system_id IN ?", systemIDs)
-
-	if startDate != "" && endDate != "" {
-		query = query.Where("killmail_time BETWEEN ? AND ?", startDate, endDate)
+	if filter.SystemIDs != nil {
+		systemIDs = intersectIDs(filter.SystemIDs, systemIDs)
+	}
+	if systemIDs == nil {
+		// A nil list would not filter at all
+		systemIDs = []int{}
 	}
+	filter.SystemIDs = systemIDs
 
-	if err := query.Find(&kills).Error; err != nil {
+	kills, err := repos.Kills.GetKills(filter)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Process kills to include CharacterID from Attackers
-	for i := range kills {
-		var attackers []models.Attacker
-		json.Unmarshal(kills[i].Attackers, &attackers)
-		if len(attackers) > 0 {
-			kills[i].CharacterID = attackers[0].CharacterID
+	if wantsNames(c) {
+		if err := embedNames(c.Request.Context(), kills); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
 		}
 	}
 
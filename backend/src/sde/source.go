@@ -0,0 +1,153 @@
+// Package sde reads universe and type data from the CCP Static Data Export
+// as published in the Fuzzwork CSV dumps (https://www.fuzzwork.co.uk/dump/).
+//
+// A dump is either a directory or a zip archive holding the CSV tables, each
+// optionally bzip2-compressed as Fuzzwork serves them, e.g. mapRegions.csv or
+// mapRegions.csv.bz2.
+package sde
+
+import (
+	"archive/zip"
+	"compress/bzip2"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Source is an opened SDE dump.
+type Source struct {
+	fsys   fs.FS
+	closer io.Closer
+}
+
+// Open opens the dump at path, which is a directory or a zip archive.
+func Open(dumpPath string) (*Source, error) {
+	info, err := os.Stat(dumpPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &Source{fsys: os.DirFS(dumpPath)}, nil
+	}
+
+	archive, err := zip.OpenReader(dumpPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening SDE archive %s: %w", dumpPath, err)
+	}
+	return &Source{fsys: archive, closer: archive}, nil
+}
+
+// Close releases the dump.
+func (s *Source) Close() error {
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
+
+// openTable finds table in the dump, in any directory and with or without
+// bzip2 compression.
+func (s *Source) openTable(table string) (io.ReadCloser, bool, error) {
+	var found string
+	err := fs.WalkDir(s.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || found != "" {
+			return err
+		}
+		base := path.Base(name)
+		if base == table+".csv" || base == table+".csv.bz2" {
+			found = name
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if found == "" {
+		return nil, false, fmt.Errorf("table %s not in SDE dump: %w", table, fs.ErrNotExist)
+	}
+
+	file, err := s.fsys.Open(found)
+	if err != nil {
+		return nil, false, err
+	}
+	return file, strings.HasSuffix(found, ".bz2"), nil
+}
+
+// row is a CSV record addressed by column name. Fuzzwork writes NULL as
+// "None"; such values read as zero.
+type row struct {
+	columns map[string]int
+	record  []string
+}
+
+func (r row) String(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) || r.record[i] == "None" {
+		return ""
+	}
+	return r.record[i]
+}
+
+func (r row) Int(column string) int {
+	value, _ := strconv.Atoi(r.String(column))
+	return value
+}
+
+func (r row) Float(column string) float64 {
+	value, _ := strconv.ParseFloat(r.String(column), 64)
+	return value
+}
+
+func (r row) Bool(column string) bool {
+	switch strings.ToLower(r.String(column)) {
+	case "1", "true":
+		return true
+	}
+	return false
+}
+
+// readTable calls fn for every row of table.
+func (s *Source) readTable(table string, fn func(row) error) error {
+	file, compressed, err := s.openTable(table)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if compressed {
+		reader = bzip2.NewReader(file)
+	}
+
+	records := csv.NewReader(reader)
+	records.ReuseRecord = true
+	records.FieldsPerRecord = -1
+
+	header, err := records.Read()
+	if err != nil {
+		return fmt.Errorf("reading %s header: %w", table, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimPrefix(name, "\ufeff")] = i
+	}
+
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", table, err)
+		}
+		if err := fn(row{columns: columns, record: record}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package sde
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// zipDump packs the tables in dir into a zip archive below a directory, as
+// Fuzzwork archives them.
+func zipDump(t *testing.T, dir string) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "sde.zip")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		w, err := archive.Create("sde-20250601/" + entry.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = io.Copy(w, r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func TestLoadDump(t *testing.T) {
+	dumps := []struct {
+		name string
+		path string
+	}{
+		{"directory", filepath.Join("testdata", "csv")},
+		{"bzip2 directory", filepath.Join("testdata", "bz2")},
+		{"zip", zipDump(t, filepath.Join("testdata", "csv"))},
+		{"zip of bzip2", zipDump(t, filepath.Join("testdata", "bz2"))},
+	}
+	for _, dump := range dumps {
+		t.Run(dump.name, func(t *testing.T) {
+			source, err := Open(dump.path)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			defer source.Close()
+
+			universe, err := source.LoadUniverse()
+			if err != nil {
+				t.Fatalf("LoadUniverse: %v", err)
+			}
+			checkUniverse(t, universe)
+
+			items, err := source.LoadTypes()
+			if err != nil {
+				t.Fatalf("LoadTypes: %v", err)
+			}
+			if len(items) != 2 {
+				t.Fatalf("loaded %d types, want 2", len(items))
+			}
+			rifter := items[0]
+			if rifter.TypeID != 587 || rifter.Name != "Rifter" || rifter.GroupID != 25 || rifter.MarketGroupID != 64 ||
+				rifter.Mass != 1067000 || !rifter.Published {
+				t.Errorf("loaded type %+v, want the Rifter", rifter)
+			}
+			if rifter.Description == "" {
+				t.Error("quoted description with a comma was not read")
+			}
+
+			// Tables missing from the dump leave their part empty
+			taxonomy, err := source.LoadTaxonomy()
+			if err != nil {
+				t.Fatalf("LoadTaxonomy: %v", err)
+			}
+			if len(taxonomy.Groups) != 1 || len(taxonomy.Categories) != 0 || len(taxonomy.MarketGroups) != 0 {
+				t.Errorf("loaded %d categories, %d groups and %d market groups, want only the group",
+					len(taxonomy.Categories), len(taxonomy.Groups), len(taxonomy.MarketGroups))
+			}
+		})
+	}
+}
+
+func checkUniverse(t *testing.T, universe *Universe) {
+	t.Helper()
+	if len(universe.Regions) != 1 || len(universe.Constellations) != 1 || len(universe.Systems) != 2 {
+		t.Fatalf("loaded %d regions, %d constellations and %d systems, want 1, 1 and 2",
+			len(universe.Regions), len(universe.Constellations), len(universe.Systems))
+	}
+
+	region := universe.Regions[0]
+	if region.RegionID != 10000048 || region.Name != "Placid" || string(region.Constellations) != "[20000561]" {
+		t.Errorf("loaded region %+v", region)
+	}
+	if region.Description != "" {
+		t.Errorf("region description %q, want none from the dump", region.Description)
+	}
+	if systems := string(universe.Constellations[0].Systems); systems != "[30003830,30003831]" {
+		t.Errorf("constellation systems %s, want both systems", systems)
+	}
+
+	system := universe.Systems[0]
+	if system.SystemID != 30003830 || system.Name != "Gallusiaux" || system.RegionID != 10000048 ||
+		system.SecurityClass != "D1" || system.SecurityStatus != 0.28 {
+		t.Errorf("loaded system %+v", system)
+	}
+	if system.StarID != 40242250 || string(system.Planets) != `[{"planet_id":40242251}]` ||
+		string(system.Stargates) != "[50000001]" {
+		t.Errorf("system celestials: star %d, planets %s, stargates %s",
+			system.StarID, system.Planets, system.Stargates)
+	}
+	if stations := string(universe.Systems[1].Stations); stations != "[60000001]" {
+		t.Errorf("second system stations %s, want the station", stations)
+	}
+
+	if len(universe.Stargates) != 2 {
+		t.Fatalf("loaded %d stargates, want 2", len(universe.Stargates))
+	}
+	for _, gate := range universe.Stargates {
+		if gate.StargateID == 50000001 && (gate.SystemID != 30003830 || gate.DestinationSystemID != 30003831 ||
+			gate.DestinationStargateID != 50000002 || gate.Name != "Stargate (Ravarin)") {
+			t.Errorf("loaded stargate %+v", gate)
+		}
+	}
+}
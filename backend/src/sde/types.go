@@ -0,0 +1,83 @@
+package sde
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// Taxonomy holds the type categories, groups and market groups.
+type Taxonomy struct {
+	Categories   []*models.ItemCategory
+	Groups       []*models.ItemGroup
+	MarketGroups []*models.MarketGroup
+}
+
+// LoadTypes reads every type from invTypes. The dump has no packaged volume
+// or radius, so those stay zero until ESI fills them in.
+func (s *Source) LoadTypes() ([]*models.ESIItem, error) {
+	var items []*models.ESIItem
+	err := s.readTable("invTypes", func(r row) error {
+		items = append(items, &models.ESIItem{
+			TypeID:        r.Int("typeID"),
+			GroupID:       r.Int("groupID"),
+			MarketGroupID: r.Int("marketGroupID"),
+			Name:          r.String("typeName"),
+			Description:   r.String("description"),
+			Mass:          r.Float("mass"),
+			Volume:        r.Float("volume"),
+			Capacity:      r.Float("capacity"),
+			PortionSize:   r.Int("portionSize"),
+			Published:     r.Bool("published"),
+		})
+		return nil
+	})
+	return items, err
+}
+
+// LoadTaxonomy reads invCategories, invGroups and invMarketGroups. Tables
+// missing from the dump are left empty.
+func (s *Source) LoadTaxonomy() (*Taxonomy, error) {
+	var taxonomy Taxonomy
+
+	err := s.readTable("invCategories", func(r row) error {
+		taxonomy.Categories = append(taxonomy.Categories, &models.ItemCategory{
+			CategoryID: r.Int("categoryID"),
+			Name:       r.String("categoryName"),
+			Published:  r.Bool("published"),
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	err = s.readTable("invGroups", func(r row) error {
+		taxonomy.Groups = append(taxonomy.Groups, &models.ItemGroup{
+			GroupID:    r.Int("groupID"),
+			CategoryID: r.Int("categoryID"),
+			Name:       r.String("groupName"),
+			Published:  r.Bool("published"),
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	err = s.readTable("invMarketGroups", func(r row) error {
+		taxonomy.MarketGroups = append(taxonomy.MarketGroups, &models.MarketGroup{
+			MarketGroupID: r.Int("marketGroupID"),
+			ParentGroupID: r.Int("parentGroupID"),
+			Name:          r.String("marketGroupName"),
+			Description:   r.String("description"),
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return &taxonomy, nil
+}
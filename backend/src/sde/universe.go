@@ -0,0 +1,212 @@
+package sde
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"sort"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// Group IDs of the celestials listed on a system.
+const (
+	groupSun      = 6
+	groupPlanet   = 7
+	groupStargate = 10
+	groupStation  = 15
+)
+
+// Universe holds the map of New Eden in the shape ESI reports it.
+type Universe struct {
+	Regions        []*models.Region
+	Constellations []*models.Constellation
+	Systems        []*models.System
+	Stargates      []*models.Stargate
+}
+
+type position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+type planet struct {
+	PlanetID int `json:"planet_id"`
+}
+
+type celestials struct {
+	starID    int
+	planets   []planet
+	stargates []int
+	stations  []int
+}
+
+type stargate struct {
+	name     string
+	systemID int
+}
+
+// LoadUniverse reads regions, constellations and systems from mapRegions,
+// mapConstellations and mapSolarSystems. Stars, planets, stargates and
+// stations are taken from mapDenormalize when the dump contains it, and
+// stargate destinations from mapJumps. The dump has no region descriptions,
+// so those stay empty until ESI fills them in.
+func (s *Source) LoadUniverse() (*Universe, error) {
+	var universe Universe
+	regions := make(map[int]*models.Region)
+	constellations := make(map[int]*models.Constellation)
+	regionConstellations := make(map[int][]int)
+	constellationSystems := make(map[int][]int)
+
+	err := s.readTable("mapRegions", func(r row) error {
+		region := &models.Region{
+			RegionID: r.Int("regionID"),
+			Name:     r.String("regionName"),
+		}
+		regions[region.RegionID] = region
+		universe.Regions = append(universe.Regions, region)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.readTable("mapConstellations", func(r row) error {
+		constellation := &models.Constellation{
+			ConstellationID: r.Int("constellationID"),
+			Name:            r.String("constellationName"),
+			RegionID:        r.Int("regionID"),
+			Position:        positionJSON(r),
+		}
+		constellations[constellation.ConstellationID] = constellation
+		regionConstellations[constellation.RegionID] = append(regionConstellations[constellation.RegionID], constellation.ConstellationID)
+		universe.Constellations = append(universe.Constellations, constellation)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	systemCelestials, stargates, err := s.loadCelestials()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.readTable("mapSolarSystems", func(r row) error {
+		system := &models.System{
+			SystemID:        r.Int("solarSystemID"),
+			ConstellationID: r.Int("constellationID"),
+			RegionID:        r.Int("regionID"),
+			Name:            r.String("solarSystemName"),
+			SecurityClass:   r.String("securityClass"),
+			SecurityStatus:  r.Float("security"),
+			Position:        positionJSON(r),
+		}
+		if c, ok := systemCelestials[system.SystemID]; ok {
+			system.StarID = c.starID
+			system.Planets = mustJSON(c.planets)
+			system.Stargates = mustJSON(c.stargates)
+			system.Stations = mustJSON(c.stations)
+		}
+		constellationSystems[system.ConstellationID] = append(constellationSystems[system.ConstellationID], system.SystemID)
+		universe.Systems = append(universe.Systems, system)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	universe.Stargates, err = s.loadStargates(stargates)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, region := range regions {
+		region.Constellations = mustJSON(sorted(regionConstellations[id]))
+	}
+	for id, constellation := range constellations {
+		constellation.Systems = mustJSON(sorted(constellationSystems[id]))
+	}
+
+	return &universe, nil
+}
+
+// loadCelestials groups the stars, planets, stargates and stations of
+// mapDenormalize by solar system and returns the stargates by ID. A dump
+// without mapDenormalize yields none.
+func (s *Source) loadCelestials() (map[int]*celestials, map[int]stargate, error) {
+	systems := make(map[int]*celestials)
+	stargates := make(map[int]stargate)
+	err := s.readTable("mapDenormalize", func(r row) error {
+		group := r.Int("groupID")
+		if group != groupSun && group != groupPlanet && group != groupStargate && group != groupStation {
+			return nil
+		}
+
+		systemID := r.Int("solarSystemID")
+		c, ok := systems[systemID]
+		if !ok {
+			c = &celestials{}
+			systems[systemID] = c
+		}
+
+		itemID := r.Int("itemID")
+		switch group {
+		case groupSun:
+			c.starID = itemID
+		case groupPlanet:
+			c.planets = append(c.planets, planet{PlanetID: itemID})
+		case groupStargate:
+			c.stargates = append(c.stargates, itemID)
+			stargates[itemID] = stargate{name: r.String("itemName"), systemID: systemID}
+		case groupStation:
+			c.stations = append(c.stations, itemID)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return systems, stargates, nil
+	}
+	return systems, stargates, err
+}
+
+// loadStargates pairs the stargates from mapDenormalize with their
+// destinations from mapJumps. A dump without mapJumps yields none.
+func (s *Source) loadStargates(stargates map[int]stargate) ([]*models.Stargate, error) {
+	var result []*models.Stargate
+	err := s.readTable("mapJumps", func(r row) error {
+		stargateID := r.Int("stargateID")
+		destinationID := r.Int("destinationID")
+		source, ok := stargates[stargateID]
+		if !ok {
+			return nil
+		}
+		result = append(result, &models.Stargate{
+			StargateID:            stargateID,
+			Name:                  source.name,
+			SystemID:              source.systemID,
+			DestinationStargateID: destinationID,
+			DestinationSystemID:   stargates[destinationID].systemID,
+		})
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return result, err
+}
+
+func positionJSON(r row) json.RawMessage {
+	return mustJSON(position{X: r.Float("x"), Y: r.Float("y"), Z: r.Float("z")})
+}
+
+func mustJSON(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func sorted(ids []int) []int {
+	sort.Ints(ids)
+	return ids
+}
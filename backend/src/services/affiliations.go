@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// affiliationBatchSize is the maximum number of character IDs ESI accepts
+// per POST /characters/affiliation/ request.
+const affiliationBatchSize = 1000
+
+// Affiliation is the current corporation and alliance of a character.
+type Affiliation struct {
+	CharacterID   int64 `json:"character_id"`
+	CorporationID int64 `json:"corporation_id"`
+	AllianceID    int64 `json:"alliance_id"`
+	FactionID     int64 `json:"faction_id"`
+}
+
+// CorporationHistoryRecord is one entry of a character's corporation history.
+type CorporationHistoryRecord struct {
+	CorporationID int64     `json:"corporation_id"`
+	RecordID      int64     `json:"record_id"`
+	StartDate     time.Time `json:"start_date"`
+	IsDeleted     bool      `json:"is_deleted"`
+}
+
+// FetchAffiliations returns the current affiliation of every character in
+// characterIDs.
+func FetchAffiliations(ctx context.Context, characterIDs []int64) ([]Affiliation, error) {
+	var affiliations []Affiliation
+	for start := 0; start < len(characterIDs); start += affiliationBatchSize {
+		end := min(start+affiliationBatchSize, len(characterIDs))
+
+		var batch []Affiliation
+		err := ESI.PostJSON(ctx, "/characters/affiliation/?datasource=tranquility", characterIDs[start:end], &batch)
+		if err != nil {
+			return nil, err
+		}
+		affiliations = append(affiliations, batch...)
+	}
+	return affiliations, nil
+}
+
+// FetchCorporationHistory returns the corporation history of a character,
+// newest first as ESI reports it.
+func FetchCorporationHistory(ctx context.Context, characterID int64) ([]CorporationHistoryRecord, error) {
+	var history []CorporationHistoryRecord
+	path := fmt.Sprintf("/characters/%d/corporationhistory/?datasource=tranquility", characterID)
+	err := ESI.GetJSON(ctx, path, &history)
+	return history, err
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BulkOptions configures FetchBulk.
+type BulkOptions struct {
+	// Concurrency is the maximum number of fetches in flight.
+	Concurrency int
+	// Retries is the number of extra passes over IDs that failed.
+	Retries int
+	// RetryDelay is the pause before each retry pass.
+	RetryDelay time.Duration
+	// Progress, if set, is called whenever an ID succeeds or runs out of
+	// retries.
+	Progress func(done, total int)
+	// Skip, if set, reports IDs that need no fetch at all, e.g. because they
+	// were imported from the static data export.
+	Skip func(id int) bool
+}
+
+// BulkError lists the IDs that still failed after all retries.
+type BulkError struct {
+	Failed map[int]error
+}
+
+func (e *BulkError) Error() string {
+	ids := e.FailedIDs()
+	var sample []string
+	for _, id := range ids {
+		if len(sample) == 3 {
+			break
+		}
+		sample = append(sample, fmt.Sprintf("%d: %v", id, e.Failed[id]))
+	}
+	return fmt.Sprintf("%d IDs failed (%s)", len(ids), strings.Join(sample, "; "))
+}
+
+// FailedIDs returns the failed IDs in ascending order.
+func (e *BulkError) FailedIDs() []int {
+	ids := make([]int, 0, len(e.Failed))
+	for id := range e.Failed {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// FetchBulk calls fetch for every ID with bounded concurrency and returns the
+// successful results keyed by ID. IDs that fail are retried according to
+// opts; those that never succeed are reported in a *BulkError. When ctx is
+// cancelled no new fetches are started and ctx.Err() is returned together
+// with the results gathered so far.
+func FetchBulk[T any](ctx context.Context, ids []int, opts BulkOptions, fetch func(ctx context.Context, id int) (T, error)) (map[int]T, error) {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ids = opts.pending(ids)
+	results := make(map[int]T, len(ids))
+	failed := make(map[int]error)
+	done := 0
+	var mu sync.Mutex
+
+	pending := ids
+	for attempt := 0; attempt <= opts.Retries && len(pending) > 0; attempt++ {
+		if attempt > 0 && opts.RetryDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return results, ctx.Err()
+			case <-time.After(opts.RetryDelay):
+			}
+		}
+
+		lastAttempt := attempt == opts.Retries
+		retry := make(map[int]error)
+
+		var wg sync.WaitGroup
+		semaphore := make(chan struct{}, concurrency)
+
+	dispatch:
+		for _, id := range pending {
+			// select picks at random when a slot frees up after cancellation
+			if ctx.Err() != nil {
+				break dispatch
+			}
+			select {
+			case <-ctx.Done():
+				break dispatch
+			case semaphore <- struct{}{}:
+			}
+
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+
+				result, err := fetch(ctx, id)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if !lastAttempt && isRetryable(err) {
+						retry[id] = err
+						return
+					}
+					failed[id] = err
+				} else {
+					results[id] = result
+				}
+				done++
+				if opts.Progress != nil {
+					opts.Progress(done, len(ids))
+				}
+			}(id)
+		}
+		wg.Wait()
+
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		pending = make([]int, 0, len(retry))
+		for id := range retry {
+			pending = append(pending, id)
+		}
+	}
+
+	if len(failed) > 0 {
+		return results, &BulkError{Failed: failed}
+	}
+	return results, nil
+}
+
+// pending returns the IDs that are not skipped.
+func (opts BulkOptions) pending(ids []int) []int {
+	if opts.Skip == nil {
+		return ids
+	}
+	pending := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !opts.Skip(id) {
+			pending = append(pending, id)
+		}
+	}
+	return pending
+}
+
+// isRetryable reports whether a failed fetch is worth another attempt. ESI
+// answering 404 will not change on a retry.
+func isRetryable(err error) bool {
+	return !IsESINotFound(err)
+}
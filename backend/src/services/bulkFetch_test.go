@@ -0,0 +1,158 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// flakyESI answers /items/<id>/ with the ID, except for 404s on missing IDs
+// and 500s on the first failures[id] requests of an ID.
+type flakyESI struct {
+	missing  map[string]bool
+	failures map[string]int
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (f *flakyESI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/items/"), "/")
+	f.mu.Lock()
+	f.requests[id]++
+	attempt := f.requests[id]
+	f.mu.Unlock()
+
+	w.Header().Set("X-ESI-Error-Limit-Remain", "100")
+	w.Header().Set("X-ESI-Error-Limit-Reset", "60")
+	switch {
+	case f.missing[id]:
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+	case attempt <= f.failures[id]:
+		http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+	default:
+		fmt.Fprint(w, id)
+	}
+}
+
+func (f *flakyESI) count(id int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[fmt.Sprint(id)]
+}
+
+func newFlakyESI(t *testing.T, missing []string, failures map[string]int) (*flakyESI, *services.ESIClient) {
+	t.Helper()
+	upstream := &flakyESI{missing: make(map[string]bool), failures: failures, requests: make(map[string]int)}
+	for _, id := range missing {
+		upstream.missing[id] = true
+	}
+	server := httptest.NewServer(upstream)
+	t.Cleanup(server.Close)
+	return upstream, services.NewESIClient(server.URL)
+}
+
+func fetchItem(client *services.ESIClient) func(ctx context.Context, id int) (int, error) {
+	return func(ctx context.Context, id int) (int, error) {
+		var got int
+		err := client.GetJSON(ctx, fmt.Sprintf("/items/%d/", id), &got)
+		return got, err
+	}
+}
+
+func TestFetchBulkRetries(t *testing.T) {
+	// 2 recovers on the second attempt, 3 is missing and 4 never recovers
+	upstream, client := newFlakyESI(t, []string{"3"}, map[string]int{"2": 1, "4": 10})
+
+	var progress []int
+	opts := services.BulkOptions{
+		Concurrency: 2,
+		Retries:     2,
+		RetryDelay:  10 * time.Millisecond,
+		Progress:    func(done, total int) { progress = append(progress, done) },
+	}
+	results, err := services.FetchBulk(context.Background(), []int{1, 2, 3, 4}, opts, fetchItem(client))
+
+	if len(results) != 2 || results[1] != 1 || results[2] != 2 {
+		t.Errorf("results %v, want 1 and 2", results)
+	}
+	var bulkErr *services.BulkError
+	if !errors.As(err, &bulkErr) {
+		t.Fatalf("FetchBulk error %v, want a *BulkError", err)
+	}
+	if ids := bulkErr.FailedIDs(); len(ids) != 2 || ids[0] != 3 || ids[1] != 4 {
+		t.Errorf("failed IDs %v, want [3 4]", ids)
+	}
+	if !services.IsESINotFound(bulkErr.Failed[3]) {
+		t.Errorf("ID 3 failed with %v, want the 404", bulkErr.Failed[3])
+	}
+
+	for id, want := range map[int]int{1: 1, 2: 2, 3: 1, 4: 3} {
+		if got := upstream.count(id); got != want {
+			t.Errorf("ID %d requested %d times, want %d", id, got, want)
+		}
+	}
+	if len(progress) != 4 || progress[3] != 4 {
+		t.Errorf("progress reported %v, want every ID done once", progress)
+	}
+}
+
+func TestFetchBulkSkip(t *testing.T) {
+	upstream, client := newFlakyESI(t, nil, nil)
+
+	opts := services.BulkOptions{Skip: func(id int) bool { return id == 2 }}
+	results, err := services.FetchBulk(context.Background(), []int{1, 2}, opts, fetchItem(client))
+	if err != nil {
+		t.Fatalf("FetchBulk: %v", err)
+	}
+	if len(results) != 1 || upstream.count(2) != 0 {
+		t.Errorf("results %v after %d requests for the skipped ID", results, upstream.count(2))
+	}
+}
+
+func TestFetchBulkStopsWhenCancelled(t *testing.T) {
+	_, client := newFlakyESI(t, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetched := 0
+	fetch := func(ctx context.Context, id int) (int, error) {
+		fetched++
+		// The client disconnects while the first ID is fetched
+		cancel()
+		return fetchItem(client)(ctx, id)
+	}
+
+	_, err := services.FetchBulk(ctx, []int{1, 2, 3, 4, 5}, services.BulkOptions{Concurrency: 1}, fetch)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchBulk error %v, want context.Canceled", err)
+	}
+	if fetched != 1 {
+		t.Errorf("fetched %d IDs, want none after the cancellation", fetched)
+	}
+}
+
+func TestFetchBulkStopsWhenCancelledBeforeRetry(t *testing.T) {
+	_, client := newFlakyESI(t, nil, map[string]int{"1": 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	opts := services.BulkOptions{Retries: 1, RetryDelay: time.Hour}
+
+	start := time.Now()
+	_, err := services.FetchBulk(ctx, []int{1}, opts, fetchItem(client))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FetchBulk error %v, want context.DeadlineExceeded", err)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("FetchBulk returned after %s, not when cancelled", waited)
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func FetchCorporationInfo(ctx context.Context, corporationID int64) (*models.Corporation, error) {
+	var esiCorporation struct {
+		Name        string `json:"name"`
+		Ticker      string `json:"ticker"`
+		MemberCount int    `json:"member_count"`
+		AllianceID  int64  `json:"alliance_id"`
+		CEOID       int64  `json:"ceo_id"`
+	}
+	path := fmt.Sprintf("/corporations/%d/?datasource=tranquility", corporationID)
+	if err := ESI.GetJSON(ctx, path, &esiCorporation); err != nil {
+		return nil, err
+	}
+
+	return &models.Corporation{
+		ID:          corporationID,
+		Name:        esiCorporation.Name,
+		Ticker:      esiCorporation.Ticker,
+		MemberCount: esiCorporation.MemberCount,
+		AllianceID:  esiCorporation.AllianceID,
+		CEOID:       esiCorporation.CEOID,
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+func FetchAllianceInfo(ctx context.Context, allianceID int64) (*models.Alliance, error) {
+	var esiAlliance struct {
+		Name                  string `json:"name"`
+		Ticker                string `json:"ticker"`
+		ExecutorCorporationID int64  `json:"executor_corporation_id"`
+	}
+	path := fmt.Sprintf("/alliances/%d/?datasource=tranquility", allianceID)
+	if err := ESI.GetJSON(ctx, path, &esiAlliance); err != nil {
+		return nil, err
+	}
+
+	return &models.Alliance{
+		ID:                    allianceID,
+		Name:                  esiAlliance.Name,
+		Ticker:                esiAlliance.Ticker,
+		ExecutorCorporationID: esiAlliance.ExecutorCorporationID,
+		UpdatedAt:             time.Now(),
+	}, nil
+}
+
+func FetchAllianceCorporationIDs(ctx context.Context, allianceID int64) ([]int64, error) {
+	var corporationIDs []int64
+	path := fmt.Sprintf("/alliances/%d/corporations/?datasource=tranquility", allianceID)
+	err := ESI.GetJSON(ctx, path, &corporationIDs)
+	return corporationIDs, err
+}
@@ -1,65 +1,28 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"strings"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/tadeasf/eve-ran/src/db/models"
 )
 
-const esiBaseURL = "https://esi.evetech.net/latest"
-
-var (
-	esiClient = &http.Client{
-		Timeout: 60 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 100,
-			IdleConnTimeout:     90 * time.Second,
-		},
-	}
-)
-
-func FetchRegionIDs() ([]int, error) {
-	url := fmt.Sprintf("%s/universe/regions/?datasource=tranquility", esiBaseURL)
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
+func FetchRegionIDs(ctx context.Context) ([]int, error) {
 	var regionIDs []int
-	err = json.Unmarshal(body, &regionIDs)
+	_, err := ESI.GetJSONCached(ctx, "/universe/regions/?datasource=tranquility", &regionIDs)
 	return regionIDs, err
 }
 
-func FetchRegionInfo(regionID int) (*models.Region, error) {
-	url := fmt.Sprintf("%s/universe/regions/%d/?datasource=tranquility&language=en", esiBaseURL, regionID)
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
+func FetchRegionInfo(ctx context.Context, regionID int) (*models.Region, bool, CacheCommit, error) {
 	var region models.Region
-	err = json.Unmarshal(body, &region)
+	path := fmt.Sprintf("/universe/regions/%d/?datasource=tranquility&language=en", regionID)
+	changed, commit, err := ESI.FetchJSONCached(ctx, path, &region)
 	if err != nil {
-		return nil, err
+		return nil, false, nil, err
 	}
 
 	// Ensure Constellations is initialized as an empty slice if it's null
@@ -67,352 +30,289 @@ func FetchRegionInfo(regionID int) (*models.Region, error) {
 		region.Constellations = json.RawMessage("[]")
 	}
 
-	return &region, nil
+	return &region, changed, commit, nil
 }
 
-func FetchSystemIDs() ([]int, error) {
-	url := fmt.Sprintf("%s/universe/systems/?datasource=tranquility", esiBaseURL)
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
+func FetchSystemIDs(ctx context.Context) ([]int, error) {
 	var systemIDs []int
-	err = json.Unmarshal(body, &systemIDs)
+	_, err := ESI.GetJSONCached(ctx, "/universe/systems/?datasource=tranquility", &systemIDs)
 	return systemIDs, err
 }
 
-func FetchSystemInfo(systemID int) (*models.System, error) {
-	url := fmt.Sprintf("%s/universe/systems/%d/?datasource=tranquility&language=en", esiBaseURL, systemID)
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+func FetchSystemInfo(ctx context.Context, systemID int) (*models.System, bool, CacheCommit, error) {
+	var system models.System
+	path := fmt.Sprintf("/universe/systems/%d/?datasource=tranquility&language=en", systemID)
+	changed, commit, err := ESI.FetchJSONCached(ctx, path, &system)
 	if err != nil {
-		return nil, err
+		return nil, false, nil, err
 	}
+	return &system, changed, commit, nil
+}
 
-	var system models.System
-	err = json.Unmarshal(body, &system)
-	return &system, err
+func FetchConstellationIDs(ctx context.Context) ([]int, error) {
+	var constellationIDs []int
+	_, err := ESI.GetJSONCached(ctx, "/universe/constellations/?datasource=tranquility", &constellationIDs)
+	return constellationIDs, err
 }
 
-func FetchConstellationIDs() ([]int, error) {
-	url := fmt.Sprintf("%s/universe/constellations/?datasource=tranquility", esiBaseURL)
-	resp, err := http.Get(url)
+func FetchConstellationInfo(ctx context.Context, constellationID int) (*models.Constellation, bool, CacheCommit, error) {
+	var constellation models.Constellation
+	path := fmt.Sprintf("/universe/constellations/%d/?datasource=tranquility&language=en", constellationID)
+	changed, commit, err := ESI.FetchJSONCached(ctx, path, &constellation)
 	if err != nil {
-		return nil, err
+		return nil, false, nil, err
 	}
-	defer resp.Body.Close()
+	return &constellation, changed, commit, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+// FetchItemIDPage fetches a single page of type IDs and returns it together
+// with the total number of pages reported by ESI.
+func FetchItemIDPage(ctx context.Context, page int) ([]int, int, error) {
+	return fetchIDPage(ctx, "/universe/types/?datasource=tranquility", page)
+}
 
-	var constellationIDs []int
-	err = json.Unmarshal(body, &constellationIDs)
-	return constellationIDs, err
+func FetchItemIDs(ctx context.Context) ([]int, error) {
+	return fetchPagedIDs(ctx, "/universe/types/?datasource=tranquility")
 }
 
-func FetchConstellationInfo(constellationID int) (*models.Constellation, error) {
-	url := fmt.Sprintf("%s/universe/constellations/%d/?datasource=tranquility&language=en", esiBaseURL, constellationID)
-	resp, err := http.Get(url)
+// fetchIDPage fetches a single page of the ID list at path and returns it
+// together with the total number of pages reported by ESI.
+func fetchIDPage(ctx context.Context, path string, page int) ([]int, int, error) {
+	body, header, err := ESI.Get(ctx, fmt.Sprintf("%s&page=%d", path, page))
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	var ids []int
+	if err := json.Unmarshal(body, &ids); err != nil {
+		return nil, 0, err
 	}
 
-	var constellation models.Constellation
-	err = json.Unmarshal(body, &constellation)
-	return &constellation, err
+	pages, err := strconv.Atoi(header.Get("X-Pages"))
+	if err != nil {
+		pages = page
+	}
+	return ids, pages, nil
 }
 
-func FetchItemIDs() ([]int, error) {
-	var allItemIDs []int
+// fetchPagedIDs fetches every page of the ID list at path.
+func fetchPagedIDs(ctx context.Context, path string) ([]int, error) {
+	var allIDs []int
 	page := 1
 	for {
-		url := fmt.Sprintf("%s/universe/types/?datasource=tranquility&page=%d", esiBaseURL, page)
-		resp, err := http.Get(url)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		var itemIDs []int
-		err = json.Unmarshal(body, &itemIDs)
+		ids, pages, err := fetchIDPage(ctx, path, page)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(itemIDs) == 0 {
+		allIDs = append(allIDs, ids...)
+		if page >= pages || len(ids) == 0 {
 			break
 		}
-
-		allItemIDs = append(allItemIDs, itemIDs...)
 		page++
 	}
 
-	return allItemIDs, nil
+	return allIDs, nil
 }
 
-func FetchItemInfo(itemID int) (*models.ESIItem, error) {
-	url := fmt.Sprintf("%s/universe/types/%d/?datasource=tranquility&language=en", esiBaseURL, itemID)
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+func FetchItemInfo(ctx context.Context, itemID int) (*models.ESIItem, bool, CacheCommit, error) {
+	var item models.ESIItem
+	path := fmt.Sprintf("/universe/types/%d/?datasource=tranquility&language=en", itemID)
+	changed, commit, err := ESI.FetchJSONCached(ctx, path, &item)
 	if err != nil {
-		return nil, err
+		return nil, false, nil, err
 	}
-
-	var item models.ESIItem
-	err = json.Unmarshal(body, &item)
-	return &item, err
+	return &item, changed, commit, nil
 }
 
-func FetchAllItems(concurrency int) ([]*models.ESIItem, error) {
-	itemIDs, err := FetchItemIDs()
-	if err != nil {
+// FetchCharacterInfo fetches the public information of a character.
+func FetchCharacterInfo(characterID int64) (*models.Character, error) {
+	var esiCharacter struct {
+		Name           string  `json:"name"`
+		SecurityStatus float64 `json:"security_status"`
+		Title          string  `json:"title"`
+		RaceID         int     `json:"race_id"`
+		CorporationID  int64   `json:"corporation_id"`
+		AllianceID     int64   `json:"alliance_id"`
+	}
+	path := fmt.Sprintf("/characters/%d/?datasource=tranquility", characterID)
+	if err := ESI.GetJSON(context.Background(), path, &esiCharacter); err != nil {
 		return nil, err
 	}
 
-	items := make([]*models.ESIItem, 0, len(itemIDs))
-	itemChan := make(chan *models.ESIItem, len(itemIDs))
-	errChan := make(chan error, len(itemIDs))
-
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, concurrency)
-
-	for _, itemID := range itemIDs {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			item, err := FetchItemInfo(id)
-			if err != nil {
-				errChan <- err
-				return
-			}
-			itemChan <- item
-		}(itemID)
-	}
-
-	go func() {
-		wg.Wait()
-		close(itemChan)
-		close(errChan)
-	}()
+	return &models.Character{
+		ID:             characterID,
+		Name:           esiCharacter.Name,
+		SecurityStatus: esiCharacter.SecurityStatus,
+		Title:          esiCharacter.Title,
+		RaceID:         esiCharacter.RaceID,
+		CorporationID:  esiCharacter.CorporationID,
+		AllianceID:     esiCharacter.AllianceID,
+	}, nil
+}
 
-	for item := range itemChan {
-		items = append(items, item)
-	}
+// SyncStats reports how many entities a sync checked against ESI and how many
+// of them actually changed since the previous sync.
+type SyncStats struct {
+	Checked int `json:"checked"`
+	Changed int `json:"changed"`
+	Failed  int `json:"failed"`
+}
 
-	if len(errChan) > 0 {
-		return items, <-errChan
-	}
+// Fetched is an entity that changed since the previous sync. Its ESI cache
+// entry is only written by Commit, which callers run once it is stored.
+type Fetched[T any] struct {
+	Value  T
+	commit CacheCommit
+}
 
-	return items, nil
+// Commit writes the ESI cache entry of the stored entity.
+func (f Fetched[T]) Commit() error {
+	return f.commit()
 }
 
-func FetchAllRegions(concurrency int) ([]*models.Region, error) {
-	regionIDs, err := FetchRegionIDs()
-	if err != nil {
-		return nil, err
+// FetchedValues returns the entities of fetched.
+func FetchedValues[T any](fetched []Fetched[T]) []T {
+	values := make([]T, len(fetched))
+	for i := range fetched {
+		values[i] = fetched[i].Value
 	}
+	return values
+}
 
-	regions := make([]*models.Region, 0, len(regionIDs))
-	regionChan := make(chan *models.Region, len(regionIDs))
-	errChan := make(chan error, len(regionIDs))
-
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, concurrency)
-
-	for _, regionID := range regionIDs {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			region, err := FetchRegionInfo(id)
-			if err != nil {
-				errChan <- err
-				return
-			}
-			regionChan <- region
-		}(regionID)
+// CommitFetched writes the ESI cache entries of the stored entities.
+func CommitFetched[T any](fetched []Fetched[T]) error {
+	for _, f := range fetched {
+		if err := f.Commit(); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	go func() {
-		wg.Wait()
-		close(regionChan)
-		close(errChan)
-	}()
+// cachedFetch is the result of a conditional ESI fetch.
+type cachedFetch[T any] struct {
+	value   T
+	changed bool
+	commit  CacheCommit
+}
 
-	for region := range regionChan {
-		regions = append(regions, region)
+// fetchChanged runs fetch for every ID through FetchBulk and returns only the
+// entities that changed since the previous sync. The cache entries of
+// unchanged entities are written right away.
+func fetchChanged[T any](ctx context.Context, ids []int, opts BulkOptions, fetch func(ctx context.Context, id int) (T, bool, CacheCommit, error)) ([]Fetched[T], SyncStats, error) {
+	results, err := FetchBulk(ctx, ids, opts, func(ctx context.Context, id int) (cachedFetch[T], error) {
+		value, changed, commit, err := fetch(ctx, id)
+		if err == nil && !changed {
+			err = commit()
+		}
+		return cachedFetch[T]{value: value, changed: changed, commit: commit}, err
+	})
+
+	stats := SyncStats{Checked: len(opts.pending(ids))}
+	changed := make([]Fetched[T], 0, len(results))
+	for _, result := range results {
+		if result.changed {
+			changed = append(changed, Fetched[T]{Value: result.value, commit: result.commit})
+		}
 	}
+	stats.Changed = len(changed)
 
-	if len(errChan) > 0 {
-		return regions, <-errChan
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		stats.Failed = len(bulkErr.Failed)
 	}
-
-	return regions, nil
+	return changed, stats, err
 }
 
-func FetchAllConstellations(concurrency int) ([]*models.Constellation, error) {
-	constellationIDs, err := FetchConstellationIDs()
+// FetchAllItems returns the items that changed since the previous sync.
+func FetchAllItems(ctx context.Context, opts BulkOptions) ([]Fetched[*models.ESIItem], SyncStats, error) {
+	itemIDs, err := FetchItemIDs(ctx)
 	if err != nil {
-		return nil, err
-	}
-
-	constellations := make([]*models.Constellation, 0, len(constellationIDs))
-	constellationChan := make(chan *models.Constellation, len(constellationIDs))
-	errChan := make(chan error, len(constellationIDs))
-
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, concurrency)
-
-	for _, constellationID := range constellationIDs {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			constellation, err := FetchConstellationInfo(id)
-			if err != nil {
-				errChan <- err
-				return
-			}
-			constellationChan <- constellation
-		}(constellationID)
+		return nil, SyncStats{}, err
 	}
-
-	go func() {
-		wg.Wait()
-		close(constellationChan)
-		close(errChan)
-	}()
-
-	for constellation := range constellationChan {
-		constellations = append(constellations, constellation)
-	}
-
-	if len(errChan) > 0 {
-		return constellations, <-errChan
-	}
-
-	return constellations, nil
+	return fetchChanged(ctx, itemIDs, opts, FetchItemInfo)
 }
 
-func FetchAllSystems(concurrency int) ([]*models.System, error) {
-	systemIDs, err := FetchSystemIDs()
+// FetchAllRegions returns the regions that changed since the previous sync.
+func FetchAllRegions(ctx context.Context, opts BulkOptions) ([]Fetched[*models.Region], SyncStats, error) {
+	regionIDs, err := FetchRegionIDs(ctx)
 	if err != nil {
-		return nil, err
-	}
-
-	systems := make([]*models.System, 0, len(systemIDs))
-	systemChan := make(chan *models.System, len(systemIDs))
-	errChan := make(chan error, len(systemIDs))
-
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, concurrency)
-
-	for _, systemID := range systemIDs {
-		wg.Add(1)
-		go func(id int) {
-			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			system, err := FetchSystemInfo(id)
-			if err != nil {
-				errChan <- err
-				return
-			}
-			systemChan <- system
-		}(systemID)
-	}
-
-	go func() {
-		wg.Wait()
-		close(systemChan)
-		close(errChan)
-	}()
-
-	for system := range systemChan {
-		systems = append(systems, system)
+		return nil, SyncStats{}, err
 	}
+	return fetchChanged(ctx, regionIDs, opts, FetchRegionInfo)
+}
 
-	if len(errChan) > 0 {
-		return systems, <-errChan
+// FetchAllConstellations returns the constellations that changed since the
+// previous sync.
+func FetchAllConstellations(ctx context.Context, opts BulkOptions) ([]Fetched[*models.Constellation], SyncStats, error) {
+	constellationIDs, err := FetchConstellationIDs(ctx)
+	if err != nil {
+		return nil, SyncStats{}, err
 	}
-
-	return systems, nil
+	return fetchChanged(ctx, constellationIDs, opts, FetchConstellationInfo)
 }
 
-func FetchKillmailFromESI(killmailID int64, hash string) (*models.Kill, error) {
-	url := fmt.Sprintf("%s/killmails/%d/%s/?datasource=tranquility", esiBaseURL, killmailID, hash)
-	req, err := http.NewRequest("GET", url, nil)
+// FetchAllSystems returns the systems that changed since the previous sync,
+// with their region ID resolved through the constellation.
+func FetchAllSystems(ctx context.Context, opts BulkOptions) ([]Fetched[*models.System], SyncStats, error) {
+	systemIDs, err := FetchSystemIDs(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return nil, SyncStats{}, err
 	}
-	req.Header.Set("User-Agent", "EVE Ran Application - GitHub: tadeasf/eve-ran")
+	return fetchChanged(ctx, systemIDs, opts, func(ctx context.Context, id int) (*models.System, bool, CacheCommit, error) {
+		system, changed, commit, err := FetchSystemInfo(ctx, id)
+		if err != nil || !changed {
+			return system, changed, commit, err
+		}
 
-	resp, err := esiClient.Do(req)
-	if err != nil {
-		if strings.Contains(err.Error(), "timeout") {
-			return nil, fmt.Errorf("ESI timeout: %v", err)
+		// Fetch constellation to get region_id. Its cache entry is left to
+		// the constellation sync, which stores it.
+		constellation, _, _, err := FetchConstellationInfo(ctx, system.ConstellationID)
+		if err != nil {
+			return nil, false, nil, fmt.Errorf("error fetching constellation for system %d: %w", id, err)
 		}
-		return nil, fmt.Errorf("error making request: %v", err)
-	}
-	defer resp.Body.Close()
+		system.RegionID = constellation.RegionID
+		return system, true, commit, nil
+	})
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
-	}
+// esiKillmail mirrors the ESI killmail payload.
+type esiKillmail struct {
+	KillmailID    int64     `json:"killmail_id"`
+	KillmailTime  time.Time `json:"killmail_time"`
+	SolarSystemID int       `json:"solar_system_id"`
+	Victim        struct {
+		AllianceID    int64 `json:"alliance_id"`
+		CharacterID   int64 `json:"character_id"`
+		CorporationID int64 `json:"corporation_id"`
+		DamageTaken   int   `json:"damage_taken"`
+		ShipTypeID    int   `json:"ship_type_id"`
+		Position      struct {
+			X float64 `json:"x"`
+			Y float64 `json:"y"`
+			Z float64 `json:"z"`
+		} `json:"position"`
+		Items models.ItemArray `json:"items"`
+	} `json:"victim"`
+	Attackers []json.RawMessage `json:"attackers"`
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ESI returned non-OK status: %d, body: %s", resp.StatusCode, string(body))
+func FetchKillmailFromESI(killmailID int64, hash string) (*models.Kill, error) {
+	path := fmt.Sprintf("/killmails/%d/%s/?datasource=tranquility", killmailID, hash)
+	body, _, err := ESI.Get(context.Background(), path)
+	if err != nil {
+		return nil, err
 	}
+	return ParseKillmail(body)
+}
 
-	var esiKill struct {
-		KillmailID    int64             `json:"killmail_id"`
-		KillmailTime  time.Time         `json:"killmail_time"`
-		SolarSystemID int               `json:"solar_system_id"`
-		Victim        models.Victim     `json:"victim"`
-		Attackers     []models.Attacker `json:"attackers"`
-	}
-	err = json.Unmarshal(body, &esiKill)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling killmail: %v", err)
+// ParseKillmail converts a killmail in ESI format, as served by ESI and
+// embedded in zKillboard packages, into a Kill.
+func ParseKillmail(data []byte) (*models.Kill, error) {
+	var esiKill esiKillmail
+	if err := json.Unmarshal(data, &esiKill); err != nil {
+		return nil, fmt.Errorf("error decoding killmail: %w", err)
 	}
 
 	// Marshal the Attackers slice into JSON
@@ -425,44 +325,19 @@ func FetchKillmailFromESI(killmailID int64, hash string) (*models.Kill, error) {
 		KillmailID:    esiKill.KillmailID,
 		KillmailTime:  esiKill.KillmailTime,
 		SolarSystemID: esiKill.SolarSystemID,
-		Victim:        esiKill.Victim,
-		Attackers:     attackersJSON,
+		Victim: models.Victim{
+			AllianceID:    esiKill.Victim.AllianceID,
+			CharacterID:   esiKill.Victim.CharacterID,
+			CorporationID: esiKill.Victim.CorporationID,
+			DamageTaken:   esiKill.Victim.DamageTaken,
+			ShipTypeID:    esiKill.Victim.ShipTypeID,
+			Position: models.Position{
+				X: esiKill.Victim.Position.X,
+				Y: esiKill.Victim.Position.Y,
+				Z: esiKill.Victim.Position.Z,
+			},
+			Items: esiKill.Victim.Items,
+		},
+		Attackers: attackersJSON,
 	}, nil
 }
-
-func IsESITimeout(err error) bool {
-	return strings.Contains(err.Error(), "Timeout contacting tranquility")
-}
-
-func IsESIErrorLimit(err error) bool {
-	return strings.Contains(err.Error(), "ESI error limit reached") ||
-		strings.Contains(err.Error(), "This software has exceeded the error limit for ESI")
-}
-
-// Add this function to the existing esi.go file
-
-func FetchConstellation(constellationID int) (*models.Constellation, error) {
-	url := fmt.Sprintf("%s/universe/constellations/%d/?datasource=tranquility&language=en", esiBaseURL, constellationID)
-	resp, err := esiClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching constellation: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ESI returned non-OK status: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
-	}
-
-	var constellation models.Constellation
-	err = json.Unmarshal(body, &constellation)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling constellation data: %v", err)
-	}
-
-	return &constellation, nil
-}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+const esiUserAgent = "EVE Ran Application - GitHub: tadeasf/eve-ran"
+
+var (
+	// ErrESIErrorLimited is returned when ESI refuses a request because the
+	// application exhausted its error budget (HTTP 420).
+	ErrESIErrorLimited = errors.New("ESI error limit reached")
+	// ErrESITimeout is returned when the request to ESI timed out.
+	ErrESITimeout = errors.New("ESI timeout")
+)
+
+// ESIError is returned for every non-2xx ESI response other than 420.
+type ESIError struct {
+	StatusCode int
+	URL        string
+	Body       string
+}
+
+func (e *ESIError) Error() string {
+	return fmt.Sprintf("ESI returned status %d for %s: %s", e.StatusCode, e.URL, e.Body)
+}
+
+// ESICache persists ESI responses together with their caching headers.
+type ESICache interface {
+	Get(path string) (*models.ESICacheEntry, error)
+	Put(entry *models.ESICacheEntry) error
+}
+
+// ESIClient is the single HTTP client used for every ESI request. It tracks
+// the ESI error budget and pauses all callers before the budget runs out.
+type ESIClient struct {
+	httpClient *http.Client
+	errors     *ESIErrorManager
+	cache      ESICache
+	baseURL    string
+}
+
+// ESI is the shared ESI client.
+var ESI = NewESIClient(defaultESIBaseURL)
+
+func NewESIClient(baseURL string) *ESIClient {
+	return &ESIClient{
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+		errors:  &ESIErrorManager{},
+		baseURL: baseURL,
+	}
+}
+
+// Do sends a request for path (relative to the ESI base URL) and returns the
+// response body and headers of a successful response.
+func (c *ESIClient) Do(ctx context.Context, method, path string, body io.Reader) ([]byte, http.Header, error) {
+	_, respBody, header, err := c.send(ctx, method, path, body, nil)
+	return respBody, header, err
+}
+
+// send performs the request with the given extra headers. A 304 response is
+// returned as a success with an empty body.
+func (c *ESIClient) send(ctx context.Context, method, path string, body io.Reader, header http.Header) (int, []byte, http.Header, error) {
+	if err := c.errors.Wait(ctx); err != nil {
+		return 0, nil, nil, err
+	}
+
+	url := c.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("error creating request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", esiUserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return 0, nil, nil, fmt.Errorf("%w: %v", ErrESITimeout, err)
+		}
+		return 0, nil, nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The error limit headers already count this response if it is an error
+	limited := c.errors.UpdateFromHeaders(resp.Header)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode == 420 {
+		reset, _ := strconv.Atoi(resp.Header.Get("X-ESI-Error-Limit-Reset"))
+		c.errors.UpdateLimits(0, reset)
+		return 0, nil, nil, ErrESIErrorLimited
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return resp.StatusCode, nil, resp.Header, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if !limited {
+			c.errors.DecrementErrorCount()
+		}
+		return 0, nil, nil, &ESIError{StatusCode: resp.StatusCode, URL: url, Body: string(respBody)}
+	}
+
+	return resp.StatusCode, respBody, resp.Header, nil
+}
+
+// Get fetches path and returns the response body and headers.
+func (c *ESIClient) Get(ctx context.Context, path string) ([]byte, http.Header, error) {
+	return c.Do(ctx, http.MethodGet, path, nil)
+}
+
+// GetJSON fetches path and decodes the JSON response into v.
+func (c *ESIClient) GetJSON(ctx context.Context, path string, v interface{}) error {
+	body, _, err := c.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// PostJSON sends payload as JSON to path and decodes the JSON response into v.
+func (c *ESIClient) PostJSON(ctx context.Context, path string, payload, v interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	body, _, err := c.Do(ctx, http.MethodPost, path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// CacheCommit writes the cache entry of a response fetched with
+// FetchJSONCached. Callers run it once they stored what the response holds,
+// so a response that never got stored is fetched in full on the next sync
+// instead of being answered 304.
+type CacheCommit func() error
+
+// GetJSONCached behaves like GetJSON but honours the ETag and Expires headers
+// of previous responses stored in the client's cache. The returned bool
+// reports whether the payload differs from the cached copy; v is populated in
+// both cases.
+func (c *ESIClient) GetJSONCached(ctx context.Context, path string, v interface{}) (bool, error) {
+	changed, commit, err := c.FetchJSONCached(ctx, path, v)
+	if err != nil {
+		return false, err
+	}
+	return changed, commit()
+}
+
+// FetchJSONCached behaves like GetJSONCached but leaves writing the cache
+// entry to the returned commit.
+func (c *ESIClient) FetchJSONCached(ctx context.Context, path string, v interface{}) (bool, CacheCommit, error) {
+	if c.cache == nil {
+		return true, noCacheCommit, c.GetJSON(ctx, path, v)
+	}
+
+	entry, err := c.cache.Get(path)
+	if err != nil {
+		return false, nil, fmt.Errorf("error reading ESI cache: %w", err)
+	}
+
+	if entry != nil && time.Now().Before(entry.Expires) {
+		return false, noCacheCommit, json.Unmarshal(entry.Body, v)
+	}
+
+	header := http.Header{}
+	if entry != nil && entry.ETag != "" {
+		header.Set("If-None-Match", entry.ETag)
+	}
+
+	status, body, respHeader, err := c.send(ctx, http.MethodGet, path, nil, header)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if status == http.StatusNotModified && entry != nil {
+		entry.Expires = parseExpires(respHeader)
+		return false, c.cacheCommit(entry), json.Unmarshal(entry.Body, v)
+	}
+
+	changed := entry == nil || !bytes.Equal(entry.Body, body)
+	commit := c.cacheCommit(&models.ESICacheEntry{
+		Path:    path,
+		ETag:    respHeader.Get("ETag"),
+		Expires: parseExpires(respHeader),
+		Body:    body,
+	})
+	if err := json.Unmarshal(body, v); err != nil {
+		return false, nil, err
+	}
+	return changed, commit, nil
+}
+
+func (c *ESIClient) cacheCommit(entry *models.ESICacheEntry) CacheCommit {
+	return func() error {
+		if err := c.cache.Put(entry); err != nil {
+			return fmt.Errorf("error writing ESI cache: %w", err)
+		}
+		return nil
+	}
+}
+
+func noCacheCommit() error {
+	return nil
+}
+
+// SetCache enables conditional requests for GetJSONCached.
+func (c *ESIClient) SetCache(cache ESICache) {
+	c.cache = cache
+}
+
+func parseExpires(header http.Header) time.Time {
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return time.Time{}
+	}
+	return expires
+}
+
+func IsESITimeout(err error) bool {
+	if errors.Is(err, ErrESITimeout) {
+		return true
+	}
+	var esiErr *ESIError
+	return errors.As(err, &esiErr) && esiErr.StatusCode == http.StatusGatewayTimeout
+}
+
+func IsESIErrorLimit(err error) bool {
+	return errors.Is(err, ErrESIErrorLimited)
+}
+
+// IsESINotFound reports whether ESI answered with 404.
+func IsESINotFound(err error) bool {
+	var esiErr *ESIError
+	return errors.As(err, &esiErr) && esiErr.StatusCode == http.StatusNotFound
+}
@@ -1,10 +1,18 @@
 package services
 
 import (
+	"context"
+	"net/http"
+	"strconv"
 	"sync"
 	"time"
 )
 
+// minErrorBudget is the number of remaining ESI errors at which all callers
+// pause until the error window resets. ESI bans the application once the
+// budget reaches zero, so we stop well before that.
+const minErrorBudget = 10
+
 type ESIErrorManager struct {
 	mu             sync.Mutex
 	errorRemaining int
@@ -18,14 +26,29 @@ func (em *ESIErrorManager) UpdateLimits(remaining int, resetSeconds int) {
 	em.resetTime = time.Now().Add(time.Duration(resetSeconds) * time.Second)
 }
 
+// UpdateFromHeaders reads the X-ESI-Error-Limit-Remain and
+// X-ESI-Error-Limit-Reset headers of an ESI response and reports whether
+// they were present.
+func (em *ESIErrorManager) UpdateFromHeaders(header http.Header) bool {
+	remaining, err := strconv.Atoi(header.Get("X-ESI-Error-Limit-Remain"))
+	if err != nil {
+		return false
+	}
+	reset, err := strconv.Atoi(header.Get("X-ESI-Error-Limit-Reset"))
+	if err != nil {
+		return false
+	}
+	em.UpdateLimits(remaining, reset)
+	return true
+}
+
 func (em *ESIErrorManager) CanMakeRequest() bool {
 	em.mu.Lock()
 	defer em.mu.Unlock()
 	if time.Now().After(em.resetTime) {
-
 		return true
 	}
-	return em.errorRemaining > 0
+	return em.errorRemaining > minErrorBudget
 }
 
 func (em *ESIErrorManager) DecrementErrorCount() {
@@ -46,3 +69,21 @@ func (em *ESIErrorManager) WaitForReset() {
 		time.Sleep(sleepDuration)
 	}
 }
+
+// Wait blocks until the error budget allows another request or ctx is done.
+func (em *ESIErrorManager) Wait(ctx context.Context) error {
+	for !em.CanMakeRequest() {
+		em.mu.Lock()
+		sleepDuration := time.Until(em.resetTime)
+		em.mu.Unlock()
+
+		timer := time.NewTimer(sleepDuration)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/utils"
+)
+
+const (
+	// namesBatchSize is the maximum number of IDs ESI accepts per
+	// POST /universe/names/ request.
+	namesBatchSize = 1000
+	// nameMaxAge is how long a stored name is trusted before it is resolved
+	// again. Characters, corporations and alliances can be renamed.
+	nameMaxAge = 7 * 24 * time.Hour
+)
+
+// ResolveNames returns the names of ids keyed by ID. Names are served from
+// the names table; unknown or stale IDs are resolved through ESI in batches
+// and stored. IDs that ESI does not know are left out of the result and
+// stored as unresolved, so they are not looked up again until nameMaxAge
+// passes. If ESI fails, the names known so far are returned together with
+// the error.
+func ResolveNames(ctx context.Context, ids []int64) (map[int64]models.Name, error) {
+	unique := make(map[int64]bool, len(ids))
+	var lookup []int64
+	for _, id := range ids {
+		if id > 0 && !unique[id] {
+			unique[id] = true
+			lookup = append(lookup, id)
+		}
+	}
+
+	stored, err := repos.Entities.GetNamesByIDs(lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]models.Name, len(lookup))
+	fresh := make(map[int64]bool, len(stored))
+	for _, name := range stored {
+		fresh[name.ID] = time.Since(name.RefreshedAt) <= nameMaxAge
+		if name.Category != models.NameUnresolved {
+			names[name.ID] = name
+		}
+	}
+
+	var unresolved []int64
+	for _, id := range lookup {
+		if !fresh[id] {
+			unresolved = append(unresolved, id)
+		}
+	}
+
+	for start := 0; start < len(unresolved); start += namesBatchSize {
+		end := min(start+namesBatchSize, len(unresolved))
+		resolved, err := fetchNames(ctx, unresolved[start:end])
+		if err != nil {
+			return names, err
+		}
+		if err := repos.Entities.UpsertNames(resolved); err != nil {
+			return names, err
+		}
+		for _, name := range resolved {
+			if name.Category == models.NameUnresolved {
+				delete(names, name.ID)
+			} else {
+				names[name.ID] = name
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// fetchNames resolves ids through ESI. ESI rejects the whole batch with 404
+// when a single ID is invalid, so such batches are split until the invalid
+// IDs are isolated and returned as unresolved.
+func fetchNames(ctx context.Context, ids []int64) ([]models.Name, error) {
+	var esiNames []struct {
+		ID       int64  `json:"id"`
+		Name     string `json:"name"`
+		Category string `json:"category"`
+	}
+
+	err := ESI.PostJSON(ctx, "/universe/names/?datasource=tranquility", ids, &esiNames)
+	if IsESINotFound(err) {
+		if len(ids) == 1 {
+			utils.LogToFile(fmt.Sprintf("ESI could not resolve a name for ID %d", ids[0]))
+			return []models.Name{{ID: ids[0], Category: models.NameUnresolved, RefreshedAt: time.Now()}}, nil
+		}
+		half := len(ids) / 2
+		left, err := fetchNames(ctx, ids[:half])
+		if err != nil {
+			return nil, err
+		}
+		right, err := fetchNames(ctx, ids[half:])
+		if err != nil {
+			return nil, err
+		}
+		return append(left, right...), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	names := make([]models.Name, 0, len(esiNames))
+	for _, esiName := range esiNames {
+		names = append(names, models.Name{
+			ID:          esiName.ID,
+			Name:        esiName.Name,
+			Category:    esiName.Category,
+			RefreshedAt: now,
+		})
+	}
+	return names, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// blueprintCopy is the singleton value ESI uses for blueprint copies, which
+// have no market value.
+const blueprintCopy = 2
+
+// FetchMarketPrices returns the average and adjusted price of every type and
+// whether they changed since the previous fetch. The returned commit writes
+// the ESI cache entry once the prices are stored.
+func FetchMarketPrices(ctx context.Context) ([]models.MarketPrice, bool, CacheCommit, error) {
+	var esiPrices []struct {
+		TypeID        int     `json:"type_id"`
+		AveragePrice  float64 `json:"average_price"`
+		AdjustedPrice float64 `json:"adjusted_price"`
+	}
+	changed, commit, err := ESI.FetchJSONCached(ctx, "/markets/prices/?datasource=tranquility", &esiPrices)
+	if err != nil || !changed {
+		return nil, changed, commit, err
+	}
+
+	now := time.Now()
+	prices := make([]models.MarketPrice, len(esiPrices))
+	for i, price := range esiPrices {
+		prices[i] = models.MarketPrice{
+			TypeID:        price.TypeID,
+			AveragePrice:  price.AveragePrice,
+			AdjustedPrice: price.AdjustedPrice,
+			UpdatedAt:     now,
+		}
+	}
+	return prices, true, commit, nil
+}
+
+// ValueKill values a victim's ship and items with price, which returns the
+// unit price of a type. Types without a price count as worthless.
+func ValueKill(victim models.Victim, price func(typeID int) float64) models.KillValue {
+	value := models.KillValue{Hull: price(victim.ShipTypeID)}
+	value.Destroyed = value.Hull
+
+	victim.Items.Walk(func(_, _ int, item models.ZKillboardItem) {
+		if item.Singleton == blueprintCopy {
+			return
+		}
+		unitPrice := price(item.ItemTypeID)
+		value.Destroyed += unitPrice * float64(item.Destroyed())
+		value.Dropped += unitPrice * float64(item.Dropped())
+	})
+
+	value.Total = value.Destroyed + value.Dropped
+	now := time.Now()
+	value.ComputedAt = &now
+	return value
+}
+
+// FetchPriceHistory returns the daily market history of a type in a region.
+func FetchPriceHistory(ctx context.Context, regionID, typeID int) ([]models.PriceHistory, error) {
+	var esiHistory []struct {
+		Date       string  `json:"date"`
+		Average    float64 `json:"average"`
+		Highest    float64 `json:"highest"`
+		Lowest     float64 `json:"lowest"`
+		Volume     int64   `json:"volume"`
+		OrderCount int64   `json:"order_count"`
+	}
+	path := fmt.Sprintf("/markets/%d/history/?datasource=tranquility&type_id=%d", regionID, typeID)
+	if err := ESI.GetJSON(ctx, path, &esiHistory); err != nil {
+		return nil, err
+	}
+
+	history := make([]models.PriceHistory, 0, len(esiHistory))
+	for _, day := range esiHistory {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid history date %q for type %d: %w", day.Date, typeID, err)
+		}
+		history = append(history, models.PriceHistory{
+			RegionID:   regionID,
+			TypeID:     typeID,
+			Date:       date,
+			Average:    day.Average,
+			Highest:    day.Highest,
+			Lowest:     day.Lowest,
+			Volume:     day.Volume,
+			OrderCount: day.OrderCount,
+		})
+	}
+	return history, nil
+}
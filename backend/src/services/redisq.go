@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RedisQPackage is a killmail pushed by zKillboard's RedisQ.
+type RedisQPackage struct {
+	KillID   int64           `json:"killID"`
+	Killmail json.RawMessage `json:"killmail"`
+	ZKB      ZKB             `json:"zkb"`
+}
+
+// RedisQClient long-polls a RedisQ listen.php endpoint.
+type RedisQClient struct {
+	httpClient *http.Client
+	url        string
+	queueID    string
+	ttw        time.Duration
+}
+
+// NewRedisQClient returns a client for the queue queueID at listenURL. ttw
+// is how long RedisQ holds a request open when no package is waiting.
+func NewRedisQClient(listenURL, queueID string, ttw time.Duration) *RedisQClient {
+	return &RedisQClient{
+		httpClient: &http.Client{Timeout: ttw + 30*time.Second},
+		url:        listenURL,
+		queueID:    queueID,
+		ttw:        ttw,
+	}
+}
+
+// Next waits for the next package. It returns nil without an error when the
+// wait timed out with an empty queue.
+func (c *RedisQClient) Next(ctx context.Context) (*RedisQPackage, error) {
+	query := url.Values{}
+	query.Set("queueID", c.queueID)
+	query.Set("ttw", strconv.Itoa(int(c.ttw.Seconds())))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", esiUserAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("RedisQ returned %d: %s", resp.StatusCode, body)
+	}
+
+	var envelope struct {
+		Package *RedisQPackage `json:"package"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("error decoding RedisQ response: %w", err)
+	}
+	return envelope.Package, nil
+}
@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tadeasf/eve-ran/src/fakeupstream"
+	"github.com/tadeasf/eve-ran/src/services"
+)
+
+// newDrainedRedisQ starts a fake upstream whose RedisQ has the bundled
+// packages already consumed.
+func newDrainedRedisQ(t *testing.T) *fakeupstream.Server {
+	t.Helper()
+	server := fakeupstream.New()
+	t.Cleanup(server.Close)
+
+	client := services.NewRedisQClient(server.Upstreams().RedisQURL, "drain", time.Second)
+	for {
+		pkg, err := client.Next(context.Background())
+		if err != nil {
+			t.Fatalf("draining RedisQ: %v", err)
+		}
+		if pkg == nil {
+			return server
+		}
+	}
+}
+
+func TestRedisQClientNextTimesOutOnEmptyQueue(t *testing.T) {
+	server := newDrainedRedisQ(t)
+	client := services.NewRedisQClient(server.Upstreams().RedisQURL, "test", time.Second)
+
+	start := time.Now()
+	pkg, err := client.Next(context.Background())
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if pkg != nil {
+		t.Errorf("Next returned kill %d from an empty queue", pkg.KillID)
+	}
+	if waited := time.Since(start); waited < time.Second {
+		t.Errorf("Next returned after %s, before ttw passed", waited)
+	}
+}
+
+func TestRedisQClientNextWakesUpOnPush(t *testing.T) {
+	server := newDrainedRedisQ(t)
+	client := services.NewRedisQClient(server.Upstreams().RedisQURL, "test", 10*time.Second)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		server.Queue().Push([]byte(`{"killID":42,"killmail":null,"zkb":{"hash":"abc","totalValue":1000}}`))
+	}()
+
+	start := time.Now()
+	pkg, err := client.Next(context.Background())
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if pkg == nil || pkg.KillID != 42 || pkg.ZKB.Hash != "abc" {
+		t.Fatalf("Next returned %+v, want the pushed kill 42", pkg)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("Next returned after %s, not when the package was pushed", waited)
+	}
+}
+
+func TestRedisQClientNextReportsServerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := services.NewRedisQClient(server.URL, "test", time.Second)
+	pkg, err := client.Next(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("Next returned %+v, %v, want a 503 error", pkg, err)
+	}
+}
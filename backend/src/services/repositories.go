@@ -0,0 +1,11 @@
+package services
+
+import "github.com/tadeasf/eve-ran/src/db/repository"
+
+var repos *repository.Repositories
+
+// ConfigureRepositories sets the repositories the services read through. It
+// must be called before the services are used.
+func ConfigureRepositories(r *repository.Repositories) {
+	repos = r
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+// FetchSystemControl returns the current sovereignty and faction warfare
+// holders of every system, keyed by system ID.
+func FetchSystemControl(ctx context.Context) (map[int]models.SystemControl, error) {
+	var sovereignty []struct {
+		SystemID      int   `json:"system_id"`
+		AllianceID    int64 `json:"alliance_id"`
+		CorporationID int64 `json:"corporation_id"`
+		FactionID     int64 `json:"faction_id"`
+	}
+	if _, err := ESI.GetJSONCached(ctx, "/sovereignty/map/?datasource=tranquility", &sovereignty); err != nil {
+		return nil, err
+	}
+
+	var warfare []struct {
+		SolarSystemID     int    `json:"solar_system_id"`
+		OwnerFactionID    int64  `json:"owner_faction_id"`
+		OccupierFactionID int64  `json:"occupier_faction_id"`
+		Contested         string `json:"contested"`
+	}
+	if _, err := ESI.GetJSONCached(ctx, "/fw/systems/?datasource=tranquility", &warfare); err != nil {
+		return nil, err
+	}
+
+	control := make(map[int]models.SystemControl, len(sovereignty))
+	for _, system := range sovereignty {
+		control[system.SystemID] = models.SystemControl{
+			SystemID:         system.SystemID,
+			SovAllianceID:    system.AllianceID,
+			SovCorporationID: system.CorporationID,
+			SovFactionID:     system.FactionID,
+		}
+	}
+	for _, system := range warfare {
+		entry := control[system.SolarSystemID]
+		entry.SystemID = system.SolarSystemID
+		entry.FWOwnerFactionID = system.OwnerFactionID
+		entry.FWOccupierFactionID = system.OccupierFactionID
+		entry.FWContested = system.Contested
+		control[system.SolarSystemID] = entry
+	}
+	return control, nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+	"github.com/tadeasf/eve-ran/src/graph"
+)
+
+func FetchStargateInfo(ctx context.Context, stargateID int) (*models.Stargate, bool, CacheCommit, error) {
+	var esiStargate struct {
+		StargateID  int    `json:"stargate_id"`
+		Name        string `json:"name"`
+		SystemID    int    `json:"system_id"`
+		Destination struct {
+			StargateID int `json:"stargate_id"`
+			SystemID   int `json:"system_id"`
+		} `json:"destination"`
+	}
+	path := fmt.Sprintf("/universe/stargates/%d/?datasource=tranquility", stargateID)
+	changed, commit, err := ESI.FetchJSONCached(ctx, path, &esiStargate)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	return &models.Stargate{
+		StargateID:            esiStargate.StargateID,
+		Name:                  esiStargate.Name,
+		SystemID:              esiStargate.SystemID,
+		DestinationStargateID: esiStargate.Destination.StargateID,
+		DestinationSystemID:   esiStargate.Destination.SystemID,
+	}, changed, commit, nil
+}
+
+// FetchAllStargates returns the stargates listed on stored systems that
+// changed since the previous sync.
+func FetchAllStargates(ctx context.Context, opts BulkOptions) ([]Fetched[*models.Stargate], SyncStats, error) {
+	stargateIDs, err := repos.Universe.GetSystemStargateIDs()
+	if err != nil {
+		return nil, SyncStats{}, err
+	}
+	return fetchChanged(ctx, stargateIDs, opts, FetchStargateInfo)
+}
+
+var universeGraph struct {
+	sync.Mutex
+	graph *graph.Graph
+}
+
+// UniverseGraph returns the stargate graph, building it from the database
+// on first use.
+func UniverseGraph() (*graph.Graph, error) {
+	universeGraph.Lock()
+	defer universeGraph.Unlock()
+
+	if universeGraph.graph != nil {
+		return universeGraph.graph, nil
+	}
+
+	systems, err := repos.Universe.GetSystemsForGraph()
+	if err != nil {
+		return nil, err
+	}
+	stargates, err := repos.Universe.GetAllStargates()
+	if err != nil {
+		return nil, err
+	}
+
+	universeGraph.graph = graph.New(systems, stargates)
+	return universeGraph.graph, nil
+}
+
+// ResetUniverseGraph drops the cached stargate graph so the next call to
+// UniverseGraph rebuilds it. Call it after systems or stargates changed.
+func ResetUniverseGraph() {
+	universeGraph.Lock()
+	universeGraph.graph = nil
+	universeGraph.Unlock()
+}
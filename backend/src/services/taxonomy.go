@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tadeasf/eve-ran/src/db/models"
+)
+
+func FetchCategoryIDs(ctx context.Context) ([]int, error) {
+	var categoryIDs []int
+	_, err := ESI.GetJSONCached(ctx, "/universe/categories/?datasource=tranquility", &categoryIDs)
+	return categoryIDs, err
+}
+
+func FetchCategoryInfo(ctx context.Context, categoryID int) (*models.ItemCategory, bool, CacheCommit, error) {
+	var category models.ItemCategory
+	path := fmt.Sprintf("/universe/categories/%d/?datasource=tranquility&language=en", categoryID)
+	changed, commit, err := ESI.FetchJSONCached(ctx, path, &category)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	return &category, changed, commit, nil
+}
+
+func FetchGroupIDs(ctx context.Context) ([]int, error) {
+	return fetchPagedIDs(ctx, "/universe/groups/?datasource=tranquility")
+}
+
+func FetchGroupInfo(ctx context.Context, groupID int) (*models.ItemGroup, bool, CacheCommit, error) {
+	var group models.ItemGroup
+	path := fmt.Sprintf("/universe/groups/%d/?datasource=tranquility&language=en", groupID)
+	changed, commit, err := ESI.FetchJSONCached(ctx, path, &group)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	return &group, changed, commit, nil
+}
+
+func FetchMarketGroupIDs(ctx context.Context) ([]int, error) {
+	var marketGroupIDs []int
+	_, err := ESI.GetJSONCached(ctx, "/markets/groups/?datasource=tranquility", &marketGroupIDs)
+	return marketGroupIDs, err
+}
+
+func FetchMarketGroupInfo(ctx context.Context, marketGroupID int) (*models.MarketGroup, bool, CacheCommit, error) {
+	var marketGroup models.MarketGroup
+	path := fmt.Sprintf("/markets/groups/%d/?datasource=tranquility&language=en", marketGroupID)
+	changed, commit, err := ESI.FetchJSONCached(ctx, path, &marketGroup)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	return &marketGroup, changed, commit, nil
+}
+
+// FetchAllCategories returns the categories that changed since the previous
+// sync.
+func FetchAllCategories(ctx context.Context, opts BulkOptions) ([]Fetched[*models.ItemCategory], SyncStats, error) {
+	categoryIDs, err := FetchCategoryIDs(ctx)
+	if err != nil {
+		return nil, SyncStats{}, err
+	}
+	return fetchChanged(ctx, categoryIDs, opts, FetchCategoryInfo)
+}
+
+// FetchAllGroups returns the groups that changed since the previous sync.
+func FetchAllGroups(ctx context.Context, opts BulkOptions) ([]Fetched[*models.ItemGroup], SyncStats, error) {
+	groupIDs, err := FetchGroupIDs(ctx)
+	if err != nil {
+		return nil, SyncStats{}, err
+	}
+	return fetchChanged(ctx, groupIDs, opts, FetchGroupInfo)
+}
+
+// FetchAllMarketGroups returns the market groups that changed since the
+// previous sync.
+func FetchAllMarketGroups(ctx context.Context, opts BulkOptions) ([]Fetched[*models.MarketGroup], SyncStats, error) {
+	marketGroupIDs, err := FetchMarketGroupIDs(ctx)
+	if err != nil {
+		return nil, SyncStats{}, err
+	}
+	return fetchChanged(ctx, marketGroupIDs, opts, FetchMarketGroupInfo)
+}
@@ -0,0 +1,57 @@
+package services
+
+import "os"
+
+const (
+	defaultESIBaseURL   = "https://esi.evetech.net/latest"
+	defaultZKillBaseURL = "https://zkillboard.com"
+	defaultRedisQURL    = "https://zkillredisq.stream/listen.php"
+)
+
+// Upstreams holds the base URLs of every external API the backend talks to.
+type Upstreams struct {
+	ESIBaseURL   string
+	ZKillBaseURL string
+	// RedisQURL is the zKillboard RedisQ listen.php endpoint.
+	RedisQURL string
+}
+
+var upstreams = Upstreams{
+	ESIBaseURL:   defaultESIBaseURL,
+	ZKillBaseURL: defaultZKillBaseURL,
+	RedisQURL:    defaultRedisQURL,
+}
+
+// UpstreamsFromEnv reads ESI_BASE_URL, ZKILL_BASE_URL and REDISQ_URL, falling
+// back to the public endpoints.
+func UpstreamsFromEnv() Upstreams {
+	u := Upstreams{
+		ESIBaseURL:   os.Getenv("ESI_BASE_URL"),
+		ZKillBaseURL: os.Getenv("ZKILL_BASE_URL"),
+		RedisQURL:    os.Getenv("REDISQ_URL"),
+	}
+	if u.ESIBaseURL == "" {
+		u.ESIBaseURL = defaultESIBaseURL
+	}
+	if u.ZKillBaseURL == "" {
+		u.ZKillBaseURL = defaultZKillBaseURL
+	}
+	if u.RedisQURL == "" {
+		u.RedisQURL = defaultRedisQURL
+	}
+	return u
+}
+
+// ConfigureUpstreams points the shared clients at the given base URLs. It
+// replaces the shared ESI and zKillboard clients, so it must be called
+// before SetCache and before any request is made.
+func ConfigureUpstreams(u Upstreams) {
+	upstreams = u
+	ESI = NewESIClient(u.ESIBaseURL)
+	ZKill = NewZKillClient(u.ZKillBaseURL, zkillMinInterval)
+}
+
+// RedisQURL returns the configured RedisQ listen.php endpoint.
+func RedisQURL() string {
+	return upstreams.RedisQURL
+}
@@ -0,0 +1,45 @@
+package services
+
+import "github.com/tadeasf/eve-ran/src/db/models"
+
+// ZKillEntry is a killmail in a zKillboard API list.
+type ZKillEntry struct {
+	KillmailID int64 `json:"killmail_id"`
+	ZKB        ZKB   `json:"zkb"`
+}
+
+// ZKB is the zKillboard metadata attached to a killmail.
+type ZKB struct {
+	LocationID     int64    `json:"locationID"`
+	Hash           string   `json:"hash"`
+	FittedValue    float64  `json:"fittedValue"`
+	DroppedValue   float64  `json:"droppedValue"`
+	DestroyedValue float64  `json:"destroyedValue"`
+	TotalValue     float64  `json:"totalValue"`
+	Points         int      `json:"points"`
+	NPC            bool     `json:"npc"`
+	Solo           bool     `json:"solo"`
+	Awox           bool     `json:"awox"`
+	Labels         []string `json:"labels"`
+}
+
+// Zkill converts the metadata of killmailID into a Zkill attributed to
+// characterID in role.
+func (z ZKB) Zkill(killmailID, characterID int64, role string) models.Zkill {
+	return models.Zkill{
+		KillmailID:     killmailID,
+		CharacterID:    characterID,
+		Role:           role,
+		LocationID:     z.LocationID,
+		Hash:           z.Hash,
+		FittedValue:    z.FittedValue,
+		DroppedValue:   z.DroppedValue,
+		DestroyedValue: z.DestroyedValue,
+		TotalValue:     z.TotalValue,
+		Points:         z.Points,
+		NPC:            z.NPC,
+		Solo:           z.Solo,
+		Awox:           z.Awox,
+		Labels:         models.StringArray(z.Labels),
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// zkillMinInterval spaces requests to zKillboard, which asks API users
+	// to stay around one request per second.
+	zkillMinInterval = time.Second
+	// zkillMaxAttempts is how often a request is tried before giving up on
+	// rate limiting and server errors.
+	zkillMaxAttempts = 5
+	zkillMinBackoff  = 5 * time.Second
+	zkillMaxBackoff  = 2 * time.Minute
+)
+
+// ZKillError is returned for non-2xx zKillboard responses that are not
+// retried or kept failing.
+type ZKillError struct {
+	StatusCode int
+	URL        string
+	Body       string
+}
+
+func (e *ZKillError) Error() string {
+	return fmt.Sprintf("zKillboard returned status %d for %s: %s", e.StatusCode, e.URL, e.Body)
+}
+
+// ZKillClient is the single HTTP client used for zKillboard API requests.
+// Requests from all callers are spaced by a minimum interval, and rate
+// limited (429) or failed (5xx, including Cloudflare's 52x) requests are
+// retried with exponential backoff, which also pauses every other caller.
+type ZKillClient struct {
+	httpClient  *http.Client
+	baseURL     string
+	minInterval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// ZKill is the shared zKillboard client.
+var ZKill = NewZKillClient(defaultZKillBaseURL, zkillMinInterval)
+
+func NewZKillClient(baseURL string, minInterval time.Duration) *ZKillClient {
+	return &ZKillClient{
+		httpClient:  &http.Client{Timeout: 60 * time.Second},
+		baseURL:     baseURL,
+		minInterval: minInterval,
+	}
+}
+
+// wait blocks until the caller may send the next request.
+func (c *ZKillClient) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	at := c.next
+	if at.Before(now) {
+		at = now
+	}
+	c.next = at.Add(c.minInterval)
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(at)):
+		return nil
+	}
+}
+
+// pause holds back every caller for d.
+func (c *ZKillClient) pause(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if until := time.Now().Add(d); until.After(c.next) {
+		c.next = until
+	}
+}
+
+// Get fetches path (relative to the zKillboard base URL) and returns the
+// response body.
+func (c *ZKillClient) Get(ctx context.Context, path string) ([]byte, error) {
+	backoff := zkillMinBackoff
+	for attempt := 1; ; attempt++ {
+		body, retryAfter, err := c.send(ctx, path)
+		if err == nil || retryAfter == 0 || attempt == zkillMaxAttempts {
+			return body, err
+		}
+
+		if retryAfter < 0 {
+			retryAfter = backoff
+			backoff = min(backoff*2, zkillMaxBackoff)
+		}
+		c.pause(retryAfter)
+	}
+}
+
+// send performs a single request. A non-zero duration marks the error as
+// retryable, with a negative one asking for the default backoff.
+func (c *ZKillClient) send(ctx context.Context, path string) ([]byte, time.Duration, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	url := c.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("User-Agent", esiUserAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, 0, ctx.Err()
+		}
+		return nil, -1, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var reader io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, -1, fmt.Errorf("error decompressing response: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, -1, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		retryAfter := time.Duration(-1)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, retryAfter, &ZKillError{StatusCode: resp.StatusCode, URL: url, Body: truncate(string(body), 512)}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, 0, &ZKillError{StatusCode: resp.StatusCode, URL: url, Body: truncate(string(body), 512)}
+	}
+	return body, 0, nil
+}
+
+// Entries fetches a page of the API list at path, e.g.
+// kills/characterID/123, newest killmail first.
+func (c *ZKillClient) Entries(ctx context.Context, path string, page int) ([]ZKillEntry, error) {
+	body, err := c.Get(ctx, fmt.Sprintf("/api/%s/page/%d/", path, page))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ZKillEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("error decoding zKillboard list %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Killmail fetches the zKillboard metadata of a single killmail. It returns
+// nil if zKillboard does not know the killmail.
+func (c *ZKillClient) Killmail(ctx context.Context, killmailID int64) (*ZKillEntry, error) {
+	body, err := c.Get(ctx, fmt.Sprintf("/api/killID/%d/", killmailID))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ZKillEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("error decoding zKillboard killmail %d: %w", killmailID, err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}